@@ -0,0 +1,333 @@
+package formats
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/onedusk/sb/internal/config"
+	"github.com/onedusk/sb/internal/fsutil"
+	"github.com/onedusk/sb/internal/interrupt"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/ui"
+	"github.com/onedusk/sb/pkg/sb"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Register adds "sb convert" and a subcommand for every converter in reg to
+// root, skipping converter names already taken by built-in commands
+func Register(root *cobra.Command, reg *sb.Registry) {
+	convert := NewConvertCommand(reg)
+	root.AddCommand(convert)
+
+	taken := make(map[string]bool)
+	for _, c := range root.Commands() {
+		taken[c.Name()] = true
+	}
+
+	for _, conv := range reg.ListConverters() {
+		if taken[conv.Name()] {
+			ui.PrintWarning("converter %q conflicts with a built-in command and has no subcommand", conv.Name())
+			continue
+		}
+		root.AddCommand(NewCommand(conv, root.PersistentFlags()))
+	}
+}
+
+// NewCommand builds the subcommand for conv: a flag for every option in its
+// schema, bound to the "<name>.<key>" config key, the SB_<NAME>_<KEY>
+// environment variable and the schema default
+func NewCommand(conv sb.Converter, global *pflag.FlagSet) *cobra.Command {
+	var (
+		dir       string
+		recursive bool
+	)
+
+	name := conv.Name()
+	cmd := &cobra.Command{
+		Use:   name + " [files...]",
+		Short: conv.Description(),
+		Long:  longHelp(conv),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConvert(cmd, conv, args, dir, recursive)
+		},
+	}
+
+	cmd.Flags().StringVarP(&dir, "dir", "d", "", "input directory")
+	cmd.Flags().BoolVar(&recursive, "recursive", false, "process directory recursively")
+
+	for _, spec := range schemaOf(conv) {
+		addOptionFlag(cmd.Flags(), global, spec)
+
+		key := configKey(name, spec.Key())
+		viper.SetDefault(key, spec.DefaultValue())
+		viper.BindEnv(key, envName(name, spec.Key()))
+		viper.BindPFlag(key, cmd.Flags().Lookup(spec.Name))
+
+		if spec.EnabledBy != "" {
+			gate := configKey(name, spec.EnabledBy)
+			viper.SetDefault(gate, false)
+			viper.BindEnv(gate, envName(name, spec.EnabledBy))
+		}
+	}
+
+	return cmd
+}
+
+// runConvert converts the inputs named by args (or found in dir) with conv
+func runConvert(cmd *cobra.Command, conv sb.Converter, args []string, dir string, recursive bool) error {
+	// Gather input files
+	accept := func(path string) bool { return media.Accepts(path, conv.SupportedInputs()) }
+	inputs, err := fsutil.GatherInputs(args, dir, recursive, accept)
+	if err != nil {
+		return err
+	}
+
+	if len(inputs) == 0 {
+		return fmt.Errorf("no input files found")
+	}
+
+	// Apply converter options from flags, environment and config
+	values, err := configure(conv, cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	return convertFiles(cmd, conv, inputs, values)
+}
+
+// configure resolves conv's options from flags (nil = config and
+// environment only) and applies them
+func configure(conv sb.Converter, flags *pflag.FlagSet) (sb.OptionValues, error) {
+	values, err := resolveOptions(conv, flags)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := conv.(sb.Configurable); ok {
+		if err := c.Configure(values); err != nil {
+			return nil, fmt.Errorf("invalid %s options: %w", conv.Name(), err)
+		}
+	}
+	return values, nil
+}
+
+// convertFiles converts inputs with an already configured converter
+func convertFiles(cmd *cobra.Command, conv sb.Converter, inputs []string, values sb.OptionValues) error {
+	cfg := config.Get()
+
+	// Build converter options
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	convOpts := sb.Options{
+		OutputDir:     viper.GetString("output_dir"),
+		Workers:       viper.GetInt("workers"),
+		SkipExisting:  viper.GetBool("skip_existing"),
+		DryRun:        dryRun,
+		Verbose:       viper.GetBool("verbose"),
+		FlatStructure: viper.GetBool("flat_structure"),
+		ShowProgress:  true,
+		ShowSummary:   true,
+		Abort:         interrupt.Abort(cmd.Context()),
+	}
+
+	// Report per-file outcomes from the batch event stream
+	if !convOpts.Verbose {
+		convOpts.OnEvent = printEvent
+	}
+
+	// Validate workers
+	if convOpts.Workers <= 0 {
+		convOpts.Workers = cfg.Workers
+	}
+
+	// Remove partial outputs left behind by previously crashed or killed runs
+	if !convOpts.DryRun {
+		sweepPartials(conv, inputs, convOpts)
+	}
+
+	// Refuse up front if an external dependency lacks a required component;
+	// batches run the preflight themselves
+	if p, ok := conv.(sb.Preflighter); ok && len(inputs) == 1 {
+		if err := p.Preflight(); err != nil {
+			return err
+		}
+	}
+
+	// Print conversion info
+	if !convOpts.Verbose {
+		ui.PrintInfo("Converting %d file(s) to %s", len(inputs), strings.ToUpper(strings.TrimPrefix(conv.OutputExtension(), ".")))
+		ui.PrintInfo("Workers: %d", convOpts.Workers)
+		if summary := describeValues(schemaOf(conv), values); summary != "" {
+			ui.PrintInfo("Options: %s", summary)
+		}
+		fmt.Println()
+	}
+
+	// Convert files
+	if len(inputs) == 1 {
+		// Single file conversion with per-file encode progress
+		showProgress := convOpts.ShowProgress && !convOpts.DryRun && !convOpts.Verbose
+		progress := ui.NewPercentBar(filepath.Base(inputs[0]), showProgress)
+		if showProgress {
+			convOpts.OnProgress = func(p sb.Progress) {
+				progress.Describe(ui.FormatFileProgress(p.CurrentFile, p.Percentage, p.Speed, p.ETA))
+				progress.Set(int(p.Percentage))
+			}
+		}
+
+		result, err := sb.Convert(convOpts.Abort, conv, inputs[0], convOpts)
+		if result != nil && result.Success {
+			progress.Finish()
+		} else {
+			progress.Clear()
+		}
+		if err != nil {
+			return err
+		}
+		if !result.Skipped && !convOpts.DryRun {
+			fmt.Printf("✓ %s -> %s\n", result.Input, result.Output)
+			if l := result.Loudness; l != nil {
+				fmt.Printf("  Loudness: %s -> %s (%s)\n",
+					ui.FormatLoudness(l.Measured.I, l.Measured.TP, l.Measured.LRA),
+					ui.FormatLoudness(l.Achieved.I, l.Achieved.TP, l.Achieved.LRA),
+					l.NormalizationType)
+			}
+		}
+		return nil
+	}
+
+	// Batch conversion
+	_, _, err := sb.Batch(cmd.Context(), conv, inputs, convOpts)
+	return err
+}
+
+// resolveOptions reads every schema option from its flag, environment
+// variable or config key. A config value behind a disabled EnabledBy gate
+// falls back to the default; an explicit flag always applies.
+func resolveOptions(conv sb.Converter, flags *pflag.FlagSet) (sb.OptionValues, error) {
+	name := conv.Name()
+	return sb.ResolveOptions(schemaOf(conv), func(spec sb.OptionSpec) (any, bool) {
+		explicit := false
+		if flags != nil {
+			flag := flags.Lookup(spec.Name)
+			explicit = flag != nil && flag.Changed
+		}
+		if !explicit && spec.EnabledBy != "" && !viper.GetBool(configKey(name, spec.EnabledBy)) {
+			return nil, false
+		}
+
+		key := configKey(name, spec.Key())
+		switch spec.Type {
+		case sb.OptionInt:
+			return viper.GetInt(key), true
+		case sb.OptionBool:
+			return viper.GetBool(key), true
+		case sb.OptionFloat:
+			return viper.GetFloat64(key), true
+		case sb.OptionDuration:
+			return viper.GetDuration(key), true
+		default:
+			return viper.GetString(key), true
+		}
+	})
+}
+
+// addOptionFlag defines the flag for spec. A shorthand already used by a
+// global flag is dropped rather than shadowing it.
+func addOptionFlag(flags, global *pflag.FlagSet, spec sb.OptionSpec) {
+	short := spec.Short
+	if short != "" && (flags.ShorthandLookup(short) != nil || (global != nil && global.ShorthandLookup(short) != nil)) {
+		short = ""
+	}
+
+	usage := spec.Help
+	if len(spec.Allowed) > 0 {
+		usage += " (" + strings.Join(spec.Allowed, "|") + ")"
+	}
+
+	switch v := spec.DefaultValue(); spec.Type {
+	case sb.OptionInt:
+		flags.IntP(spec.Name, short, v.(int), usage)
+	case sb.OptionBool:
+		flags.BoolP(spec.Name, short, v.(bool), usage)
+	case sb.OptionFloat:
+		flags.Float64P(spec.Name, short, v.(float64), usage)
+	case sb.OptionDuration:
+		flags.DurationP(spec.Name, short, v.(time.Duration), usage)
+	default:
+		flags.StringP(spec.Name, short, v.(string), usage)
+	}
+}
+
+// longHelp builds the long description of a generated command
+func longHelp(conv sb.Converter) string {
+	name := conv.Name()
+	ext := ".ext"
+	if inputs := conv.SupportedInputs(); len(inputs) > 0 {
+		ext = inputs[0]
+	}
+	example := "input" + ext
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s.\n\n", conv.Description())
+	fmt.Fprintf(&b, "Supports input formats: %s\n\n", strings.Join(conv.SupportedInputs(), ", "))
+	fmt.Fprintf(&b, "Options can also be set in the %q section of the config file or\n", name)
+	fmt.Fprintf(&b, "through SB_%s_<OPTION> environment variables.\n\n", strings.ToUpper(name))
+	fmt.Fprintf(&b, "Examples:\n")
+	fmt.Fprintf(&b, "  sb %s %-28s # Convert single file\n", name, example)
+	fmt.Fprintf(&b, "  sb %s %-28s # Convert all matching files\n", name, "*"+ext)
+	fmt.Fprintf(&b, "  sb %s %-28s # Convert directory recursively\n", name, "-d ./media -r")
+	fmt.Fprintf(&b, "  sb %s %-28s # 8 workers, custom output dir", name, "-w 8 -o ./out -d ./media")
+	return b.String()
+}
+
+// describeValues formats the set option values for display
+func describeValues(schema []sb.OptionSpec, values sb.OptionValues) string {
+	parts := []string{}
+	for _, spec := range schema {
+		v := values[spec.Name]
+		if v == nil || v == "" || v == false {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%v", spec.Name, v))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// schemaOf returns the option schema of conv (nil if it declares none)
+func schemaOf(conv sb.Converter) []sb.OptionSpec {
+	if c, ok := conv.(sb.Configurable); ok {
+		return c.OptionSchema()
+	}
+	return nil
+}
+
+// configKey returns the full config key of an option of converter name
+func configKey(name, key string) string {
+	return name + "." + key
+}
+
+// envName returns the environment variable for an option of converter name,
+// e.g., SB_MP4_HARDWARE_TYPE
+func envName(name, key string) string {
+	r := strings.NewReplacer(".", "_", "-", "_")
+	return "SB_" + strings.ToUpper(r.Replace(name)) + "_" + strings.ToUpper(r.Replace(key))
+}
+
+// sweepPartials removes stale partial files from every output directory of this run
+func sweepPartials(conv sb.Converter, inputs []string, opts sb.Options) {
+	dirs := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		dirs = append(dirs, filepath.Dir(sb.OutputPath(conv, input, opts)))
+	}
+
+	removed, err := fsutil.SweepPartials(dirs, fsutil.StalePartialAge)
+	if err != nil {
+		ui.PrintWarning("failed to clean up partial files: %v", err)
+	}
+	for _, path := range removed {
+		ui.PrintVerbose(opts.Verbose, "Removed stale partial file %s", path)
+	}
+}
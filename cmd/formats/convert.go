@@ -0,0 +1,168 @@
+package formats
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/onedusk/sb/internal/fsutil"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/ui"
+	"github.com/onedusk/sb/pkg/sb"
+	"github.com/spf13/cobra"
+)
+
+// NewConvertCommand builds "sb convert", which routes every input to the
+// converter in reg for its extension and the requested output format
+func NewConvertCommand(reg *sb.Registry) *cobra.Command {
+	var (
+		to        string
+		dir       string
+		recursive bool
+		explain   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "convert --to <ext> [files...]",
+		Short: "Convert files to a format, picking the converter automatically",
+		Long: `Convert files to the format given by --to. Each input is routed to the
+converter registered for its extension and the target extension, so
+mixed-media directories can be processed in one invocation. When no single
+converter fits, sb chains several (e.g., .mkv -> .wav -> .flac), picking the
+cheapest chain and running intermediate steps in a temporary workspace.
+Files without a route are reported and left alone.
+
+Converter options are taken from the config file and SB_<CONVERTER>_<OPTION>
+environment variables.
+
+Examples:
+  sb convert --to mp4 clip.mov talk.mkv      # Route each file
+  sb convert --to mp4 -d ./media -r          # Whole directory tree
+  sb convert --to mp4 -n -d ./media          # Preview conversions
+  sb convert --to mp4 --explain -d ./media   # Show the chosen converter chains`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRoutedConvert(cmd, reg, args, to, dir, recursive, explain)
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "output format extension (e.g., mp4, webm, mp3, jpg)")
+	cmd.Flags().StringVarP(&dir, "dir", "d", "", "input directory")
+	cmd.Flags().BoolVar(&recursive, "recursive", false, "process directory recursively")
+	cmd.Flags().BoolVar(&explain, "explain", false, "print the converter chain chosen for each input format and exit")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+// routeGroup is the set of inputs routed through one plan
+type routeGroup struct {
+	plan   *sb.Plan
+	inputs []string
+}
+
+// runRoutedConvert converts every input to the target extension
+func runRoutedConvert(cmd *cobra.Command, reg *sb.Registry, args []string, to, dir string, recursive, explain bool) error {
+	target := sb.NormalizeExt(to)
+	if target == "" {
+		return fmt.Errorf("--to must name an output format")
+	}
+
+	// Accept any known media file so that unroutable ones can be reported
+	routable := make(map[string]bool)
+	accept := func(path string) bool {
+		if media.IsMedia(path) {
+			return true
+		}
+		ext := sb.NormalizeExt(filepath.Ext(path))
+		ok, seen := routable[ext]
+		if !seen {
+			_, err := reg.FindPlan(ext, target)
+			ok = err == nil
+			routable[ext] = ok
+		}
+		return ok
+	}
+	inputs, err := fsutil.GatherInputs(args, dir, recursive, accept)
+	if err != nil {
+		return err
+	}
+
+	if len(inputs) == 0 {
+		return fmt.Errorf("no input files found")
+	}
+
+	// Group inputs by the extension matching their content (and so by plan),
+	// keeping the order of first appearance
+	groups := []*routeGroup{}
+	byExt := make(map[string]*routeGroup)
+	unrouted := 0
+	for _, input := range inputs {
+		ext := media.DetectExt(input)
+		if g, ok := byExt[ext]; ok {
+			g.inputs = append(g.inputs, input)
+			continue
+		}
+
+		plan, err := reg.FindPlan(ext, target)
+		if err != nil {
+			ui.PrintWarning("%s: %v", input, err)
+			unrouted++
+			continue
+		}
+
+		g := &routeGroup{plan: plan, inputs: []string{input}}
+		byExt[ext] = g
+		groups = append(groups, g)
+	}
+
+	if explain {
+		for _, g := range groups {
+			fmt.Printf("%s  (cost %d, %d file(s))\n", g.plan, g.plan.Cost(), len(g.inputs))
+		}
+		return nil
+	}
+
+	if len(groups) == 0 {
+		return fmt.Errorf("none of the %d input file(s) can be converted to %s", len(inputs), target)
+	}
+	if unrouted > 0 {
+		ui.PrintWarning("%d file(s) have no route to %s and will be left alone", unrouted, target)
+		fmt.Println()
+	}
+
+	// Run each plan over its share of the inputs
+	var errs []error
+	for _, g := range groups {
+		if cmd.Context().Err() != nil {
+			break
+		}
+
+		conv := g.plan.Converter()
+		values, err := configurePlan(g.plan)
+		if err == nil {
+			err = convertFiles(cmd, conv, g.inputs, values)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", conv.Name(), err))
+		}
+		fmt.Println()
+	}
+
+	return errors.Join(errs...)
+}
+
+// configurePlan applies config and environment options to every converter
+// of plan, returning the values of a single-step plan for display
+func configurePlan(plan *sb.Plan) (sb.OptionValues, error) {
+	var values sb.OptionValues
+	for _, step := range plan.Steps {
+		v, err := configure(step.Converter, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(plan.Steps) == 1 {
+			values = v
+		}
+	}
+	return values, nil
+}
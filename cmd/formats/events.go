@@ -0,0 +1,17 @@
+package formats
+
+import (
+	"fmt"
+
+	"github.com/onedusk/sb/pkg/sb"
+)
+
+// printEvent prints a status line for every completed batch job
+func printEvent(ev sb.Event) {
+	switch ev.Type {
+	case sb.EventFinished:
+		fmt.Printf("✓ %s\n", ev.Input)
+	case sb.EventFailed:
+		fmt.Printf("✗ %s: %v\n", ev.Input, ev.Error)
+	}
+}
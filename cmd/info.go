@@ -2,40 +2,348 @@ package cmd
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
 
-	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/config"
+	"github.com/onedusk/sb/internal/fsutil"
+	"github.com/onedusk/sb/internal/interrupt"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/ui"
+	"github.com/onedusk/sb/pkg/sb"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	infoFormat    string
+	infoFields    string
+	infoDir       string
+	infoToolchain string
 )
 
 // infoCmd represents the info command
 var infoCmd = &cobra.Command{
-	Use:   "info [file]",
+	Use:   "info [files...]",
 	Short: "Display media file information",
-	Long:  `Display detailed information about a media file using ffprobe.`,
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		input := args[0]
+	Long: `Display information about media files using ffprobe.
 
-		ffmpeg, err := executor.NewFFmpeg()
-		if err != nil {
-			return fmt.Errorf("ffmpeg not available: %w", err)
+Accepts files, globs and directories. Output is a compact table by default;
+use --format for machine-readable output and --fields to select columns.
+
+Examples:
+  sb info video.mov                                  # Single file
+  sb info -d ./videos -r                             # Whole library
+  sb info --format csv *.mov > library.csv           # CSV export
+  sb info --format ndjson --fields duration,video.codec,width,height ./clips
+  sb info --format json video.mov                    # Full typed model`,
+	RunE: runInfo,
+}
+
+func init() {
+	infoCmd.Flags().StringVar(&infoFormat, "format", "table", "output format (table|json|ndjson|csv|yaml)")
+	infoCmd.Flags().StringVar(&infoFields, "fields", "", "comma-separated fields to show (e.g., duration,video.codec,width,height)")
+	infoCmd.Flags().StringVarP(&infoDir, "dir", "d", "", "input directory")
+	infoCmd.Flags().StringVar(&infoToolchain, "toolchain", "", "named ffmpeg toolchain from config")
+
+	rootCmd.AddCommand(infoCmd)
+}
+
+// probeResult pairs an input with its probe outcome
+type probeResult struct {
+	Input string
+	Info  *media.MediaInfo
+	Err   error
+}
+
+func runInfo(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(infoFormat)
+	switch format {
+	case "table", "json", "ndjson", "csv", "yaml":
+	default:
+		return fmt.Errorf("unsupported format %q (table|json|ndjson|csv|yaml)", infoFormat)
+	}
+
+	// Resolve field selection; full model output is used for json/yaml without --fields
+	fieldNames := media.DefaultFields
+	if infoFields != "" {
+		fieldNames = strings.Split(infoFields, ",")
+	}
+	fields, err := media.LookupFields(fieldNames)
+	if err != nil {
+		return err
+	}
+
+	recursive, _ := cmd.Flags().GetBool("recursive")
+	inputs, err := fsutil.GatherInputs(args, infoDir, recursive, media.IsMedia)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("no input files found")
+	}
+
+	ffmpeg, err := sb.ToolchainExecutor(infoToolchain)
+	if err != nil {
+		return fmt.Errorf("ffmpeg not available: %w", err)
+	}
+
+	workers := viper.GetInt("workers")
+	if workers <= 0 {
+		workers = config.Get().Workers
+	}
+
+	results := probeAll(cmd.Context(), ffmpeg, inputs, workers)
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			ui.PrintWarning("%s: %v", r.Input, r.Err)
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	full := infoFields == ""
+
+	switch format {
+	case "table":
+		err = writeInfoTable(out, results, fields)
+	case "csv":
+		err = writeInfoCSV(out, results, fields)
+	case "json":
+		err = writeInfoJSON(out, results, fields, full)
+	case "ndjson":
+		err = writeInfoNDJSON(out, results, fields, full)
+	case "yaml":
+		err = writeInfoYAML(out, results, fields, full)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d file(s) could not be probed", failed)
+	}
+	return nil
+}
+
+// probeAll probes inputs in parallel, returning results in input order
+func probeAll(ctx context.Context, ffmpeg sb.Executor, inputs []string, workers int) []probeResult {
+	results := make([]probeResult, len(inputs))
+	for i, input := range inputs {
+		results[i] = probeResult{Input: input, Err: sb.ErrNotStarted}
+	}
+
+	pool := sb.NewPool(ctx, interrupt.Abort(ctx), workers)
+	pool.Start()
+
+	go func() {
+		for i, input := range inputs {
+			pool.Submit(func(ctx context.Context) error {
+				info, err := ffmpeg.GetInfo(ctx, input)
+				if info != nil {
+					info.Path = input
+				}
+				results[i] = probeResult{Input: input, Info: info, Err: err}
+				return err
+			})
 		}
+		pool.Stop()
+	}()
+
+	for range pool.Results() {
+	}
+
+	return results
+}
+
+// record is an ordered set of field values for structured output
+type record []recordValue
+
+type recordValue struct {
+	Name  string
+	Value any
+}
 
-		fmt.Printf("Media Info: %s\n", input)
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+// newRecord extracts the selected fields from info
+func newRecord(info *media.MediaInfo, fields []media.Field) record {
+	rec := make(record, 0, len(fields))
+	for _, f := range fields {
+		rec = append(rec, recordValue{Name: f.Name, Value: f.Value(info)})
+	}
+	return rec
+}
 
-		info, err := ffmpeg.GetInfo(context.Background(), input)
+// MarshalJSON encodes the record as an object preserving field order
+func (r record) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, v := range r {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(v.Name)
+		val, err := json.Marshal(v.Value)
 		if err != nil {
-			return fmt.Errorf("failed to get info: %w", err)
+			return nil, err
 		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
 
-		fmt.Println(info)
+// MarshalYAML encodes the record as a mapping preserving field order
+func (r record) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, v := range r {
+		value := v.Value
+		if d, ok := value.(media.Duration); ok {
+			value = d.Seconds()
+		}
+		var valNode yaml.Node
+		if err := valNode.Encode(value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: v.Name}, &valNode)
+	}
+	return node, nil
+}
 
-		return nil
-	},
+// structured returns the value to serialize for one result
+func structured(r probeResult, fields []media.Field, full bool) any {
+	if r.Err != nil {
+		return map[string]string{"path": r.Input, "error": r.Err.Error()}
+	}
+	if full {
+		return r.Info
+	}
+	return newRecord(r.Info, fields)
 }
 
-func init() {
-	rootCmd.AddCommand(infoCmd)
+func writeInfoTable(w io.Writer, results []probeResult, fields []media.Field) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	headers := make([]string, len(fields))
+	for i, f := range fields {
+		headers[i] = f.Header
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		row := make([]string, len(fields))
+		for i, f := range fields {
+			v := f.Value(r.Info)
+			if f.Name == "size" {
+				if n, ok := v.(int64); ok {
+					v = ui.FormatBytes(n)
+				}
+			}
+			row[i] = media.FormatValue(v)
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func writeInfoCSV(w io.Writer, results []probeResult, fields []media.Field) error {
+	cw := csv.NewWriter(w)
+
+	headers := make([]string, len(fields))
+	for i, f := range fields {
+		headers[i] = f.Name
+	}
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		row := make([]string, len(fields))
+		for i, f := range fields {
+			row[i] = csvValue(f.Value(r.Info))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeInfoJSON(w io.Writer, results []probeResult, fields []media.Field, full bool) error {
+	values := make([]any, 0, len(results))
+	for _, r := range results {
+		values = append(values, structured(r, fields, full))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(values)
+}
+
+func writeInfoNDJSON(w io.Writer, results []probeResult, fields []media.Field, full bool) error {
+	enc := json.NewEncoder(w)
+	for _, r := range results {
+		if err := enc.Encode(structured(r, fields, full)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeInfoYAML(w io.Writer, results []probeResult, fields []media.Field, full bool) error {
+	values := make([]any, 0, len(results))
+	for _, r := range results {
+		v := structured(r, fields, full)
+		if full && r.Err == nil {
+			// Round-trip through JSON so YAML keys match the JSON field names
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			var generic any
+			if err := json.Unmarshal(data, &generic); err != nil {
+				return err
+			}
+			v = generic
+		}
+		values = append(values, v)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(values); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// csvValue renders a raw field value for CSV output
+func csvValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case media.Duration:
+		return fmt.Sprintf("%.3f", val.Seconds())
+	case float64:
+		return fmt.Sprintf("%.3f", val)
+	default:
+		return fmt.Sprint(val)
+	}
 }
@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"text/tabwriter"
 
-	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/pkg/sb"
 	"github.com/spf13/cobra"
 )
 
@@ -14,7 +16,7 @@ var lsCmd = &cobra.Command{
 	Short: "List available converters",
 	Long:  `List all available converters and their supported formats.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		converters := converter.ListConverters()
+		converters := sb.DefaultRegistry().ListConverters()
 
 		if len(converters) == 0 {
 			fmt.Println("No converters available")
@@ -30,6 +32,10 @@ var lsCmd = &cobra.Command{
 			fmt.Printf("    Description: %s\n", conv.Description())
 			fmt.Printf("    Input:       %s\n", strings.Join(conv.SupportedInputs(), ", "))
 			fmt.Printf("    Output:      %s\n", conv.OutputExtension())
+			if c, ok := conv.(sb.Configurable); ok && len(c.OptionSchema()) > 0 {
+				fmt.Println("    Options:")
+				printOptions(c.OptionSchema())
+			}
 			fmt.Println()
 		}
 
@@ -41,3 +47,26 @@ var lsCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(lsCmd)
 }
+
+// printOptions prints a converter's option schema as an aligned table
+func printOptions(schema []sb.OptionSpec) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, spec := range schema {
+		flag := "--" + spec.Name
+		if spec.Short != "" {
+			flag = "-" + spec.Short + ", " + flag
+		}
+
+		help := spec.Help
+		if len(spec.Allowed) > 0 {
+			help += " (" + strings.Join(spec.Allowed, "|") + ")"
+		}
+		// A declared numeric zero (e.g., a float of 0) is a default worth showing
+		if def := spec.Default; def != nil && def != "" && def != false {
+			help += fmt.Sprintf(" [default: %v]", def)
+		}
+
+		fmt.Fprintf(w, "      %s\t%s\t%s\n", flag, spec.Type, help)
+	}
+	w.Flush()
+}
@@ -1,19 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/onedusk/sb/internal/config"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/interrupt"
+	"github.com/onedusk/sb/internal/media"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	cfgFile string
-	Version = "dev"
-	Commit  = "none"
-	Date    = "unknown"
+	cfgFile    string
+	configOnce sync.Once
+	Version    = "dev"
+	Commit     = "none"
+	Date       = "unknown"
 )
 
 // rootCmd represents the base command
@@ -28,10 +35,18 @@ hardware acceleration, and quality controls.`,
 	SilenceErrors: true,
 }
 
-// Execute runs the root command
+// Execute runs the root command. The first SIGINT/SIGTERM stops new jobs
+// from starting and lets running ones finish; a second one aborts them.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := interrupt.Notify(context.Background())
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		stop()
+		if ctx.Err() != nil {
+			os.Exit(130)
+		}
 		os.Exit(1)
 	}
 }
@@ -42,7 +57,7 @@ func GetRootCmd() *cobra.Command {
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
+	cobra.OnInitialize(initConfig, initDetection)
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.sb.yaml)")
@@ -53,6 +68,8 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("dry-run", "n", false, "preview without converting")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().BoolP("flat", "f", false, "flatten output directory structure")
+	rootCmd.PersistentFlags().Bool("trust-extension", false, "recognize inputs by extension only, without reading file signatures")
+	rootCmd.PersistentFlags().Bool("probe-inputs", false, "confirm every input with ffprobe before converting")
 
 	// Bind flags to viper
 	viper.BindPFlag("workers", rootCmd.PersistentFlags().Lookup("workers"))
@@ -60,10 +77,41 @@ func init() {
 	viper.BindPFlag("skip_existing", rootCmd.PersistentFlags().Lookup("skip"))
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
 	viper.BindPFlag("flat_structure", rootCmd.PersistentFlags().Lookup("flat"))
+	viper.BindPFlag("trust_extension", rootCmd.PersistentFlags().Lookup("trust-extension"))
+	viper.BindPFlag("probe_inputs", rootCmd.PersistentFlags().Lookup("probe-inputs"))
+}
+
+// LoadConfig reads the config file and environment before command-line
+// parsing, so that converters defined in config can get their own commands.
+// The --config flag is picked out of os.Args directly.
+func LoadConfig() {
+	cfgFile = configFileFromArgs(os.Args[1:])
+	initConfig()
 }
 
-// initConfig reads in config file and ENV variables
+// configFileFromArgs returns the value of --config in args ("" if absent)
+func configFileFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--config="); ok {
+			return value
+		}
+		if arg == "--config" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// initConfig reads in config file and ENV variables (once)
 func initConfig() {
+	configOnce.Do(loadConfig)
+}
+
+// loadConfig reads the config file and registers the configured toolchains
+func loadConfig() {
 	if cfgFile != "" {
 		// Use config file from the flag
 		viper.SetConfigFile(cfgFile)
@@ -73,4 +121,49 @@ func initConfig() {
 	if err := config.Initialize(); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 	}
+
+	registerToolchains(config.Get())
+}
+
+// registerToolchains makes the configured ffmpeg/ffprobe binaries available to converters
+func registerToolchains(cfg *config.Config) {
+	executor.RegisterToolchain(executor.Toolchain{
+		Name:        executor.DefaultToolchain,
+		FFmpegPath:  cfg.FFmpegPath,
+		FFprobePath: cfg.FFprobePath,
+	})
+
+	for name, tc := range cfg.Toolchains {
+		executor.RegisterToolchain(executor.Toolchain{
+			Name:        name,
+			FFmpegPath:  tc.FFmpegPath,
+			FFprobePath: tc.FFprobePath,
+		})
+	}
+}
+
+// initDetection configures how input files are recognized, once flags are parsed
+func initDetection() {
+	detection := media.Detection{TrustExtension: viper.GetBool("trust_extension")}
+
+	if viper.GetBool("probe_inputs") && !detection.TrustExtension {
+		var (
+			once  sync.Once
+			probe executor.Executor
+			err   error
+		)
+		detection.Probe = func(path string) (string, error) {
+			once.Do(func() { probe, err = executor.New("") })
+			if err != nil {
+				return "", err
+			}
+			info, err := probe.GetInfo(context.Background(), path)
+			if err != nil {
+				return "", err
+			}
+			return info.Format.Name, nil
+		}
+	}
+
+	media.SetDetection(detection)
 }
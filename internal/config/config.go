@@ -18,24 +18,23 @@ type Config struct {
 	FlatStructure bool   `mapstructure:"flat_structure"`
 	Verbose       bool   `mapstructure:"verbose"`
 
-	// Format-specific settings
-	MP4 MP4Config `mapstructure:"mp4"`
-}
+	// Input detection
+	TrustExtension bool `mapstructure:"trust_extension"` // match inputs by extension only
+	ProbeInputs    bool `mapstructure:"probe_inputs"`    // confirm inputs with ffprobe
+
+	// External tools
+	FFmpegPath  string                     `mapstructure:"ffmpeg_path"`  // SB_FFMPEG
+	FFprobePath string                     `mapstructure:"ffprobe_path"` // SB_FFPROBE
+	Toolchains  map[string]ToolchainConfig `mapstructure:"toolchains"`
 
-// MP4Config contains MP4-specific configuration
-type MP4Config struct {
-	Quality   int            `mapstructure:"quality"` // CRF value
-	Preset    string         `mapstructure:"preset"`
-	Codec     string         `mapstructure:"codec"`
-	Audio     string         `mapstructure:"audio"`
-	Bitrate   string         `mapstructure:"bitrate"`
-	Hardware  HardwareConfig `mapstructure:"hardware"`
+	// Format-specific settings live in per-converter sections (e.g., "mp4")
+	// whose keys and defaults come from each converter's option schema
 }
 
-// HardwareConfig contains hardware acceleration settings
-type HardwareConfig struct {
-	Enabled bool   `mapstructure:"enabled"`
-	Type    string `mapstructure:"type"` // videotoolbox, nvenc, qsv
+// ToolchainConfig names an alternative ffmpeg/ffprobe pair
+type ToolchainConfig struct {
+	FFmpegPath  string `mapstructure:"ffmpeg_path"`
+	FFprobePath string `mapstructure:"ffprobe_path"`
 }
 
 var (
@@ -60,6 +59,8 @@ func Initialize() error {
 	// Enable environment variables
 	viper.SetEnvPrefix("SB")
 	viper.AutomaticEnv()
+	viper.BindEnv("ffmpeg_path", "SB_FFMPEG", "SB_FFMPEG_PATH")
+	viper.BindEnv("ffprobe_path", "SB_FFPROBE", "SB_FFPROBE_PATH")
 
 	// Try to read config file (not an error if it doesn't exist)
 	if err := viper.ReadInConfig(); err != nil {
@@ -85,15 +86,13 @@ func setDefaults() {
 	viper.SetDefault("output_dir", "")
 	viper.SetDefault("flat_structure", false)
 	viper.SetDefault("verbose", false)
+	viper.SetDefault("trust_extension", false)
+	viper.SetDefault("probe_inputs", false)
+	viper.SetDefault("ffmpeg_path", "")
+	viper.SetDefault("ffprobe_path", "")
 
-	// MP4 defaults
-	viper.SetDefault("mp4.quality", 23)
-	viper.SetDefault("mp4.preset", "medium")
-	viper.SetDefault("mp4.codec", "h264")
-	viper.SetDefault("mp4.audio", "aac")
-	viper.SetDefault("mp4.bitrate", "")
-	viper.SetDefault("mp4.hardware.enabled", false)
-	viper.SetDefault("mp4.hardware.type", "")
+	// Converter defaults are registered from their option schemas when the
+	// format commands are generated
 }
 
 // Get returns the current configuration
@@ -119,6 +118,11 @@ func GetBool(key string) bool {
 	return viper.GetBool(key)
 }
 
+// UnmarshalKey decodes the config value at key into out
+func UnmarshalKey(key string, out interface{}) error {
+	return viper.UnmarshalKey(key, out)
+}
+
 // Set sets a config value
 func Set(key string, value interface{}) {
 	viper.Set(key, value)
@@ -148,20 +152,138 @@ output_dir: ""          # Default output directory (empty = same as input)
 flat_structure: false   # Flatten directory structure in output
 verbose: false          # Enable verbose logging
 
+# Input detection (inputs are recognized by file signature, e.g., ftyp, EBML, RIFF)
+trust_extension: false  # Match inputs by extension only (fast path, no file reads)
+probe_inputs: false     # Confirm every input with ffprobe before converting
+
+# External tools (env: SB_FFMPEG, SB_FFPROBE)
+ffmpeg_path: ""         # ffmpeg binary (empty = ffmpeg from PATH)
+ffprobe_path: ""        # ffprobe binary (empty = next to ffmpeg, then PATH)
+
+# Named toolchains selectable per converter
+# toolchains:
+#   ffmpeg6:
+#     ffmpeg_path: /opt/ffmpeg-6/bin/ffmpeg-static
+#     ffprobe_path: /opt/ffmpeg-6/bin/ffprobe-static
+
 # MP4 conversion settings
 mp4:
   quality: 23           # CRF value (0-51, lower = better quality)
   preset: medium        # Encoding preset (ultrafast, fast, medium, slow, veryslow)
   codec: h264           # Video codec (h264, h265, vp9)
-  audio: aac            # Audio codec (aac, mp3, copy)
+  audio: aac            # Audio codec (aac, mp3, opus, copy)
+  audio_bitrate: 192k   # Audio bitrate
   bitrate: ""           # Video bitrate (e.g., "2M", "5M", empty = use CRF)
   hardware:
     enabled: false      # Enable hardware acceleration
     type: ""            # Hardware type (videotoolbox, nvenc, qsv)
+  normalize: false      # Normalize audio loudness (two-pass EBU R128)
+  integrated: -16       # Loudness target in LUFS when normalizing
+  true_peak: -1.5       # True peak ceiling in dBTP when normalizing
+  lra: 11               # Loudness range target in LU when normalizing
+  toolchain: ""         # Named toolchain (empty = default)
+
+# JPEG conversion settings (HEIC/HEIF photos)
+jpg:
+  quality: 92           # JPEG quality (1-100, higher = better)
+  auto_orient: true     # Rotate pixels upright (false = keep stored pixels, record orientation in EXIF)
+  exif: true            # Copy EXIF metadata (capture date, camera, GPS)
+  all_images: false     # Also write the other images of a burst as <name>_2.jpg, ...
+  toolchain: ""         # Named toolchain (empty = default)
+
+# Audio extraction settings (also aac, flac, opus, wav sections)
+mp3:
+  codec: libmp3lame     # Encoder
+  bitrate: 192k         # Bitrate (ignored when vbr >= 0)
+  vbr: -1               # LAME VBR quality (0-9, lower = better; -1 = use bitrate)
+  sample_rate: 0        # Sample rate in Hz (0 = source)
+  channels: ""          # Channel layout (mono, stereo, 5.1, 7.1; empty = source)
+  stream: ""            # Audio stream index or language (empty = default stream)
+  copy: true            # Copy the stream when the source codec already matches
+  tags: true            # Carry over title, artist and creation date
+
+# GIF conversion settings
+gif:
+  fps: 15               # Frames per second
+  width: 480            # Width in pixels (0 = source; never upscales)
+  loop: 0               # 0 = forever, -1 = play once, N = repeat N times
+  max_size: ""          # Size target (e.g., "5M"); lowers fps/width until it fits
+  dither: sierra2_4a    # Dithering (sierra2_4a, sierra2, floyd_steinberg, bayer, none)
+  stats_mode: full      # Palette statistics (full, diff for screen recordings)
+
+# WebM settings (the av1 section takes the same keys except codec)
+webm:
+  codec: vp9            # Video codec (vp9, av1)
+  av1_encoder: libaom-av1 # AV1 encoder (libaom-av1, libsvtav1 = single-pass only)
+  quality: 31           # CRF (0-63, lower = better quality)
+  max_bitrate: ""       # Bitrate cap for constrained quality (e.g., "2M")
+  passes: 2             # Encoding passes (1, 2)
+  cpu_used: 2           # Speed (libvpx/libaom 0-8, libsvtav1 preset 0-13)
+  row_mt: true          # Row-based multithreading
+  tile_columns: -1      # log2 tile columns (-1 = from the video width)
+  audio: opus           # Audio codec (opus, copy, none)
+  audio_bitrate: 128k   # Audio bitrate
+
+# Image resize settings
+resize:
+  format: jpg           # Output format (jpg, png, webp, gif, tiff, bmp)
+  quality: 85           # jpg/webp quality (1-100)
+  width: 0              # Target width in pixels (0 = unconstrained)
+  height: 0             # Target height in pixels (0 = unconstrained)
+  fit: inside           # inside, contain, cover or fill
+  sharpen: 0            # Unsharp mask amount after resizing (0 = off)
+  auto_orient: true     # Apply EXIF orientation
+
+# Thumbnail settings
+thumbs:
+  mode: poster          # poster, thumbnails or sheet
+  format: jpg           # Image format (jpg, png, webp)
+  quality: 85           # jpg/webp quality (1-100)
+  count: 9              # Thumbnails in thumbnails mode
+  width: 0              # Image or tile width (0 = source; sheets: 320)
+  columns: 4            # Contact sheet columns
+  rows: 4               # Contact sheet rows
+  timestamps: true      # Draw timestamps on contact sheet tiles
+  sprite: false         # Also write a sprite sheet and WebVTT track
+  sprite_interval: 10s  # Time between sprite thumbnails
+
+# HLS/DASH packaging settings
+hls:
+  ladder: "1080p:5000k,720p:2800k,480p:1400k,360p:800k" # Rungs (HEIGHTp:BITRATE)
+  segments: fmp4        # Segment container (fmp4, ts)
+  segment_duration: 6s  # Segment length; keyframes are aligned to it
+  dash: false           # Also write a DASH manifest (fmp4 only)
+  codec: h264           # Video codec (h264, hevc)
+  preset: fast          # Encoding preset
+  audio: aac            # Audio codec (aac, none)
+  audio_bitrate: 128k   # Audio bitrate
+
+# Loudness normalization settings (EBU R128, audio files)
+loudnorm:
+  integrated: -16       # Integrated loudness target in LUFS (-70 to -5)
+  true_peak: -1.5       # True peak ceiling in dBTP (-9 to 0)
+  lra: 11               # Loudness range target in LU (1 to 50)
+  format: mp3           # Output format (mp3, m4a, opus, flac, wav)
+  bitrate: 192k         # Bitrate of lossy formats
+  sample_rate: 0        # Sample rate in Hz (0 = source)
+
+# Declared converters: ffmpeg recipes registered like built-in converters
+# (standalone definitions can also go in ~/.sb/converters.d/*.yaml)
+# converters:
+#   - name: voice
+#     description: Extract speech as mono Opus
+#     inputs: [.mov, .mp4, .mkv]
+#     output: .opus
+#     cost: 5
+#     params:
+#       - name: bitrate
+#         default: 32k
+#         help: audio bitrate
+#     args: ["-vn", "-ac", "1", "-c:a", "libopus", "-application", "voip", "-b:a", "{{.bitrate}}"]
+#     requires:
+#       encoders: [libopus]
 
 # Future format settings can be added here
-# jpg:
-#   quality: 95
 # png:
 #   compression: 9
 `
@@ -0,0 +1,82 @@
+package converter
+
+import "context"
+
+// legacyAdapter runs a LegacyConverter through the Converter interface
+type legacyAdapter struct {
+	LegacyConverter
+}
+
+// Adapt wraps a LegacyConverter so it can be registered and run as a
+// Converter. The job context is passed to it through Options.Context and
+// Options.Abort, and the resolved output path through Options.Output.
+func Adapt(conv LegacyConverter) Converter {
+	return &legacyAdapter{LegacyConverter: conv}
+}
+
+// Unwrap returns the converter wrapped by Adapt, or conv itself
+func Unwrap(conv Base) Base {
+	if a, ok := conv.(*legacyAdapter); ok {
+		return a.LegacyConverter
+	}
+	return conv
+}
+
+// Convert runs the legacy converter with ctx and job threaded into its options
+func (a *legacyAdapter) Convert(ctx context.Context, job Job) (*Result, error) {
+	opts := job.Options
+	opts.Context = ctx
+	opts.Abort = ctx
+	opts.Output = job.Output
+	return a.LegacyConverter.Convert(job.Input, opts)
+}
+
+// OutputPath delegates to the wrapped converter
+func (a *legacyAdapter) OutputPath(input string, opts Options) string {
+	return OutputPath(a.LegacyConverter, input, opts)
+}
+
+// Preflight delegates to the wrapped converter if it implements Preflighter
+func (a *legacyAdapter) Preflight() error {
+	if p, ok := a.LegacyConverter.(Preflighter); ok {
+		return p.Preflight()
+	}
+	return nil
+}
+
+// Setup delegates to the wrapped converter if it has setup hooks
+func (a *legacyAdapter) Setup() error {
+	if s, ok := a.LegacyConverter.(interface{ Setup() error }); ok {
+		return s.Setup()
+	}
+	return nil
+}
+
+// Teardown delegates to the wrapped converter if it has teardown hooks
+func (a *legacyAdapter) Teardown() error {
+	if s, ok := a.LegacyConverter.(interface{ Teardown() error }); ok {
+		return s.Teardown()
+	}
+	return nil
+}
+
+// OptionSchema delegates to the wrapped converter if it declares options
+func (a *legacyAdapter) OptionSchema() []OptionSpec {
+	if c, ok := a.LegacyConverter.(interface{ OptionSchema() []OptionSpec }); ok {
+		return c.OptionSchema()
+	}
+	return nil
+}
+
+// Configure delegates to the wrapped converter if it declares options
+func (a *legacyAdapter) Configure(values OptionValues) error {
+	if c, ok := a.LegacyConverter.(interface{ Configure(OptionValues) error }); ok {
+		return c.Configure(values)
+	}
+	return nil
+}
+
+// Cost delegates to the wrapped converter's cost hint
+func (a *legacyAdapter) Cost() int {
+	return CostOf(a.LegacyConverter)
+}
@@ -0,0 +1,227 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/ui"
+)
+
+// BatchError reports the failed jobs of a batch
+type BatchError struct {
+	Stats    Stats
+	Failures []*Result // failed, interrupted and not-started jobs in input order
+}
+
+func (e *BatchError) Error() string {
+	if e.Stats.Interrupted > 0 || e.Stats.NotStarted > 0 {
+		return fmt.Sprintf("batch interrupted: %d completed, %d interrupted, %d not started",
+			e.Stats.Success+e.Stats.Skipped+e.Stats.Failed, e.Stats.Interrupted, e.Stats.NotStarted)
+	}
+	return fmt.Sprintf("%d conversion(s) failed", e.Stats.Failed)
+}
+
+// Unwrap returns the individual job errors
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, r := range e.Failures {
+		if r.Error != nil {
+			errs = append(errs, r.Error)
+		}
+	}
+	return errs
+}
+
+// Batch converts inputs using the converter's own ConvertBatch if it
+// implements BatchConverter, and RunBatch otherwise
+func Batch(ctx context.Context, conv Converter, inputs []string, opts Options) ([]*Result, error) {
+	if bc, ok := conv.(BatchConverter); ok {
+		return bc.ConvertBatch(ctx, inputs, opts)
+	}
+	return RunBatch(ctx, conv, inputs, opts)
+}
+
+// RunBatch converts inputs in parallel with conv. Cancelling ctx stops new
+// jobs from starting; cancelling opts.Abort (default ctx) kills running ones.
+// It runs Preflight and Setup/Teardown hooks when implemented, drives the
+// progress bar, emits lifecycle events to opts.OnEvent, prints a summary if
+// opts.ShowSummary is set and aggregates errors into a *BatchError. Results
+// are returned in input order.
+func RunBatch(ctx context.Context, conv Converter, inputs []string, opts Options) (results []*Result, err error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no input files provided")
+	}
+
+	// Refuse up front if an external dependency is missing
+	if p, ok := conv.(Preflighter); ok {
+		if err := p.Preflight(); err != nil {
+			return nil, err
+		}
+	}
+
+	if s, ok := conv.(ConverterWithSetup); ok {
+		if err := s.Setup(); err != nil {
+			return nil, fmt.Errorf("%s setup failed: %w", conv.Name(), err)
+		}
+		defer func() {
+			if tdErr := s.Teardown(); tdErr != nil {
+				err = errors.Join(err, fmt.Errorf("%s teardown failed: %w", conv.Name(), tdErr))
+			}
+		}()
+	}
+
+	// Results are stored by input index, so no locking is needed and the
+	// returned slice preserves input order
+	results = make([]*Result, len(inputs))
+	start := time.Now()
+
+	events := NewEventDispatcher(opts.OnEvent, len(inputs))
+	for i, input := range inputs {
+		events.Emit(Event{Type: EventQueued, Index: i, Input: input})
+	}
+
+	// Create progress bar
+	showProgress := opts.ShowProgress && !opts.DryRun && !opts.Verbose
+	progress := ui.NewProgressBar(len(inputs), "Converting", showProgress)
+
+	// Create worker pool
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	pool := executor.NewPoolWithContext(ctx, opts.Abort, opts.Workers)
+	pool.Start()
+
+	// Submit jobs
+	go func() {
+		for i, input := range inputs {
+			pool.Submit(func(ctx context.Context) error {
+				events.Emit(Event{Type: EventStarted, Index: i, Input: input})
+
+				jobOpts := opts
+				jobOpts.OnProgress = func(p Progress) {
+					p.Current = i + 1
+					p.Total = len(inputs)
+					if showProgress {
+						progress.Describe(ui.FormatFileProgress(p.CurrentFile, p.Percentage, p.Speed, p.ETA))
+					}
+					if opts.OnProgress != nil {
+						opts.OnProgress(p)
+					}
+					events.Emit(Event{Type: EventProgress, Index: i, Input: input, Progress: &p})
+				}
+
+				result, err := conv.Convert(ctx, NewJob(conv, input, jobOpts))
+				if result == nil {
+					result = &Result{Input: input, Error: err}
+				}
+				results[i] = result
+				progress.Increment()
+				events.EmitResult(i, result)
+
+				return err
+			})
+		}
+		pool.Stop()
+	}()
+
+	// Wait for completion
+	for range pool.Results() {
+	}
+
+	progress.Finish()
+
+	// Jobs the pool never ran still get a result
+	for i, input := range inputs {
+		if results[i] == nil {
+			results[i] = &Result{Input: input, Error: executor.ErrNotStarted}
+			events.Emit(Event{
+				Type:   EventNotStarted,
+				Index:  i,
+				Input:  input,
+				Result: results[i],
+				Error:  executor.ErrNotStarted,
+			})
+		}
+	}
+
+	// Calculate statistics
+	stats := StatsOf(results)
+	stats.StartTime, stats.EndTime = start, time.Now()
+
+	failures := []*Result{}
+	for _, result := range results {
+		if !result.Success && !result.Skipped {
+			failures = append(failures, result)
+		}
+	}
+
+	if opts.ShowSummary && !opts.Verbose {
+		summary := stats.Summary()
+		summary.Loudness = loudnessLines(results)
+		ui.PrintSummary(summary)
+	}
+
+	if len(failures) > 0 {
+		return results, &BatchError{Stats: stats, Failures: failures}
+	}
+
+	return results, nil
+}
+
+// StatsOf counts the outcomes of results
+func StatsOf(results []*Result) Stats {
+	stats := Stats{Total: len(results)}
+	for _, result := range results {
+		switch {
+		case result.Error == executor.ErrNotStarted:
+			stats.NotStarted++
+		case result.Skipped:
+			stats.Skipped++
+		case result.Interrupted:
+			stats.Interrupted++
+		case result.Success:
+			stats.Success++
+		default:
+			stats.Failed++
+		}
+	}
+	return stats
+}
+
+// loudnessLines lists the loudness of the normalized files for the summary
+func loudnessLines(results []*Result) []ui.LoudnessLine {
+	lines := []ui.LoudnessLine{}
+	for _, result := range results {
+		l := result.Loudness
+		if l == nil || !result.Success {
+			continue
+		}
+		lines = append(lines, ui.LoudnessLine{
+			File:        result.Output,
+			MeasuredI:   l.Measured.I,
+			MeasuredTP:  l.Measured.TP,
+			MeasuredLRA: l.Measured.LRA,
+			I:           l.Achieved.I,
+			TP:          l.Achieved.TP,
+			LRA:         l.Achieved.LRA,
+			Type:        l.NormalizationType,
+		})
+	}
+	return lines
+}
+
+// Summary converts statistics for display
+func (s Stats) Summary() ui.Summary {
+	return ui.Summary{
+		Total:       s.Total,
+		Success:     s.Success,
+		Failed:      s.Failed,
+		Skipped:     s.Skipped,
+		Interrupted: s.Interrupted,
+		NotStarted:  s.NotStarted,
+		Duration:    s.EndTime.Sub(s.StartTime),
+	}
+}
@@ -0,0 +1,159 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Chain runs a multi-step plan as a single converter. Intermediate files are
+// written to a temporary workspace that is removed when the job finishes;
+// only the last step writes to the job's output path.
+type Chain struct {
+	plan *Plan
+}
+
+// Name joins the names of the chained converters, e.g., "jpg+webp"
+func (c *Chain) Name() string {
+	names := make([]string, len(c.plan.Steps))
+	for i, s := range c.plan.Steps {
+		names[i] = s.Converter.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+// Description describes the chain
+func (c *Chain) Description() string {
+	return "Multi-step conversion " + c.plan.String()
+}
+
+// SupportedInputs returns the input extension of the plan
+func (c *Chain) SupportedInputs() []string {
+	return []string{c.plan.From()}
+}
+
+// OutputExtension returns the output extension of the plan
+func (c *Chain) OutputExtension() string {
+	return c.plan.To()
+}
+
+// Validate checks the input against the first step
+func (c *Chain) Validate(input string) error {
+	return c.plan.Steps[0].Converter.Validate(input)
+}
+
+// Plan returns the executed plan
+func (c *Chain) Plan() *Plan {
+	return c.plan
+}
+
+// Cost returns the summed cost of all steps
+func (c *Chain) Cost() int {
+	return c.plan.Cost()
+}
+
+// Preflight checks the dependencies of every step
+func (c *Chain) Preflight() error {
+	var errs []error
+	for _, s := range c.plan.Steps {
+		if p, ok := s.Converter.(Preflighter); ok {
+			if err := p.Preflight(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Convert runs every step of the plan in order
+func (c *Chain) Convert(ctx context.Context, job Job) (*Result, error) {
+	opts := job.Options
+	result := &Result{Input: job.Input, Output: job.Output}
+	start := time.Now()
+
+	if err := c.Validate(job.Input); err != nil {
+		result.Error = err
+		result.Duration = time.Since(start)
+		return result, err
+	}
+
+	if opts.SkipExisting {
+		if _, err := os.Stat(job.Output); err == nil {
+			result.Skipped = true
+			result.SkipReason = "file already exists"
+			result.Duration = time.Since(start)
+			return result, nil
+		}
+	}
+
+	if info, err := os.Stat(job.Input); err == nil {
+		result.InputSize = info.Size()
+	}
+
+	// Intermediate files do not exist in dry-run mode, so only report the plan
+	if opts.DryRun {
+		fmt.Printf("[DRY-RUN] Would convert: %s -> %s via %s\n", job.Input, job.Output, c.plan)
+		result.Success = true
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+
+	workspace, err := os.MkdirTemp("", "sb-chain-*")
+	if err != nil {
+		result.Error = fmt.Errorf("failed to create workspace: %w", err)
+		result.Duration = time.Since(start)
+		return result, result.Error
+	}
+	defer os.RemoveAll(workspace)
+
+	base := strings.TrimSuffix(filepath.Base(job.Input), filepath.Ext(job.Input))
+	input := job.Input
+	n := len(c.plan.Steps)
+
+	for i, step := range c.plan.Steps {
+		stepOpts := opts
+		stepOpts.SkipExisting = false
+		stepOpts.Output = job.Output
+		if i < n-1 {
+			stepOpts.Output = filepath.Join(workspace, fmt.Sprintf("%d-%s%s", i, base, step.To))
+		}
+
+		// Report progress across the whole chain
+		if opts.OnProgress != nil {
+			i := i
+			stepOpts.OnProgress = func(p Progress) {
+				p.CurrentFile = job.Input
+				p.Percentage = (float64(i)*100 + p.Percentage) / float64(n)
+				opts.OnProgress(p)
+			}
+		}
+
+		stepResult, err := step.Converter.Convert(ctx, Job{Input: input, Output: stepOpts.Output, Options: stepOpts})
+		if err == nil && stepResult != nil && stepResult.Error != nil {
+			err = stepResult.Error
+		}
+		if err != nil {
+			result.Interrupted = stepResult != nil && stepResult.Interrupted
+			result.Error = fmt.Errorf("step %d/%d (%s %s -> %s): %w", i+1, n, step.Converter.Name(), step.From, step.To, err)
+			result.Duration = time.Since(start)
+			return result, result.Error
+		}
+
+		if stepResult != nil && stepResult.Loudness != nil {
+			result.Loudness = stepResult.Loudness
+		}
+		input = stepOpts.Output
+	}
+
+	if info, err := os.Stat(job.Output); err == nil {
+		result.OutputSize = info.Size()
+	}
+
+	result.Success = true
+	result.Duration = time.Since(start)
+	return result, nil
+}
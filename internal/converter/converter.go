@@ -1,7 +1,9 @@
 package converter
 
-// Converter defines the interface that all media converters must implement
-type Converter interface {
+import "context"
+
+// Base describes a converter independent of how it is invoked
+type Base interface {
 	// Name returns the unique name of this converter
 	Name() string
 
@@ -18,13 +20,36 @@ type Converter interface {
 
 	// Validate checks if the input file can be processed
 	Validate(input string) error
+}
+
+// Converter defines the interface that all media converters must implement
+type Converter interface {
+	Base
+
+	// Convert processes a single job. Cancelling ctx must stop the
+	// conversion and leave no partial output behind.
+	Convert(ctx context.Context, job Job) (*Result, error)
+}
+
+// LegacyConverter is the original interface that receives its context
+// through Options. Wrap implementations with Adapt to register them.
+//
+// Deprecated: implement Converter instead.
+type LegacyConverter interface {
+	Base
 
 	// Convert processes a single file with the given options
 	Convert(input string, opts Options) (*Result, error)
+}
+
+// BatchConverter is implemented by converters that need custom batch
+// scheduling; all others are run by RunBatch
+type BatchConverter interface {
+	Converter
 
 	// ConvertBatch processes multiple files with the given options
 	// Returns a slice of results and any fatal error
-	ConvertBatch(inputs []string, opts Options) ([]*Result, error)
+	ConvertBatch(ctx context.Context, inputs []string, opts Options) ([]*Result, error)
 }
 
 // ConverterWithSetup extends Converter with setup/teardown hooks
@@ -37,3 +62,17 @@ type ConverterWithSetup interface {
 	// Teardown is called after all conversions complete
 	Teardown() error
 }
+
+// Preflighter is implemented by converters that can verify their external
+// dependencies (e.g., ffmpeg encoders) before a batch starts
+type Preflighter interface {
+	// Preflight returns an error naming any missing component
+	Preflight() error
+}
+
+// OutputPather is implemented by converters that choose their own output
+// paths instead of DefaultOutputPath
+type OutputPather interface {
+	// OutputPath returns the path input will be converted to with opts
+	OutputPath(input string, opts Options) string
+}
@@ -0,0 +1,107 @@
+package converter
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies a stage in a job's lifecycle
+type EventType string
+
+const (
+	EventQueued     EventType = "queued"      // job accepted into the batch
+	EventStarted    EventType = "started"     // a worker began converting
+	EventProgress   EventType = "progress"    // encode progress update
+	EventFinished   EventType = "finished"    // conversion succeeded
+	EventSkipped    EventType = "skipped"     // output already existed
+	EventFailed     EventType = "failed"      // conversion failed or was interrupted
+	EventNotStarted EventType = "not_started" // batch was interrupted before the job ran
+)
+
+// Event describes a lifecycle change of one job in a batch
+type Event struct {
+	Type     EventType
+	Index    int // position of the job in the batch input list
+	Total    int // number of jobs in the batch
+	Input    string
+	Result   *Result   // set for finished, skipped, failed and not_started
+	Progress *Progress // set for progress
+	Error    error     // set for failed and not_started
+	Time     time.Time
+}
+
+// EventHandler receives batch events
+type EventHandler func(Event)
+
+// MultiHandler returns a handler that forwards each event to every handler
+func MultiHandler(handlers ...EventHandler) EventHandler {
+	return func(ev Event) {
+		for _, h := range handlers {
+			if h != nil {
+				h(ev)
+			}
+		}
+	}
+}
+
+// ChannelHandler returns a handler that sends events to ch. Sends block, so
+// the consumer must keep reading until the batch returns.
+func ChannelHandler(ch chan<- Event) EventHandler {
+	return func(ev Event) {
+		ch <- ev
+	}
+}
+
+// EventDispatcher delivers events to a handler one at a time, so handlers do
+// not need to be safe for concurrent use
+type EventDispatcher struct {
+	mu      sync.Mutex
+	handler EventHandler
+	total   int
+}
+
+// NewEventDispatcher creates a dispatcher for a batch of total jobs;
+// a nil handler discards all events
+func NewEventDispatcher(handler EventHandler, total int) *EventDispatcher {
+	return &EventDispatcher{
+		handler: handler,
+		total:   total,
+	}
+}
+
+// Emit stamps and delivers an event
+func (d *EventDispatcher) Emit(ev Event) {
+	if d == nil || d.handler == nil {
+		return
+	}
+
+	ev.Total = d.total
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handler(ev)
+}
+
+// EmitResult emits the terminal event matching a job's result
+func (d *EventDispatcher) EmitResult(index int, result *Result) {
+	ev := Event{
+		Index:  index,
+		Input:  result.Input,
+		Result: result,
+		Error:  result.Error,
+	}
+
+	switch {
+	case result.Skipped:
+		ev.Type = EventSkipped
+	case result.Success:
+		ev.Type = EventFinished
+	default:
+		ev.Type = EventFailed
+	}
+
+	d.Emit(ev)
+}
@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Job is a single unit of conversion work
+type Job struct {
+	Input   string
+	Output  string  // resolved output path
+	Options Options // per-job options
+}
+
+// NewJob creates a job for input, resolving its output path with conv
+func NewJob(conv Base, input string, opts Options) Job {
+	return Job{
+		Input:   input,
+		Output:  OutputPath(conv, input, opts),
+		Options: opts,
+	}
+}
+
+// OutputPath returns the path conv writes input to: opts.Output when set,
+// the converter's own choice when it implements OutputPather, and
+// DefaultOutputPath otherwise
+func OutputPath(conv Base, input string, opts Options) string {
+	if opts.Output != "" {
+		return opts.Output
+	}
+	if p, ok := conv.(OutputPather); ok {
+		return p.OutputPath(input, opts)
+	}
+	return DefaultOutputPath(conv, input, opts)
+}
+
+// DefaultOutputPath replaces the input extension with the converter's output
+// extension and places the file in opts.OutputDir, or next to the input.
+// An input that already has the output extension (a misnamed file accepted
+// by its content) gets a ".converted" suffix instead of being overwritten.
+func DefaultOutputPath(conv Base, input string, opts Options) string {
+	baseName := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+
+	// No output dir specified: place next to input file
+	dir := filepath.Dir(input)
+	if opts.OutputDir != "" {
+		// TODO: preserve relative paths when FlatStructure is false
+		dir = opts.OutputDir
+	}
+
+	output := filepath.Join(dir, baseName+conv.OutputExtension())
+	if samePath(output, input) {
+		output = filepath.Join(dir, baseName+".converted"+conv.OutputExtension())
+	}
+	return output
+}
+
+// samePath reports whether a and b name the same file
+func samePath(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
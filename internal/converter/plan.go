@@ -0,0 +1,174 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultCost is the cost of a converter that does not implement Coster
+const DefaultCost = 10
+
+// Coster is implemented by converters that give the planner a cost hint.
+// Costs are relative: about 1 for a remux, 10 for a typical re-encode and
+// higher for slow or lossy conversions.
+type Coster interface {
+	Cost() int
+}
+
+// CostOf returns the cost hint of conv
+func CostOf(conv Base) int {
+	if c, ok := conv.(Coster); ok {
+		if cost := c.Cost(); cost > 0 {
+			return cost
+		}
+	}
+	return DefaultCost
+}
+
+// Step is one conversion in a plan
+type Step struct {
+	Converter Converter
+	From, To  string // input and output extensions
+}
+
+// Plan is a chain of conversions from one extension to another
+type Plan struct {
+	Steps []Step
+}
+
+// From returns the input extension of the plan
+func (p *Plan) From() string {
+	return p.Steps[0].From
+}
+
+// To returns the output extension of the plan
+func (p *Plan) To() string {
+	return p.Steps[len(p.Steps)-1].To
+}
+
+// Cost returns the summed cost hints of all steps
+func (p *Plan) Cost() int {
+	total := 0
+	for _, s := range p.Steps {
+		total += CostOf(s.Converter)
+	}
+	return total
+}
+
+// String describes the plan, e.g., ".heic -[jpg]-> .jpg -[webp]-> .webp"
+func (p *Plan) String() string {
+	var b strings.Builder
+	b.WriteString(p.From())
+	for _, s := range p.Steps {
+		fmt.Fprintf(&b, " -[%s]-> %s", s.Converter.Name(), s.To)
+	}
+	return b.String()
+}
+
+// Converter returns a converter executing the plan: the only step's
+// converter for single-step plans, a Chain otherwise
+func (p *Plan) Converter() Converter {
+	if len(p.Steps) == 1 {
+		return p.Steps[0].Converter
+	}
+	return &Chain{plan: p}
+}
+
+// FindPlan finds the cheapest chain of converters turning files with
+// extension in into files with extension out. Ties are broken by fewer
+// steps, then by converter names.
+func FindPlan(in, out string) (*Plan, error) {
+	return defaultRegistry.FindPlan(in, out)
+}
+
+// FindPlan finds the cheapest chain of converters turning files with
+// extension in into files with extension out
+func (r *Registry) FindPlan(in, out string) (*Plan, error) {
+	in, out = NormalizeExt(in), NormalizeExt(out)
+	if in == out {
+		return nil, fmt.Errorf("input is already %s", out)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Cheapest converter for every edge of the extension graph
+	edges := make(map[string][]Step)
+	for key := range r.routes {
+		if conv := r.cheapest(key); conv != nil {
+			edges[key.in] = append(edges[key.in], Step{Converter: conv, From: key.in, To: key.out})
+		}
+	}
+
+	// Dijkstra over extensions; the graph is small, so a linear scan for the
+	// next node is fine
+	best := map[string]*candidate{in: {}}
+	done := make(map[string]bool)
+	for {
+		node := ""
+		for ext, c := range best {
+			if !done[ext] && (node == "" || c.less(best[node])) {
+				node = ext
+			}
+		}
+		if node == "" {
+			return nil, fmt.Errorf("%w from %s to %s", ErrNoRoute, in, out)
+		}
+		if node == out {
+			return &Plan{Steps: best[node].steps}, nil
+		}
+		done[node] = true
+
+		for _, step := range edges[node] {
+			if done[step.To] {
+				continue
+			}
+			next := best[node].extend(step)
+			if cur, ok := best[step.To]; !ok || next.less(cur) {
+				best[step.To] = next
+			}
+		}
+	}
+}
+
+// cheapest returns the lowest-cost converter registered for key, preferring
+// the first by name on equal cost. The caller must hold r.mu.
+func (r *Registry) cheapest(key route) Converter {
+	var best Converter
+	for _, name := range r.routes[key] {
+		conv := r.converters[name]
+		if best == nil || CostOf(conv) < CostOf(best) {
+			best = conv
+		}
+	}
+	return best
+}
+
+// candidate is the best known path to an extension
+type candidate struct {
+	cost  int
+	steps []Step
+	names string // converter names along the path, for deterministic ties
+}
+
+// extend returns the candidate reached by taking step after c
+func (c *candidate) extend(step Step) *candidate {
+	steps := make([]Step, len(c.steps), len(c.steps)+1)
+	copy(steps, c.steps)
+	return &candidate{
+		cost:  c.cost + CostOf(step.Converter),
+		steps: append(steps, step),
+		names: c.names + "/" + step.Converter.Name(),
+	}
+}
+
+// less orders candidates by cost, then number of steps, then names
+func (c *candidate) less(o *candidate) bool {
+	if c.cost != o.cost {
+		return c.cost < o.cost
+	}
+	if len(c.steps) != len(o.steps) {
+		return len(c.steps) < len(o.steps)
+	}
+	return c.names < o.names
+}
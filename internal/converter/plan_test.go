@@ -0,0 +1,172 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// stub is a converter that only describes a route
+type stub struct {
+	name string
+	in   []string
+	out  string
+	cost int
+}
+
+func (s *stub) Name() string              { return s.name }
+func (s *stub) Description() string       { return s.name }
+func (s *stub) SupportedInputs() []string { return s.in }
+func (s *stub) OutputExtension() string   { return s.out }
+func (s *stub) Validate(string) error     { return nil }
+func (s *stub) Cost() int                 { return s.cost }
+
+func (s *stub) Convert(context.Context, Job) (*Result, error) {
+	return nil, errors.New("not implemented")
+}
+
+// registryOf returns a registry holding convs
+func registryOf(t *testing.T, convs ...*stub) *Registry {
+	t.Helper()
+	r := NewRegistry()
+	for _, c := range convs {
+		if err := r.Register(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+func TestFindPlan(t *testing.T) {
+	tests := []struct {
+		name  string
+		convs []*stub
+		in    string
+		out   string
+		want  string // Plan.String()
+	}{
+		{
+			name:  "direct route",
+			convs: []*stub{{"mp4", []string{".mov"}, ".mp4", 10}},
+			in:    ".mov", out: ".mp4",
+			want: ".mov -[mp4]-> .mp4",
+		},
+		{
+			name: "two hops",
+			convs: []*stub{
+				{"jpg", []string{".heic"}, ".jpg", 5},
+				{"webp", []string{".jpg", ".png"}, ".webp", 5},
+			},
+			in: "HEIC", out: "webp",
+			want: ".heic -[jpg]-> .jpg -[webp]-> .webp",
+		},
+		{
+			name: "cheaper chain beats a costly direct route",
+			convs: []*stub{
+				{"direct", []string{".a"}, ".c", 30},
+				{"ab", []string{".a"}, ".b", 5},
+				{"bc", []string{".b"}, ".c", 5},
+			},
+			in: ".a", out: ".c",
+			want: ".a -[ab]-> .b -[bc]-> .c",
+		},
+		{
+			name: "equal cost prefers fewer steps",
+			convs: []*stub{
+				{"direct", []string{".a"}, ".c", 10},
+				{"ab", []string{".a"}, ".b", 5},
+				{"bc", []string{".b"}, ".c", 5},
+			},
+			in: ".a", out: ".c",
+			want: ".a -[direct]-> .c",
+		},
+		{
+			name: "equal cost and steps prefers converter names",
+			convs: []*stub{
+				{"zz", []string{".a"}, ".b", 5},
+				{"bd", []string{".b"}, ".d", 5},
+				{"aa", []string{".a"}, ".c", 5},
+				{"cd", []string{".c"}, ".d", 5},
+			},
+			in: ".a", out: ".d",
+			want: ".a -[aa]-> .c -[cd]-> .d",
+		},
+		{
+			name: "cheapest converter on a shared route",
+			convs: []*stub{
+				{"slow", []string{".wav"}, ".mp3", 20},
+				{"fast", []string{".wav"}, ".mp3", 5},
+			},
+			in: ".wav", out: ".mp3",
+			want: ".wav -[fast]-> .mp3",
+		},
+		{
+			name: "first name on a shared route with equal cost",
+			convs: []*stub{
+				{"b", []string{".wav"}, ".mp3", 5},
+				{"a", []string{".wav"}, ".mp3", 5},
+			},
+			in: ".wav", out: ".mp3",
+			want: ".wav -[a]-> .mp3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := registryOf(t, tt.convs...).FindPlan(tt.in, tt.out)
+			if err != nil {
+				t.Fatalf("FindPlan() error = %v", err)
+			}
+			if got := plan.String(); got != tt.want {
+				t.Errorf("FindPlan() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPlanUnreachable(t *testing.T) {
+	r := registryOf(t,
+		&stub{"ab", []string{".a"}, ".b", 5},
+		&stub{"cd", []string{".c"}, ".d", 5},
+		&stub{"ba", []string{".b"}, ".a", 5}, // a cycle must not loop forever
+	)
+
+	for _, out := range []string{".d", ".c", ".zzz"} {
+		if _, err := r.FindPlan(".a", out); !errors.Is(err, ErrNoRoute) {
+			t.Errorf("FindPlan(.a, %s) error = %v, want ErrNoRoute", out, err)
+		}
+	}
+}
+
+func TestFindPlanSameExtension(t *testing.T) {
+	r := registryOf(t, &stub{"ab", []string{".a"}, ".b", 5})
+	if _, err := r.FindPlan(".A", "a"); err == nil {
+		t.Error("FindPlan() to the input extension succeeded, want an error")
+	}
+}
+
+func TestPlanConverter(t *testing.T) {
+	r := registryOf(t,
+		&stub{"ab", []string{".a"}, ".b", 5},
+		&stub{"bc", []string{".b"}, ".c", 7},
+	)
+
+	single, err := r.FindPlan(".a", ".b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conv := single.Converter(); conv.Name() != "ab" {
+		t.Errorf("single-step Converter() = %s, want the step's converter", conv.Name())
+	}
+
+	chain, err := r.FindPlan(".a", ".c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := chain.Converter().(*Chain); !ok {
+		t.Errorf("multi-step Converter() = %T, want *Chain", chain.Converter())
+	}
+	if chain.Cost() != 12 {
+		t.Errorf("Cost() = %d, want 12", chain.Cost())
+	}
+}
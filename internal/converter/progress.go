@@ -0,0 +1,44 @@
+package converter
+
+import (
+	"time"
+
+	"github.com/onedusk/sb/internal/executor"
+)
+
+// ProgressFrom converts ffmpeg progress of pass index (0-based) out of
+// passes into the progress of the single file input, scaling the percentage
+// so the passes together run from 0 to 100
+func ProgressFrom(p executor.ProgressInfo, input string, index, passes int) Progress {
+	if passes < 1 {
+		passes = 1
+	}
+	return Progress{
+		Current:     1,
+		Total:       1,
+		CurrentFile: input,
+		Percentage:  (float64(index)*100 + p.Percentage) / float64(passes),
+		Elapsed:     p.OutTime,
+		Duration:    p.Duration,
+		FPS:         p.FPS,
+		Speed:       p.Speed,
+		Bitrate:     p.Bitrate,
+		Size:        p.TotalSize,
+		ETA:         p.ETA,
+	}
+}
+
+// RunOptionsFor returns the ffmpeg run options of pass index out of passes
+// over input, reporting progress to opts.OnProgress if it is set
+func RunOptionsFor(opts Options, input string, duration time.Duration, index, passes int) executor.RunOptions {
+	runOpts := executor.RunOptions{Verbose: opts.Verbose}
+	if opts.OnProgress == nil {
+		return runOpts
+	}
+
+	runOpts.Duration = duration
+	runOpts.Progress = func(p executor.ProgressInfo) {
+		opts.OnProgress(ProgressFrom(p, input, index, passes))
+	}
+	return runOpts
+}
@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onedusk/sb/internal/executor"
+)
+
+func TestProgressFrom(t *testing.T) {
+	p := executor.ProgressInfo{
+		OutTime:    30 * time.Second,
+		Duration:   time.Minute,
+		FPS:        24,
+		Speed:      1.5,
+		Bitrate:    "800kbits/s",
+		TotalSize:  4096,
+		Percentage: 50,
+		ETA:        20 * time.Second,
+	}
+
+	tests := []struct {
+		name          string
+		index, passes int
+		want          float64
+	}{
+		{"single pass", 0, 1, 50},
+		{"first of two passes", 0, 2, 25},
+		{"second of two passes", 1, 2, 75},
+		{"no passes counts as one", 0, 0, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ProgressFrom(p, "in.mov", tt.index, tt.passes)
+			if got.Percentage != tt.want {
+				t.Errorf("Percentage = %v, want %v", got.Percentage, tt.want)
+			}
+			want := Progress{
+				Current: 1, Total: 1, CurrentFile: "in.mov", Percentage: tt.want,
+				Elapsed: 30 * time.Second, Duration: time.Minute, FPS: 24, Speed: 1.5,
+				Bitrate: "800kbits/s", Size: 4096, ETA: 20 * time.Second,
+			}
+			if got != want {
+				t.Errorf("ProgressFrom() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestRunOptionsFor(t *testing.T) {
+	quiet := RunOptionsFor(Options{Verbose: true}, "in.mov", time.Minute, 0, 1)
+	if quiet.Progress != nil || quiet.Duration != 0 || !quiet.Verbose {
+		t.Errorf("without OnProgress = %+v, want only Verbose", quiet)
+	}
+
+	var got []Progress
+	opts := Options{OnProgress: func(p Progress) { got = append(got, p) }}
+	runOpts := RunOptionsFor(opts, "in.mov", time.Minute, 1, 2)
+	if runOpts.Duration != time.Minute || runOpts.Progress == nil {
+		t.Fatalf("with OnProgress = %+v, want duration and callback", runOpts)
+	}
+
+	runOpts.Progress(executor.ProgressInfo{Percentage: 100})
+	if len(got) != 1 || got[0].Percentage != 100 || got[0].CurrentFile != "in.mov" {
+		t.Errorf("reported %+v, want the end of the second pass", got)
+	}
+}
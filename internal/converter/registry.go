@@ -1,45 +1,95 @@
 package converter
 
 import (
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 )
 
-var (
-	registry = &Registry{
-		converters: make(map[string]Converter),
-	}
-)
+// defaultRegistry holds the converters registered by processor packages
+var defaultRegistry = NewRegistry()
+
+// ErrNoRoute is returned when no converter maps an input extension to the
+// requested output extension
+var ErrNoRoute = errors.New("no converter available")
+
+// Factory creates a new, unconfigured instance of a converter
+type Factory func() Converter
+
+// route is an (input extension, output extension) pair
+type route struct {
+	in, out string
+}
 
 // Registry manages available converters
 type Registry struct {
 	mu         sync.RWMutex
 	converters map[string]Converter
+	factories  map[string]Factory
+	routes     map[route][]string // converter names by route, sorted
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		converters: make(map[string]Converter),
+		factories:  make(map[string]Factory),
+		routes:     make(map[route][]string),
+	}
 }
 
-// Register adds a converter to the registry
+// Default returns the registry used by the package-level functions
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Register adds a converter to the default registry
 func Register(conv Converter) error {
-	return registry.Register(conv)
+	return defaultRegistry.Register(conv)
+}
+
+// RegisterFactory adds the converter created by factory to the default registry
+func RegisterFactory(factory Factory) error {
+	return defaultRegistry.RegisterFactory(factory)
 }
 
 // Get retrieves a converter by name
 func Get(name string) (Converter, error) {
-	return registry.Get(name)
+	return defaultRegistry.Get(name)
 }
 
 // List returns all registered converter names
 func List() []string {
-	return registry.List()
+	return defaultRegistry.List()
 }
 
-// ListConverters returns all registered converters
+// ListConverters returns all registered converters sorted by name
 func ListConverters() []Converter {
-	return registry.ListConverters()
+	return defaultRegistry.ListConverters()
+}
+
+// Route returns the converter that turns files with extension in into files
+// with extension out
+func Route(in, out string) (Converter, error) {
+	return defaultRegistry.Route(in, out)
 }
 
-// Register adds a converter to this registry
+// Register adds a converter to this registry. The instance is shared by
+// everyone using the registry; New cannot create others (see RegisterFactory).
 func (r *Registry) Register(conv Converter) error {
+	return r.register(conv, nil)
+}
+
+// RegisterFactory adds the converter created by factory to this registry.
+// New creates further instances with factory.
+func (r *Registry) RegisterFactory(factory Factory) error {
+	return r.register(factory(), factory)
+}
+
+// register adds conv, and its factory if any, to this registry
+func (r *Registry) register(conv Converter, factory Factory) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -53,9 +103,46 @@ func (r *Registry) Register(conv Converter) error {
 	}
 
 	r.converters[name] = conv
+	if factory != nil {
+		r.factories[name] = factory
+	}
+
+	// Index the converter by every (input, output) extension pair it handles
+	out := NormalizeExt(conv.OutputExtension())
+	for _, in := range conv.SupportedInputs() {
+		key := route{in: NormalizeExt(in), out: out}
+		r.routes[key] = append(r.routes[key], name)
+		sort.Strings(r.routes[key])
+	}
 	return nil
 }
 
+// Route returns the converter that turns files with extension in into files
+// with extension out in a single step. If several converters share a route,
+// the cheapest (see Coster) is used, then the first by name.
+func (r *Registry) Route(in, out string) (Converter, error) {
+	in, out = NormalizeExt(in), NormalizeExt(out)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conv := r.cheapest(route{in: in, out: out})
+	if conv == nil {
+		return nil, fmt.Errorf("%w from %s to %s", ErrNoRoute, in, out)
+	}
+	return conv, nil
+}
+
+// NormalizeExt lowercases an extension and adds the leading dot if missing,
+// e.g., "WEBM" -> ".webm"
+func NormalizeExt(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
 // Get retrieves a converter by name
 func (r *Registry) Get(name string) (Converter, error) {
 	r.mu.RLock()
@@ -69,6 +156,24 @@ func (r *Registry) Get(name string) (Converter, error) {
 	return conv, nil
 }
 
+// New creates a new instance of the converter named name, which the caller
+// can configure without affecting other users of the registry. Converters
+// added with Register rather than RegisterFactory cannot be instantiated.
+func (r *Registry) New(name string) (Converter, error) {
+	r.mu.RLock()
+	_, exists := r.converters[name]
+	factory := r.factories[name]
+	r.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("converter %q not found", name)
+	}
+	if factory == nil {
+		return nil, fmt.Errorf("converter %q was registered without a factory", name)
+	}
+	return factory(), nil
+}
+
 // List returns all registered converter names sorted alphabetically
 func (r *Registry) List() []string {
 	r.mu.RLock()
@@ -82,7 +187,7 @@ func (r *Registry) List() []string {
 	return names
 }
 
-// ListConverters returns all registered converters
+// ListConverters returns all registered converters sorted by name
 func (r *Registry) ListConverters() []Converter {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -91,5 +196,6 @@ func (r *Registry) ListConverters() []Converter {
 	for _, conv := range r.converters {
 		convs = append(convs, conv)
 	}
+	sort.Slice(convs, func(i, j int) bool { return convs[i].Name() < convs[j].Name() })
 	return convs
 }
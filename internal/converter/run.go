@@ -0,0 +1,166 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/fsutil"
+	"github.com/onedusk/sb/internal/ui"
+)
+
+// Task holds the converter-specific steps of a job run by RunJob. Only
+// Encode is required.
+type Task struct {
+	// Validate checks the input before anything else
+	Validate func(input string) error
+
+	// Outputs lists the files the job writes, the job output by default. It
+	// is called before Prepare for the skip check, which skips the job only
+	// if every output exists, and after it for the dry run and output size.
+	Outputs func() []string
+
+	// Prepare runs before the dry run, e.g., to probe the input, so that its
+	// errors surface in previews too
+	Prepare func() error
+
+	// Preview prints what a dry run would do, one line per output by default
+	Preview func()
+
+	// Encode writes the output to partial and returns the result of the
+	// last ffmpeg run, whose stderr is printed in verbose mode on failure
+	Encode func(partial string) (*executor.FFmpegResult, error)
+
+	// Commit moves partial into place, with fsutil.Commit by default;
+	// Discard removes it after a failure, with fsutil.Discard by default
+	Commit  func(partial string) error
+	Discard func(partial string)
+
+	// Finish records converter-specific details of a successful job
+	Finish func(result *Result)
+}
+
+// RunJob runs job through the steps every converter shares: validation, the
+// skip check, the dry run and writing to a partial file that is committed
+// on success and discarded on failure or interruption. task supplies the
+// steps specific to the converter.
+func RunJob(ctx context.Context, job Job, task Task) (*Result, error) {
+	input, output, opts := job.Input, job.Output, job.Options
+	result := &Result{Input: input, Output: output}
+	start := time.Now()
+
+	fail := func(err error) (*Result, error) {
+		result.Error = err
+		result.Duration = time.Since(start)
+		return result, err
+	}
+	outputs := func() []string {
+		if task.Outputs != nil {
+			return task.Outputs()
+		}
+		return []string{output}
+	}
+
+	if task.Validate != nil {
+		if err := task.Validate(input); err != nil {
+			return fail(err)
+		}
+	}
+
+	if opts.SkipExisting && allExist(outputs()) {
+		result.Skipped = true
+		result.SkipReason = "file already exists"
+		result.Duration = time.Since(start)
+		ui.PrintVerbose(opts.Verbose, "Skipping %s (already exists)", input)
+		return result, nil
+	}
+
+	if info, err := os.Stat(input); err == nil {
+		result.InputSize = info.Size()
+	}
+
+	if task.Prepare != nil {
+		if err := task.Prepare(); err != nil {
+			return fail(err)
+		}
+	}
+
+	if opts.DryRun {
+		if task.Preview != nil {
+			task.Preview()
+		} else {
+			for _, out := range outputs() {
+				fmt.Printf("[DRY-RUN] Would convert: %s -> %s\n", input, out)
+			}
+		}
+		result.Success = true
+		result.Duration = time.Since(start)
+		return result, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return fail(fmt.Errorf("failed to create output directory: %w", err))
+	}
+
+	ui.PrintVerbose(opts.Verbose, "Converting: %s -> %s", input, output)
+
+	partial := fsutil.PartialPath(output)
+	ffResult, err := task.Encode(partial)
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	if err == nil {
+		if task.Commit != nil {
+			err = task.Commit(partial)
+		} else {
+			err = fsutil.Commit(partial, output)
+		}
+	}
+	result.Duration = time.Since(start)
+
+	if err != nil {
+		if task.Discard != nil {
+			task.Discard(partial)
+		} else {
+			fsutil.Discard(partial)
+		}
+		if ctx.Err() != nil {
+			result.Interrupted = true
+			result.Error = fmt.Errorf("conversion interrupted: %w", ctx.Err())
+			return result, result.Error
+		}
+		result.Error = fmt.Errorf("conversion failed: %w", err)
+		ui.PrintVerbose(opts.Verbose, "Error: %v", err)
+		if ffResult != nil && ffResult.Stderr != "" {
+			ui.PrintVerbose(opts.Verbose, "FFmpeg stderr: %s", ffResult.Stderr)
+		}
+		return result, result.Error
+	}
+
+	for _, out := range outputs() {
+		if info, err := os.Stat(out); err == nil {
+			result.OutputSize += info.Size()
+		}
+	}
+	if task.Finish != nil {
+		task.Finish(result)
+	}
+
+	result.Success = true
+	ui.PrintVerbose(opts.Verbose, "Successfully converted %s in %s", input, result.Duration.Round(time.Millisecond))
+
+	return result, nil
+}
+
+// allExist reports whether every path exists
+func allExist(paths []string) bool {
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			return false
+		}
+	}
+	return len(paths) > 0
+}
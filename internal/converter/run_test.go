@@ -0,0 +1,116 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/fsutil"
+)
+
+func TestRunJob(t *testing.T) {
+	write := func(partial string) (*executor.FFmpegResult, error) {
+		return nil, os.WriteFile(partial, []byte("output"), 0644)
+	}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		existing []string // outputs present before the run, by name
+		extra    bool     // the task also lists "extra.out" as an output
+		validate error
+		encode   func(partial string) (*executor.FFmpegResult, error)
+		cancel   bool
+
+		wantErr         bool
+		wantEncoded     bool
+		wantSkipped     bool
+		wantInterrupted bool
+		wantOutput      bool
+	}{
+		{name: "success", encode: write,
+			wantEncoded: true, wantOutput: true},
+		{name: "failure discards the partial",
+			encode: func(partial string) (*executor.FFmpegResult, error) {
+				write(partial)
+				return &executor.FFmpegResult{Stderr: "boom"}, errors.New("exit status 1")
+			},
+			wantErr: true, wantEncoded: true},
+		{name: "interrupted", encode: write, cancel: true,
+			wantErr: true, wantEncoded: true, wantInterrupted: true},
+		{name: "invalid input", encode: write, validate: errors.New("unsupported"),
+			wantErr: true},
+		{name: "dry run", encode: write, opts: Options{DryRun: true}},
+		{name: "existing output is skipped", encode: write,
+			opts: Options{SkipExisting: true}, existing: []string{"out.bin"},
+			wantSkipped: true, wantOutput: true},
+		{name: "skip needs every output", encode: write,
+			opts: Options{SkipExisting: true}, existing: []string{"out.bin"}, extra: true,
+			wantEncoded: true, wantOutput: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := filepath.Join(dir, "in.bin")
+			output := filepath.Join(dir, "out.bin")
+			if err := os.WriteFile(input, []byte("input"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tt.existing {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("old"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			encoded, finished := false, false
+			task := Task{
+				Validate: func(string) error { return tt.validate },
+				Encode: func(partial string) (*executor.FFmpegResult, error) {
+					encoded = true
+					if tt.cancel {
+						cancel()
+					}
+					return tt.encode(partial)
+				},
+				Finish: func(*Result) { finished = true },
+			}
+			if tt.extra {
+				task.Outputs = func() []string { return []string{output, filepath.Join(dir, "extra.out")} }
+			}
+
+			result, err := RunJob(ctx, Job{Input: input, Output: output, Options: tt.opts}, task)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunJob() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if encoded != tt.wantEncoded {
+				t.Errorf("encoded = %v, want %v", encoded, tt.wantEncoded)
+			}
+			if result.Skipped != tt.wantSkipped || result.Interrupted != tt.wantInterrupted {
+				t.Errorf("Skipped, Interrupted = %v, %v, want %v, %v", result.Skipped, result.Interrupted, tt.wantSkipped, tt.wantInterrupted)
+			}
+			if result.Success != (err == nil && !result.Skipped) {
+				t.Errorf("Success = %v with error %v", result.Success, err)
+			}
+			if finished != (tt.wantEncoded && !tt.wantErr) {
+				t.Errorf("Finish called = %v", finished)
+			}
+
+			if _, err := os.Stat(output); (err == nil) != tt.wantOutput {
+				t.Errorf("output exists = %v, want %v", err == nil, tt.wantOutput)
+			}
+			if _, err := os.Stat(fsutil.PartialPath(output)); err == nil {
+				t.Error("partial output left behind")
+			}
+			if tt.wantEncoded && !tt.wantErr && result.OutputSize != int64(len("output")) {
+				t.Errorf("OutputSize = %d, want %d", result.OutputSize, len("output"))
+			}
+		})
+	}
+}
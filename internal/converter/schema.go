@@ -0,0 +1,218 @@
+package converter
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OptionType is the value type of a converter option
+type OptionType string
+
+const (
+	OptionString   OptionType = "string"
+	OptionInt      OptionType = "int"
+	OptionBool     OptionType = "bool"
+	OptionFloat    OptionType = "float"
+	OptionDuration OptionType = "duration"
+)
+
+// OptionSpec declares one converter option. The CLI derives a flag, a config
+// key below the converter's section and an SB_<NAME>_<KEY> environment
+// variable from it.
+type OptionSpec struct {
+	Name      string     // flag name, e.g., "quality"
+	Short     string     // optional one-letter flag shorthand
+	Type      OptionType // value type
+	Default   any        // default value of Type (nil = zero value)
+	Allowed   []string   // permitted values for string options (empty = any)
+	Help      string     // one-line description
+	ConfigKey string     // key below the converter section, e.g., "hardware.type" (default: Name)
+	EnabledBy string     // boolean config key gating the config value, e.g., "hardware.enabled"
+}
+
+// Key returns the config key of the option below the converter's section
+func (s OptionSpec) Key() string {
+	if s.ConfigKey != "" {
+		return s.ConfigKey
+	}
+	return strings.ReplaceAll(s.Name, "-", "_")
+}
+
+// DefaultValue returns the default converted to the option's type
+func (s OptionSpec) DefaultValue() any {
+	if s.Default != nil {
+		return s.Default
+	}
+	switch s.Type {
+	case OptionInt:
+		return 0
+	case OptionBool:
+		return false
+	case OptionFloat:
+		return 0.0
+	case OptionDuration:
+		return time.Duration(0)
+	default:
+		return ""
+	}
+}
+
+// Check verifies that v has the option's type and is an allowed value
+func (s OptionSpec) Check(v any) error {
+	ok := false
+	switch s.Type {
+	case OptionInt:
+		_, ok = v.(int)
+	case OptionBool:
+		_, ok = v.(bool)
+	case OptionFloat:
+		_, ok = v.(float64)
+	case OptionDuration:
+		_, ok = v.(time.Duration)
+	default:
+		var str string
+		if str, ok = v.(string); ok && str != "" && len(s.Allowed) > 0 && !slices.Contains(s.Allowed, str) {
+			return fmt.Errorf("invalid value %q for %s (allowed: %s)", str, s.Name, strings.Join(s.Allowed, ", "))
+		}
+	}
+	if !ok {
+		return fmt.Errorf("invalid value %v for %s: expected %s", v, s.Name, s.Type)
+	}
+	return nil
+}
+
+// CoerceValue converts a decoded config, YAML or JSON value (e.g., a float64
+// for a JSON integer or a string for a duration) to the Go type of t.
+// A nil value stays nil.
+func CoerceValue(t OptionType, v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch t {
+	case OptionInt:
+		switch n := v.(type) {
+		case int:
+			return n, nil
+		case int64:
+			return int(n), nil
+		case float64:
+			if n == float64(int(n)) {
+				return int(n), nil
+			}
+		case string:
+			if i, err := strconv.Atoi(n); err == nil {
+				return i, nil
+			}
+		}
+	case OptionFloat:
+		switch n := v.(type) {
+		case float64:
+			return n, nil
+		case int:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		case string:
+			if f, err := strconv.ParseFloat(n, 64); err == nil {
+				return f, nil
+			}
+		}
+	case OptionBool:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			if parsed, err := strconv.ParseBool(b); err == nil {
+				return parsed, nil
+			}
+		}
+	case OptionDuration:
+		switch d := v.(type) {
+		case time.Duration:
+			return d, nil
+		case string:
+			if parsed, err := time.ParseDuration(d); err == nil {
+				return parsed, nil
+			}
+		}
+	case OptionString:
+		switch str := v.(type) {
+		case string:
+			return str, nil
+		case int, int64, float64, bool:
+			return fmt.Sprint(str), nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown option type %q", t)
+	}
+	return nil, fmt.Errorf("%v is not a valid %s", v, t)
+}
+
+// Configurable is implemented by converters that declare an option schema
+type Configurable interface {
+	Base
+
+	// OptionSchema describes the converter's options
+	OptionSchema() []OptionSpec
+
+	// Configure applies option values resolved against OptionSchema
+	Configure(values OptionValues) error
+}
+
+// OptionValues holds option values keyed by option name
+type OptionValues map[string]any
+
+// ResolveOptions builds the values for schema. lookup returns an explicitly
+// set value for an option, if any; all other options get their defaults.
+// Every value is checked against its spec.
+func ResolveOptions(schema []OptionSpec, lookup func(OptionSpec) (any, bool)) (OptionValues, error) {
+	values := make(OptionValues, len(schema))
+	for _, spec := range schema {
+		v, ok := any(nil), false
+		if lookup != nil {
+			v, ok = lookup(spec)
+		}
+		if !ok {
+			v = spec.DefaultValue()
+		}
+		if err := spec.Check(v); err != nil {
+			return nil, err
+		}
+		values[spec.Name] = v
+	}
+	return values, nil
+}
+
+// String returns a string option ("" if unset)
+func (v OptionValues) String(name string) string {
+	s, _ := v[name].(string)
+	return s
+}
+
+// Int returns an int option (0 if unset)
+func (v OptionValues) Int(name string) int {
+	i, _ := v[name].(int)
+	return i
+}
+
+// Bool returns a bool option (false if unset)
+func (v OptionValues) Bool(name string) bool {
+	b, _ := v[name].(bool)
+	return b
+}
+
+// Float returns a float option (0 if unset)
+func (v OptionValues) Float(name string) float64 {
+	f, _ := v[name].(float64)
+	return f
+}
+
+// Duration returns a duration option (0 if unset)
+func (v OptionValues) Duration(name string) time.Duration {
+	d, _ := v[name].(time.Duration)
+	return d
+}
@@ -9,7 +9,7 @@ import (
 type Options struct {
 	// Input/Output
 	Input     string
-	Output    string
+	Output    string // explicit output path for a single job (overrides OutputDir)
 	OutputDir string
 
 	// Processing
@@ -21,7 +21,11 @@ type Options struct {
 
 	// Progress
 	ShowProgress bool
-	Context      context.Context
+	ShowSummary  bool            // print batch statistics when RunBatch finishes
+	Context      context.Context // job context handed to legacy converters by Adapt
+	Abort        context.Context // cancelled to kill running jobs (second interrupt); nil = batch context
+	OnProgress   func(Progress)  // called with per-file encode progress (optional)
+	OnEvent      EventHandler    // receives batch lifecycle events (optional)
 }
 
 // Result represents the outcome of a conversion
@@ -35,16 +39,39 @@ type Result struct {
 	OutputSize  int64
 	Skipped     bool
 	SkipReason  string
+	Interrupted bool // conversion was aborted while running
+
+	// Loudness is set by converters that normalize audio loudness
+	Loudness *Loudness
+}
+
+// Loudness records the EBU R128 loudness of a normalized file
+type Loudness struct {
+	Measured LoudnessLevels // of the input
+	Achieved LoudnessLevels // of the output
+
+	// NormalizationType is "linear" (a single gain) or "dynamic" (compressed
+	// because a linear gain would have exceeded the true peak target)
+	NormalizationType string
+}
+
+// LoudnessLevels contains EBU R128 loudness measurements
+type LoudnessLevels struct {
+	I   float64 // integrated loudness in LUFS
+	TP  float64 // true peak in dBTP
+	LRA float64 // loudness range in LU
 }
 
 // Stats tracks conversion statistics
 type Stats struct {
-	Total     int
-	Success   int
-	Failed    int
-	Skipped   int
-	StartTime time.Time
-	EndTime   time.Time
+	Total       int
+	Success     int
+	Failed      int
+	Skipped     int
+	Interrupted int // aborted while running
+	NotStarted  int // never started because the batch was interrupted
+	StartTime   time.Time
+	EndTime     time.Time
 }
 
 // Progress represents ongoing conversion progress
@@ -53,4 +80,25 @@ type Progress struct {
 	Total       int
 	CurrentFile string
 	Percentage  float64
+
+	// Per-file encode progress
+	Elapsed  time.Duration // media time encoded so far
+	Duration time.Duration // total media duration (0 if unknown)
+	FPS      float64
+	Speed    float64 // relative to realtime
+	Bitrate  string
+	Size     int64
+	ETA      time.Duration
+}
+
+// JobContext returns the context running jobs should use. Only legacy
+// converters need it; Converter implementations receive ctx directly.
+func (o Options) JobContext() context.Context {
+	if o.Abort != nil {
+		return o.Abort
+	}
+	if o.Context != nil {
+		return o.Context
+	}
+	return context.Background()
 }
@@ -0,0 +1,168 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/fsutil"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/ui"
+)
+
+// Converter runs a declared ffmpeg recipe
+type Converter struct {
+	recipe *recipe
+
+	mu     sync.Mutex
+	ffmpeg executor.Lazy
+	values converter.OptionValues
+}
+
+// New compiles a definition into a converter
+func New(def Definition) (*Converter, error) {
+	r, err := def.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	def = r.def
+	for i, ext := range def.Inputs {
+		def.Inputs[i] = converter.NormalizeExt(ext)
+	}
+	def.Output = converter.NormalizeExt(def.Output)
+	r.def = def
+
+	c := &Converter{recipe: r}
+	c.ffmpeg.SetToolchain(def.Toolchain)
+	return c, nil
+}
+
+// copy returns a new, unconfigured converter for the same recipe
+func (c *Converter) copy() converter.Converter {
+	dup := &Converter{recipe: c.recipe}
+	dup.ffmpeg.SetToolchain(c.recipe.def.Toolchain)
+	return dup
+}
+
+// Name returns the declared name
+func (c *Converter) Name() string {
+	return c.recipe.def.Name
+}
+
+// Description returns the declared description
+func (c *Converter) Description() string {
+	if c.recipe.def.Description == "" {
+		return fmt.Sprintf("Convert to %s (declared converter)", c.recipe.def.Output)
+	}
+	return c.recipe.def.Description
+}
+
+// SupportedInputs returns the declared input extensions
+func (c *Converter) SupportedInputs() []string {
+	return c.recipe.def.Inputs
+}
+
+// OutputExtension returns the declared output extension
+func (c *Converter) OutputExtension() string {
+	return c.recipe.def.Output
+}
+
+// Cost returns the declared cost hint
+func (c *Converter) Cost() int {
+	return c.recipe.def.Cost
+}
+
+// Source returns the file the converter was declared in
+func (c *Converter) Source() string {
+	return c.recipe.def.Source
+}
+
+// OptionSchema returns the declared parameters
+func (c *Converter) OptionSchema() []converter.OptionSpec {
+	return c.recipe.schema
+}
+
+// Configure sets the parameter values used to render the recipe
+func (c *Converter) Configure(values converter.OptionValues) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values = values
+	return nil
+}
+
+// SetExecutor overrides the executor used for conversions
+func (c *Converter) SetExecutor(e executor.Executor) {
+	c.ffmpeg.Set(e)
+}
+
+// Validate checks if the input file is valid
+func (c *Converter) Validate(input string) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return fmt.Errorf("cannot access file: %w", err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("input is a directory, not a file")
+	}
+
+	return media.CheckInput(input, c.SupportedInputs())
+}
+
+// Preflight verifies that ffmpeg has the components the recipe requires
+func (c *Converter) Preflight() error {
+	ff, err := c.ffmpeg.Get()
+	if err != nil {
+		return err
+	}
+	return ff.Preflight(c.recipe.requirements())
+}
+
+// Convert renders the recipe for a job and runs ffmpeg
+func (c *Converter) Convert(ctx context.Context, job converter.Job) (*converter.Result, error) {
+	input, output, opts := job.Input, job.Output, job.Options
+
+	c.mu.Lock()
+	values := c.values
+	c.mu.Unlock()
+
+	var (
+		ff   executor.Executor
+		args []string
+	)
+	return converter.RunJob(ctx, job, converter.Task{
+		Validate: c.Validate,
+		// Render before the dry run so template errors surface in previews too
+		Prepare: func() error {
+			var err error
+			if ff, err = c.ffmpeg.Get(); err != nil {
+				return err
+			}
+			if args, err = c.recipe.build(input, fsutil.PartialPath(output), values); err != nil {
+				return fmt.Errorf("invalid recipe: %w", err)
+			}
+			return nil
+		},
+		Encode: func(string) (*executor.FFmpegResult, error) {
+			runOpts := executor.RunOptions{Verbose: opts.Verbose}
+
+			// Wire per-file progress reporting
+			if opts.OnProgress != nil {
+				duration, err := ff.GetDuration(ctx, input)
+				if err != nil {
+					ui.PrintVerbose(opts.Verbose, "Unable to probe duration of %s: %v", input, err)
+				}
+				runOpts.Duration = duration
+				runOpts.Progress = func(p executor.ProgressInfo) {
+					opts.OnProgress(converter.ProgressFrom(p, input, 0, 1))
+				}
+			}
+
+			return ff.Run(ctx, args, runOpts)
+		},
+	})
+}
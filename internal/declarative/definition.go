@@ -0,0 +1,230 @@
+// Package declarative builds converters from ffmpeg argument recipes declared
+// in the config file or in standalone YAML files.
+package declarative
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+)
+
+// Definition declares a converter. Args are text/template strings rendered
+// with the parameter values (by name) plus .Input and .Output; arguments that
+// render empty are dropped, so {{if .bitrate}}-b:v{{end}} is optional.
+//
+// The ffmpeg command line is:
+//
+//	ffmpeg -y <input_args> -i <input> <args> -f <format> <output>
+type Definition struct {
+	Name        string   `yaml:"name" mapstructure:"name"`
+	Description string   `yaml:"description" mapstructure:"description"`
+	Inputs      []string `yaml:"inputs" mapstructure:"inputs"`         // input extensions
+	Output      string   `yaml:"output" mapstructure:"output"`         // output extension
+	Format      string   `yaml:"format" mapstructure:"format"`         // ffmpeg muxer (default: derived from output)
+	Cost        int      `yaml:"cost" mapstructure:"cost"`             // planner cost hint (0 = default)
+	Toolchain   string   `yaml:"toolchain" mapstructure:"toolchain"`   // named toolchain (empty = default)
+	Params      []Param  `yaml:"params" mapstructure:"params"`         // typed parameters, exposed as options
+	InputArgs   []string `yaml:"input_args" mapstructure:"input_args"` // templates placed before -i
+	Args        []string `yaml:"args" mapstructure:"args"`             // templates placed between input and output
+	Requires    Requires `yaml:"requires" mapstructure:"requires"`     // checked before a batch starts
+
+	// Source records where the definition was read from, for error messages
+	Source string `yaml:"-" mapstructure:"-"`
+}
+
+// Param declares a typed recipe parameter
+type Param struct {
+	Name    string   `yaml:"name" mapstructure:"name"`
+	Short   string   `yaml:"short" mapstructure:"short"`
+	Type    string   `yaml:"type" mapstructure:"type"` // string, int, bool, float, duration (default: string)
+	Default any      `yaml:"default" mapstructure:"default"`
+	Allowed []string `yaml:"allowed" mapstructure:"allowed"`
+	Help    string   `yaml:"help" mapstructure:"help"`
+}
+
+// Requires lists ffmpeg components a recipe depends on
+type Requires struct {
+	Encoders []string `yaml:"encoders" mapstructure:"encoders"`
+	Decoders []string `yaml:"decoders" mapstructure:"decoders"`
+	Filters  []string `yaml:"filters" mapstructure:"filters"`
+}
+
+// reserved template names that parameters cannot use
+var reserved = map[string]bool{"Input": true, "Output": true}
+
+// compile validates d and parses its templates
+func (d Definition) compile() (*recipe, error) {
+	if d.Name == "" {
+		return nil, fmt.Errorf("converter has no name")
+	}
+	if len(d.Inputs) == 0 {
+		return nil, fmt.Errorf("converter %q has no inputs", d.Name)
+	}
+	if d.Output == "" {
+		return nil, fmt.Errorf("converter %q has no output extension", d.Name)
+	}
+	if len(d.Args) == 0 {
+		return nil, fmt.Errorf("converter %q has no args", d.Name)
+	}
+
+	r := &recipe{def: d}
+
+	for _, p := range d.Params {
+		if p.Name == "" || reserved[p.Name] {
+			return nil, fmt.Errorf("converter %q: invalid parameter name %q", d.Name, p.Name)
+		}
+
+		spec := converter.OptionSpec{
+			Name:    p.Name,
+			Short:   p.Short,
+			Type:    converter.OptionType(p.Type),
+			Allowed: p.Allowed,
+			Help:    p.Help,
+		}
+		if spec.Type == "" {
+			spec.Type = converter.OptionString
+		}
+
+		def, err := converter.CoerceValue(spec.Type, p.Default)
+		if err != nil {
+			return nil, fmt.Errorf("converter %q parameter %s: %w", d.Name, p.Name, err)
+		}
+		spec.Default = def
+		if err := spec.Check(spec.DefaultValue()); err != nil {
+			return nil, fmt.Errorf("converter %q: %w", d.Name, err)
+		}
+
+		r.schema = append(r.schema, spec)
+	}
+
+	var err error
+	if r.inputArgs, err = parseArgs(d.Name, d.InputArgs); err != nil {
+		return nil, err
+	}
+	if r.args, err = parseArgs(d.Name, d.Args); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// recipe is a compiled definition
+type recipe struct {
+	def       Definition
+	schema    []converter.OptionSpec
+	inputArgs []*template.Template
+	args      []*template.Template
+}
+
+// format returns the ffmpeg muxer for the output
+func (r *recipe) format() string {
+	if r.def.Format != "" {
+		return r.def.Format
+	}
+	return executor.MuxerFor(r.def.Output)
+}
+
+// imageEncoder returns the encoder added for image2 outputs whose recipe
+// names none ("" for other formats)
+func (r *recipe) imageEncoder() string {
+	if r.format() != "image2" {
+		return ""
+	}
+	return executor.ImageEncoderFor(r.def.Output)
+}
+
+// requirements returns the ffmpeg components the recipe depends on
+func (r *recipe) requirements() executor.Requirements {
+	encoders := r.def.Requires.Encoders
+	if enc := r.imageEncoder(); enc != "" {
+		encoders = append(append([]string{}, encoders...), enc)
+	}
+	return executor.Requirements{
+		Encoders: encoders,
+		Decoders: r.def.Requires.Decoders,
+		Filters:  r.def.Requires.Filters,
+		Muxers:   []string{r.format()},
+	}
+}
+
+// build renders the full ffmpeg argument list
+func (r *recipe) build(input, output string, values converter.OptionValues) ([]string, error) {
+	data := make(map[string]any, len(values)+2)
+	for _, spec := range r.schema {
+		data[spec.Name] = values[spec.Name]
+		if data[spec.Name] == nil {
+			data[spec.Name] = spec.DefaultValue()
+		}
+	}
+	data["Input"] = input
+	data["Output"] = output
+
+	args := []string{"-y"}
+
+	pre, err := render(r.inputArgs, data)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, pre...)
+	args = append(args, "-i", input)
+
+	main, err := render(r.args, data)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, main...)
+
+	// Partial paths hide the extension image2 would pick the codec from
+	if enc := r.imageEncoder(); enc != "" && !hasVideoCodec(main) {
+		args = append(args, "-c:v", enc)
+	}
+
+	if format := r.format(); format != "" {
+		args = append(args, "-f", format)
+	}
+	return append(args, output), nil
+}
+
+// hasVideoCodec reports whether args select a video codec
+func hasVideoCodec(args []string) bool {
+	for _, arg := range args {
+		switch {
+		case arg == "-c", arg == "-codec", arg == "-vcodec",
+			strings.HasPrefix(arg, "-c:v"), strings.HasPrefix(arg, "-codec:v"):
+			return true
+		}
+	}
+	return false
+}
+
+// parseArgs parses argument templates
+func parseArgs(name string, args []string) ([]*template.Template, error) {
+	tmpls := make([]*template.Template, 0, len(args))
+	for i, arg := range args {
+		t, err := template.New(fmt.Sprintf("%s[%d]", name, i)).Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("converter %q: %w", name, err)
+		}
+		tmpls = append(tmpls, t)
+	}
+	return tmpls, nil
+}
+
+// render executes argument templates, dropping empty results
+func render(tmpls []*template.Template, data map[string]any) ([]string, error) {
+	out := make([]string, 0, len(tmpls))
+	for _, t := range tmpls {
+		var b bytes.Buffer
+		if err := t.Execute(&b, data); err != nil {
+			return nil, err
+		}
+		if arg := strings.TrimSpace(b.String()); arg != "" {
+			out = append(out, arg)
+		}
+	}
+	return out, nil
+}
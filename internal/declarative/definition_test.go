@@ -0,0 +1,68 @@
+package declarative
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name string
+		def  Definition
+		want string
+	}{
+		{
+			name: "muxer from the output extension",
+			def:  Definition{Name: "mkv", Inputs: []string{".mp4"}, Output: ".mkv", Args: []string{"-c", "copy"}},
+			want: "-y -i in.mp4 -c copy -f matroska out.part",
+		},
+		{
+			name: "image2 output gets its encoder",
+			def:  Definition{Name: "thumb", Inputs: []string{".mp4"}, Output: ".png", Args: []string{"-frames:v", "1"}},
+			want: "-y -i in.mp4 -frames:v 1 -c:v png -f image2 out.part",
+		},
+		{
+			name: "tiff encoder",
+			def:  Definition{Name: "tiff", Inputs: []string{".mp4"}, Output: ".TIF", Args: []string{"-frames:v", "1"}},
+			want: "-y -i in.mp4 -frames:v 1 -c:v tiff -f image2 out.part",
+		},
+		{
+			name: "recipe codec is kept",
+			def:  Definition{Name: "jpg", Inputs: []string{".mp4"}, Output: ".jpg", Args: []string{"-vcodec", "mjpeg", "-q:v", "2"}},
+			want: "-y -i in.mp4 -vcodec mjpeg -q:v 2 -f image2 out.part",
+		},
+		{
+			name: "explicit format other than image2",
+			def:  Definition{Name: "pipe", Inputs: []string{".mp4"}, Output: ".png", Format: "apng", Args: []string{"-plays", "0"}},
+			want: "-y -i in.mp4 -plays 0 -f apng out.part",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := tt.def.compile()
+			if err != nil {
+				t.Fatalf("compile() error = %v", err)
+			}
+			args, err := r.build("in.mp4", "out.part", nil)
+			if err != nil {
+				t.Fatalf("build() error = %v", err)
+			}
+			if got := strings.Join(args, " "); got != tt.want {
+				t.Errorf("build() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirementsImageEncoder(t *testing.T) {
+	def := Definition{Name: "thumb", Inputs: []string{".mp4"}, Output: ".png", Args: []string{"-frames:v", "1"}}
+	r, err := def.compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := r.requirements()
+	if strings.Join(req.Encoders, ",") != "png" || strings.Join(req.Muxers, ",") != "image2" {
+		t.Errorf("requirements() = %+v, want the png encoder and image2 muxer", req)
+	}
+}
@@ -0,0 +1,117 @@
+package declarative
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/onedusk/sb/internal/config"
+	"github.com/onedusk/sb/internal/converter"
+	"go.yaml.in/yaml/v3"
+)
+
+// ConfigKey is the config section holding declared converters
+const ConfigKey = "converters"
+
+// DefaultDir returns the directory of standalone definitions, ~/.sb/converters.d
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".sb", "converters.d")
+}
+
+// FromConfig returns the definitions in the "converters" config section
+func FromConfig() ([]Definition, error) {
+	var defs []Definition
+	if err := config.UnmarshalKey(ConfigKey, &defs); err != nil {
+		return nil, fmt.Errorf("invalid %s config: %w", ConfigKey, err)
+	}
+	for i := range defs {
+		defs[i].Source = "config"
+	}
+	return defs, nil
+}
+
+// ReadDir returns the definitions in the *.yaml and *.yml files of dir, one
+// definition per file. A missing directory yields no definitions.
+func ReadDir(dir string) ([]Definition, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	defs := []Definition{}
+	errs := []error{}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		var def Definition
+		if err := yaml.Unmarshal(data, &def); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		def.Source = path
+		defs = append(defs, def)
+	}
+
+	return defs, errors.Join(errs...)
+}
+
+// LoadAll registers the converters declared in config and in dirs with r.
+// Invalid definitions and name clashes are reported in the returned error
+// and skipped; the others are still registered.
+func LoadAll(r *converter.Registry, dirs ...string) ([]*Converter, error) {
+	errs := []error{}
+
+	defs, err := FromConfig()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		found, err := ReadDir(dir)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		defs = append(defs, found...)
+	}
+
+	loaded := []*Converter{}
+	for _, def := range defs {
+		conv, err := New(def)
+		if err == nil {
+			err = r.RegisterFactory(conv.copy)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", def.Source, err))
+			continue
+		}
+		loaded = append(loaded, conv)
+	}
+
+	return loaded, errors.Join(errs...)
+}
@@ -0,0 +1,553 @@
+package executor
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Capabilities describes the components compiled into an ffmpeg binary
+type Capabilities struct {
+	BinaryPath string          `json:"binary_path"`
+	Version    string          `json:"version"`
+	Encoders   map[string]bool `json:"encoders"`
+	Decoders   map[string]bool `json:"decoders"`
+	HWAccels   map[string]bool `json:"hwaccels"`
+	Filters    map[string]bool `json:"filters"`
+	Muxers     map[string]bool `json:"muxers"`
+}
+
+// Requirements lists ffmpeg components needed by a conversion
+type Requirements struct {
+	Encoders []string
+	Decoders []string
+	HWAccels []string
+	Filters  []string
+	Muxers   []string
+
+	// MinVersion is the oldest ffmpeg release that works, e.g., "7.1"
+	// (empty = any). Builds from git snapshots are assumed to be recent.
+	MinVersion string
+}
+
+// Merge combines two requirement sets
+func (r Requirements) Merge(other Requirements) Requirements {
+	minVersion := r.MinVersion
+	if compareVersions(other.MinVersion, minVersion) > 0 {
+		minVersion = other.MinVersion
+	}
+	return Requirements{
+		Encoders:   append(append([]string{}, r.Encoders...), other.Encoders...),
+		Decoders:   append(append([]string{}, r.Decoders...), other.Decoders...),
+		HWAccels:   append(append([]string{}, r.HWAccels...), other.HWAccels...),
+		Filters:    append(append([]string{}, r.Filters...), other.Filters...),
+		Muxers:     append(append([]string{}, r.Muxers...), other.Muxers...),
+		MinVersion: minVersion,
+	}
+}
+
+// MissingComponentsError reports components absent from the local ffmpeg
+type MissingComponentsError struct {
+	BinaryPath string
+	Version    string
+	Missing    []string // e.g., "encoder libx265", "hwaccel cuda"
+}
+
+func (e *MissingComponentsError) Error() string {
+	return fmt.Sprintf("ffmpeg at %s (%s) is missing required components: %s",
+		e.BinaryPath, e.Version, strings.Join(e.Missing, ", "))
+}
+
+var (
+	capsMu       sync.Mutex
+	capsCache    = make(map[string]*Capabilities)
+	versionCache = make(map[string]cachedVersion) // by binary path
+)
+
+// HasEncoder reports whether the named encoder is available
+func (c *Capabilities) HasEncoder(name string) bool { return c.Encoders[name] }
+
+// HasDecoder reports whether the named decoder is available
+func (c *Capabilities) HasDecoder(name string) bool { return c.Decoders[name] }
+
+// HasHWAccel reports whether the named hardware acceleration method is available
+func (c *Capabilities) HasHWAccel(name string) bool { return c.HWAccels[name] }
+
+// HasFilter reports whether the named filter is available
+func (c *Capabilities) HasFilter(name string) bool { return c.Filters[name] }
+
+// HasMuxer reports whether the named muxer is available
+func (c *Capabilities) HasMuxer(name string) bool { return c.Muxers[name] }
+
+// Check verifies that every requirement is satisfied, naming all missing components
+func (c *Capabilities) Check(req Requirements) error {
+	missing := []string{}
+
+	check := func(kind string, names []string, available map[string]bool) {
+		for _, name := range names {
+			if name != "" && !available[name] {
+				missing = append(missing, kind+" "+name)
+			}
+		}
+	}
+
+	check("encoder", req.Encoders, c.Encoders)
+	check("decoder", req.Decoders, c.Decoders)
+	check("hwaccel", req.HWAccels, c.HWAccels)
+	check("filter", req.Filters, c.Filters)
+	check("muxer", req.Muxers, c.Muxers)
+
+	if req.MinVersion != "" {
+		if release, ok := ReleaseVersion(c.Version); ok && compareVersions(release, req.MinVersion) < 0 {
+			missing = append(missing, "version "+req.MinVersion+" or later")
+		}
+	}
+
+	if len(missing) > 0 {
+		return &MissingComponentsError{
+			BinaryPath: c.BinaryPath,
+			Version:    c.Version,
+			Missing:    missing,
+		}
+	}
+	return nil
+}
+
+// Capabilities discovers the encoders, decoders, hwaccels, filters and muxers
+// of this ffmpeg binary. Results are cached in memory and on disk, keyed by
+// binary path and version; the version is only queried again when the
+// binary's size or modification time changes.
+func (f *FFmpeg) Capabilities() (*Capabilities, error) {
+	capsMu.Lock()
+	defer capsMu.Unlock()
+
+	version, err := f.version()
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine ffmpeg version: %w", err)
+	}
+
+	key := f.binaryPath + "\x00" + version
+
+	if caps, ok := capsCache[key]; ok {
+		return caps, nil
+	}
+
+	if caps := loadCachedCapabilities(key); caps != nil {
+		capsCache[key] = caps
+		return caps, nil
+	}
+
+	caps := &Capabilities{
+		BinaryPath: f.binaryPath,
+		Version:    version,
+	}
+
+	queries := []struct {
+		flag   string
+		parse  func(string) map[string]bool
+		target *map[string]bool
+	}{
+		{"-encoders", parseCodecList, &caps.Encoders},
+		{"-decoders", parseCodecList, &caps.Decoders},
+		{"-hwaccels", parseHWAccels, &caps.HWAccels},
+		{"-filters", parseFilters, &caps.Filters},
+		{"-muxers", parseMuxers, &caps.Muxers},
+	}
+
+	for _, q := range queries {
+		output, err := exec.Command(f.binaryPath, "-hide_banner", q.flag).Output()
+		if err != nil {
+			return nil, fmt.Errorf("ffmpeg %s failed: %w", q.flag, err)
+		}
+		*q.target = q.parse(string(output))
+	}
+
+	capsCache[key] = caps
+	saveCachedCapabilities(key, caps)
+
+	return caps, nil
+}
+
+// Preflight verifies that this ffmpeg satisfies req before any work starts
+func (f *FFmpeg) Preflight(req Requirements) error {
+	caps, err := f.Capabilities()
+	if err != nil {
+		return err
+	}
+	return caps.Check(req)
+}
+
+// EncoderFor maps a user-facing codec name and hardware acceleration type to
+// the ffmpeg encoder that will be used
+func EncoderFor(codec, hwaccel string) string {
+	switch codec {
+	case "", "h264", "avc":
+		switch hwaccel {
+		case "videotoolbox":
+			return "h264_videotoolbox"
+		case "nvenc":
+			return "h264_nvenc"
+		case "qsv":
+			return "h264_qsv"
+		}
+		return "libx264"
+	case "h265", "hevc":
+		switch hwaccel {
+		case "videotoolbox":
+			return "hevc_videotoolbox"
+		case "nvenc":
+			return "hevc_nvenc"
+		case "qsv":
+			return "hevc_qsv"
+		}
+		return "libx265"
+	case "vp9":
+		return "libvpx-vp9"
+	}
+	return codec
+}
+
+// AudioEncoderFor maps a user-facing audio codec name to its ffmpeg encoder
+// ("" when the stream is copied)
+func AudioEncoderFor(codec string) string {
+	switch codec {
+	case "", "aac":
+		return "aac"
+	case "mp3":
+		return "libmp3lame"
+	case "opus":
+		return "libopus"
+	case "vorbis":
+		return "libvorbis"
+	case "copy":
+		return ""
+	}
+	return codec
+}
+
+// MuxerFor returns the ffmpeg muxer that writes files with extension ext,
+// e.g., ".mkv" -> "matroska"
+func MuxerFor(ext string) string {
+	ext = strings.TrimPrefix(strings.ToLower(ext), ".")
+	switch ext {
+	case "mkv":
+		return "matroska"
+	case "m4v":
+		return "mp4"
+	case "m4a":
+		return "ipod"
+	case "ts", "m2ts", "mts":
+		return "mpegts"
+	case "jpg", "jpeg", "png", "bmp", "tif", "tiff":
+		return "image2"
+	case "aac":
+		return "adts"
+	case "aif", "aiff":
+		return "aiff"
+	case "m3u8":
+		return "hls"
+	case "mpd":
+		return "dash"
+	}
+	return ext
+}
+
+// ImageEncoderFor returns the ffmpeg encoder for still images with
+// extension ext, e.g., ".png" -> "png" ("" when ext is not an image2 format).
+// The image2 muxer does not choose one by itself for paths it cannot name,
+// such as partial files, and falls back to MJPEG.
+func ImageEncoderFor(ext string) string {
+	switch strings.TrimPrefix(strings.ToLower(ext), ".") {
+	case "jpg", "jpeg":
+		return "mjpeg"
+	case "png":
+		return "png"
+	case "bmp":
+		return "bmp"
+	case "tif", "tiff":
+		return "tiff"
+	}
+	return ""
+}
+
+// HWAccelFor maps a user-facing hardware acceleration type to the ffmpeg
+// -hwaccel method used for decoding
+func HWAccelFor(hwaccel string) string {
+	switch hwaccel {
+	case "nvenc":
+		return "cuda"
+	}
+	return hwaccel
+}
+
+// ReleaseVersion extracts the release number from an "ffmpeg -version" line,
+// e.g., "ffmpeg version 7.1.1-1ubuntu1 Copyright ..." -> "7.1.1". It returns
+// false for builds from git snapshots ("N-113246-g...") and unknown formats.
+func ReleaseVersion(line string) (string, bool) {
+	_, rest, ok := strings.Cut(line, "version ")
+	if !ok {
+		return "", false
+	}
+	field := strings.TrimPrefix(strings.Fields(rest + " ")[0], "n")
+
+	end := 0
+	for end < len(field) && (field[end] >= '0' && field[end] <= '9' || field[end] == '.') {
+		end++
+	}
+	release := strings.Trim(field[:end], ".")
+	if release == "" {
+		return "", false
+	}
+	return release, true
+}
+
+// compareVersions compares dotted version numbers, returning -1, 0 or 1;
+// missing components count as zero and empty versions sort first
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseCodecList parses "ffmpeg -encoders" / "ffmpeg -decoders" output
+func parseCodecList(output string) map[string]bool {
+	result := make(map[string]bool)
+	inList := false
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "------") {
+			inList = true
+			continue
+		}
+		if !inList {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 2 {
+			result[fields[1]] = true
+		}
+	}
+
+	return result
+}
+
+// parseHWAccels parses "ffmpeg -hwaccels" output
+func parseHWAccels(output string) map[string]bool {
+	result := make(map[string]bool)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasSuffix(line, ":") {
+			continue
+		}
+		result[line] = true
+	}
+
+	return result
+}
+
+// parseFilters parses "ffmpeg -filters" output, whose entries look like
+// " TSC scale             V->V       Scale the input video size."
+func parseFilters(output string) map[string]bool {
+	result := make(map[string]bool)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 3 && strings.Contains(fields[2], "->") {
+			result[fields[1]] = true
+		}
+	}
+
+	return result
+}
+
+// parseMuxers parses "ffmpeg -muxers" output
+func parseMuxers(output string) map[string]bool {
+	result := make(map[string]bool)
+	inList := false
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "--" {
+			inList = true
+			continue
+		}
+		if !inList {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && strings.Contains(fields[0], "E") {
+			for _, name := range strings.Split(fields[1], ",") {
+				result[name] = true
+			}
+		}
+	}
+
+	return result
+}
+
+// version returns the version line of this binary, from the cache when
+// the binary is unchanged since it was last queried. capsMu must be held.
+func (f *FFmpeg) version() (string, error) {
+	stat, err := os.Stat(f.binaryPath)
+	if err != nil {
+		return "", err
+	}
+
+	if cached, ok := versionCache[f.binaryPath]; ok && cached.matches(f.binaryPath, stat) {
+		return cached.Version, nil
+	}
+	if cached, ok := loadCachedVersion(f.binaryPath); ok && cached.matches(f.binaryPath, stat) {
+		versionCache[f.binaryPath] = cached
+		return cached.Version, nil
+	}
+
+	version, err := f.CheckVersion()
+	if err != nil {
+		return "", err
+	}
+	cached := cachedVersion{Path: f.binaryPath, Size: stat.Size(), ModTime: stat.ModTime(), Version: version}
+	versionCache[f.binaryPath] = cached
+	saveCachedVersion(cached)
+	return version, nil
+}
+
+// cachedVersion is the version line of an ffmpeg binary, valid while the
+// binary keeps its size and modification time
+type cachedVersion struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Version string    `json:"version"`
+}
+
+// matches reports whether v was recorded for the binary at path as it is now
+func (v cachedVersion) matches(path string, stat os.FileInfo) bool {
+	return v.Path == path && v.Size == stat.Size() && v.ModTime.Equal(stat.ModTime())
+}
+
+// versionCachePath returns the on-disk cache location for the version of
+// the binary at path
+func versionCachePath(path string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(dir, "sb", "ffmpeg-version-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// loadCachedVersion returns the cached version of the binary at path
+func loadCachedVersion(path string) (cachedVersion, bool) {
+	cachePath, err := versionCachePath(path)
+	if err != nil {
+		return cachedVersion{}, false
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return cachedVersion{}, false
+	}
+
+	var cached cachedVersion
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Version == "" {
+		return cachedVersion{}, false
+	}
+	return cached, true
+}
+
+// saveCachedVersion stores a binary's version on disk; failures are ignored
+func saveCachedVersion(cached cachedVersion) {
+	cachePath, err := versionCachePath(cached.Path)
+	if err != nil {
+		return
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return
+	}
+	os.WriteFile(cachePath, data, 0644)
+}
+
+// capabilitiesCachePath returns the on-disk cache location for key
+func capabilitiesCachePath(key string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, "sb", "ffmpeg-capabilities-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// loadCachedCapabilities returns cached capabilities for key, or nil
+func loadCachedCapabilities(key string) *Capabilities {
+	path, err := capabilitiesCachePath(key)
+	if err != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var caps Capabilities
+	if err := json.Unmarshal(data, &caps); err != nil {
+		return nil
+	}
+	if caps.BinaryPath+"\x00"+caps.Version != key {
+		return nil
+	}
+	return &caps
+}
+
+// saveCachedCapabilities stores capabilities on disk; failures are ignored
+func saveCachedCapabilities(key string, caps *Capabilities) {
+	path, err := capabilitiesCachePath(key)
+	if err != nil {
+		return
+	}
+
+	data, err := json.Marshal(caps)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,247 @@
+package executor
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const encodersOutput = `Encoders:
+ V..... = Video
+ A..... = Audio
+ S..... = Subtitle
+ .F.... = Frame-level multithreading
+ ..S... = Slice-level multithreading
+ ...X.. = Codec is experimental
+ ....B. = Supports draw_horiz_band
+ .....D = Supports direct rendering method 1
+ ------
+ V....D libx264              libx264 H.264 / AVC / MPEG-4 AVC / MPEG-4 part 10 (codec h264)
+ V....D h264_videotoolbox    VideoToolbox H.264 Encoder (codec h264)
+ V....D png                  PNG (Portable Network Graphics) image
+ A....D aac                  AAC (Advanced Audio Coding)
+ A....D libmp3lame           libmp3lame MP3 (MPEG audio layer 3) (codec mp3)
+ S..... mov_text             3GPP Timed Text subtitle
+`
+
+const muxersOutput = `File formats:
+ D. = Demuxing supported
+ .E = Muxing supported
+ --
+  E 3g2             3GP2 (3GPP file format)
+ DE hls             Apple HTTP Live Streaming
+  E ipod            iPod H.264 MP4 (MPEG-4 Part 14)
+ DE matroska        Matroska
+  E mp4             MP4 (MPEG-4 Part 14)
+ D  mov,mp4,m4a,3gp,3g2,mj2 QuickTime / MOV
+  E webm,weba       WebM
+`
+
+const filtersOutput = `Filters:
+  T.. = Timeline support
+  .S. = Slice threading
+  ..C = Command support
+  A = Audio input/output
+  V = Video input/output
+  N = Dynamic number and/or type of input/output
+  | = Source or sink filter
+ ..C loudnorm          A->A       EBU R128 loudness normalization
+ TSC scale             V->V       Scale the input video size.
+ ... split             V->N       Pass on the input to N video outputs.
+ ... nullsrc           |->V       Null video source, return unprocessed video frames.
+`
+
+const hwaccelsOutput = `Hardware acceleration methods:
+videotoolbox
+cuda
+
+`
+
+// keys returns the sorted keys of set
+func keys(set map[string]bool) []string {
+	names := []string{}
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestParseCapabilityLists(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) map[string]bool
+		input string
+		want  []string
+	}{
+		{"encoders", parseCodecList, encodersOutput,
+			[]string{"aac", "h264_videotoolbox", "libmp3lame", "libx264", "mov_text", "png"}},
+		{"muxers", parseMuxers, muxersOutput,
+			[]string{"3g2", "hls", "ipod", "matroska", "mp4", "weba", "webm"}},
+		{"filters", parseFilters, filtersOutput,
+			[]string{"loudnorm", "nullsrc", "scale", "split"}},
+		{"hwaccels", parseHWAccels, hwaccelsOutput,
+			[]string{"cuda", "videotoolbox"}},
+		{"empty encoders", parseCodecList, "", []string{}},
+		{"encoders without separator", parseCodecList, " V....D libx264  libx264 H.264\n", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keys(tt.parse(tt.input)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMuxerFor(t *testing.T) {
+	tests := []struct {
+		ext  string
+		want string
+	}{
+		{".mkv", "matroska"},
+		{"MKV", "matroska"},
+		{".m4v", "mp4"},
+		{".m4a", "ipod"},
+		{".mts", "mpegts"},
+		{".JPG", "image2"},
+		{".tif", "image2"},
+		{".aac", "adts"},
+		{".aif", "aiff"},
+		{".m3u8", "hls"},
+		{".mpd", "dash"},
+		{".mp4", "mp4"},
+		{".webm", "webm"},
+	}
+
+	for _, tt := range tests {
+		if got := MuxerFor(tt.ext); got != tt.want {
+			t.Errorf("MuxerFor(%q) = %q, want %q", tt.ext, got, tt.want)
+		}
+	}
+}
+
+func TestCapabilitiesCheck(t *testing.T) {
+	caps := &Capabilities{
+		BinaryPath: "/usr/bin/ffmpeg",
+		Version:    "ffmpeg version 6.1.1 Copyright (c) 2000-2023",
+		Encoders:   parseCodecList(encodersOutput),
+		HWAccels:   parseHWAccels(hwaccelsOutput),
+		Filters:    parseFilters(filtersOutput),
+		Muxers:     parseMuxers(muxersOutput),
+	}
+
+	tests := []struct {
+		name    string
+		req     Requirements
+		missing []string
+	}{
+		{"satisfied",
+			Requirements{Encoders: []string{"libx264", "aac"}, Muxers: []string{"mp4"}, Filters: []string{"scale"}},
+			nil},
+		{"copied audio has no encoder",
+			Requirements{Encoders: []string{"libx264", ""}},
+			nil},
+		{"every missing component is named",
+			Requirements{
+				Encoders: []string{"libx265"},
+				HWAccels: []string{"qsv"},
+				Filters:  []string{"zscale"},
+				Muxers:   []string{"dash"},
+			},
+			[]string{"encoder libx265", "hwaccel qsv", "filter zscale", "muxer dash"}},
+		{"old release",
+			Requirements{MinVersion: "7.1"},
+			[]string{"version 7.1 or later"}},
+		{"release new enough",
+			Requirements{MinVersion: "6.1"},
+			nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := caps.Check(tt.req)
+			if tt.missing == nil {
+				if err != nil {
+					t.Fatalf("Check() = %v, want nil", err)
+				}
+				return
+			}
+
+			var mce *MissingComponentsError
+			if !errors.As(err, &mce) {
+				t.Fatalf("Check() = %v, want *MissingComponentsError", err)
+			}
+			if !reflect.DeepEqual(mce.Missing, tt.missing) {
+				t.Errorf("Missing = %v, want %v", mce.Missing, tt.missing)
+			}
+		})
+	}
+}
+
+func TestCheckSnapshotBuild(t *testing.T) {
+	caps := &Capabilities{Version: "ffmpeg version N-113246-g1c9b2c0a2b Copyright (c) 2000-2024"}
+	if err := caps.Check(Requirements{MinVersion: "7.1"}); err != nil {
+		t.Errorf("git snapshot builds are assumed to be recent, got %v", err)
+	}
+}
+
+func TestRequirementsMerge(t *testing.T) {
+	a := Requirements{Encoders: []string{"libx264"}, MinVersion: "6.0"}
+	b := Requirements{Encoders: []string{"aac"}, Filters: []string{"loudnorm"}, MinVersion: "7.1"}
+
+	got := a.Merge(b)
+	if !reflect.DeepEqual(got.Encoders, []string{"libx264", "aac"}) || !reflect.DeepEqual(got.Filters, []string{"loudnorm"}) {
+		t.Errorf("Merge() = %+v", got)
+	}
+	if got.MinVersion != "7.1" {
+		t.Errorf("MinVersion = %q, want the later 7.1", got.MinVersion)
+	}
+	if len(a.Encoders) != 1 {
+		t.Errorf("Merge() modified its receiver: %v", a.Encoders)
+	}
+}
+
+func TestReleaseVersion(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+		ok   bool
+	}{
+		{"ffmpeg version 7.1.1-1ubuntu1 Copyright (c) 2000-2025", "7.1.1", true},
+		{"ffmpeg version n6.0 Copyright (c) 2000-2023", "6.0", true},
+		{"ffmpeg version 4.4.2-0ubuntu0.22.04.1 Copyright", "4.4.2", true},
+		{"ffmpeg version N-113246-g1c9b2c0a2b Copyright", "", false},
+		{"not ffmpeg", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ReleaseVersion(tt.line)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ReleaseVersion(%q) = %q, %v, want %q, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestImageEncoderFor(t *testing.T) {
+	tests := []struct {
+		ext  string
+		want string
+	}{
+		{".png", "png"},
+		{".JPG", "mjpeg"},
+		{"jpeg", "mjpeg"},
+		{".bmp", "bmp"},
+		{".tif", "tiff"},
+		{".tiff", "tiff"},
+		{".mp4", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ImageEncoderFor(tt.ext); got != tt.want {
+			t.Errorf("ImageEncoderFor(%q) = %q, want %q", tt.ext, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+//go:build !windows
+
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVersionCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	count := filepath.Join(dir, "count")
+	path := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\necho run >>" + count + "\necho 'ffmpeg version 7.1.1 Copyright (c) 2000-2025'\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	runs := func() int {
+		data, _ := os.ReadFile(count)
+		return strings.Count(string(data), "run")
+	}
+	version := func(t *testing.T) {
+		t.Helper()
+		capsMu.Lock()
+		defer capsMu.Unlock()
+		v, err := (&FFmpeg{binaryPath: path}).version()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(v, "ffmpeg version 7.1.1") {
+			t.Errorf("version() = %q", v)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		prepare  func(t *testing.T)
+		wantRuns int
+	}{
+		{"first query runs ffmpeg", nil, 1},
+		{"from memory", nil, 1},
+		{"from disk", func(*testing.T) { delete(versionCache, path) }, 1},
+		{"changed binary", func(t *testing.T) {
+			later := time.Now().Add(time.Hour)
+			if err := os.Chtimes(path, later, later); err != nil {
+				t.Fatal(err)
+			}
+		}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.prepare != nil {
+				tt.prepare(t)
+			}
+			version(t)
+			if n := runs(); n != tt.wantRuns {
+				t.Errorf("ffmpeg ran %d times, want %d", n, tt.wantRuns)
+			}
+		})
+	}
+
+	capsMu.Lock()
+	delete(versionCache, path)
+	capsMu.Unlock()
+}
@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/onedusk/sb/internal/media"
+)
+
+// Executor runs media tools on behalf of converters. FFmpeg is the standard
+// implementation; tests and library users can substitute their own.
+type Executor interface {
+	// Convert transcodes input into output
+	Convert(ctx context.Context, input, output string, opts FFmpegOptions) (*FFmpegResult, error)
+
+	// Run executes ffmpeg with a complete argument list
+	Run(ctx context.Context, args []string, opts RunOptions) (*FFmpegResult, error)
+
+	// GetInfo probes a media file
+	GetInfo(ctx context.Context, input string) (*media.MediaInfo, error)
+
+	// GetDuration returns the duration of a media file
+	GetDuration(ctx context.Context, input string) (time.Duration, error)
+
+	// Capabilities lists the components available to this executor
+	Capabilities() (*Capabilities, error)
+
+	// Preflight verifies that all requirements are satisfied
+	Preflight(req Requirements) error
+
+	// CheckVersion returns a version string for the underlying tool
+	CheckVersion() (string, error)
+}
+
+// Ensure FFmpeg implements Executor
+var _ Executor = (*FFmpeg)(nil)
+
+// DefaultToolchain is the toolchain used when none is selected
+const DefaultToolchain = "default"
+
+// Toolchain names a pair of ffmpeg/ffprobe binaries
+type Toolchain struct {
+	Name        string
+	FFmpegPath  string // binary name or path; empty = "ffmpeg" from PATH
+	FFprobePath string // binary name or path; empty = derived from FFmpegPath
+}
+
+var (
+	toolchainsMu sync.RWMutex
+	toolchains   = make(map[string]Toolchain)
+)
+
+// RegisterToolchain adds or replaces a named toolchain
+func RegisterToolchain(tc Toolchain) {
+	if tc.Name == "" {
+		tc.Name = DefaultToolchain
+	}
+
+	toolchainsMu.Lock()
+	defer toolchainsMu.Unlock()
+	toolchains[tc.Name] = tc
+}
+
+// LookupToolchain returns the named toolchain ("" = default)
+func LookupToolchain(name string) (Toolchain, bool) {
+	if name == "" {
+		name = DefaultToolchain
+	}
+
+	toolchainsMu.RLock()
+	defer toolchainsMu.RUnlock()
+	tc, ok := toolchains[name]
+	return tc, ok
+}
+
+// ListToolchains returns all registered toolchain names sorted alphabetically
+func ListToolchains() []string {
+	toolchainsMu.RLock()
+	defer toolchainsMu.RUnlock()
+
+	names := make([]string, 0, len(toolchains))
+	for name := range toolchains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns an Executor for the named toolchain ("" = default). The default
+// toolchain falls back to ffmpeg and ffprobe from PATH when not configured.
+func New(toolchain string) (Executor, error) {
+	tc, ok := LookupToolchain(toolchain)
+	if !ok {
+		if toolchain != "" && toolchain != DefaultToolchain {
+			return nil, fmt.Errorf("toolchain %q not configured", toolchain)
+		}
+		tc = Toolchain{Name: DefaultToolchain}
+	}
+
+	return NewFFmpegWithPaths(tc.FFmpegPath, tc.FFprobePath)
+}
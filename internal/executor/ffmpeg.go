@@ -4,30 +4,51 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/onedusk/sb/internal/media"
 )
 
 // FFmpegOptions contains options for ffmpeg execution
 type FFmpegOptions struct {
 	// Video options
-	VideoCodec    string // h264, h265, vp9
-	CRF           int    // Constant Rate Factor (0-51, lower = better quality)
-	Preset        string // ultrafast, superfast, veryfast, faster, fast, medium, slow, slower, veryslow
-	Bitrate       string // e.g., "2M", "5M"
+	VideoCodec string // h264, h265, vp9
+	CRF        int    // Constant Rate Factor (0-51, lower = better quality)
+	Preset     string // ultrafast, superfast, veryfast, faster, fast, medium, slow, slower, veryslow
+	Bitrate    string // e.g., "2M", "5M"
 
 	// Audio options
-	AudioCodec    string // aac, mp3, copy
-	AudioBitrate  string // e.g., "128k", "192k"
+	AudioCodec   string // aac, mp3, copy
+	AudioBitrate string // e.g., "128k", "192k"
+	AudioFilter  string // audio filter graph (-af), e.g., a loudnorm pass
 
 	// Hardware acceleration
 	HWAccel       string // videotoolbox, nvenc, qsv
 	HWAccelDevice string // optional device specification
 
+	// Output container (muxer name, e.g., "mp4"); required when the output
+	// path has no recognizable extension, such as partial files
+	Format string
+
 	// Advanced
-	ExtraArgs     []string
-	Verbose       bool
+	ExtraArgs []string
+	Verbose   bool
+
+	// Progress reporting
+	Duration time.Duration // input duration, used to compute percentage and ETA
+	Progress ProgressFunc  // called with parsed progress updates (nil = disabled)
+}
+
+// RunOptions controls a raw ffmpeg invocation
+type RunOptions struct {
+	Verbose  bool
+	Duration time.Duration // input duration, used to compute percentage and ETA
+	Progress ProgressFunc  // called with parsed progress updates (nil = disabled)
 }
 
 // FFmpegResult contains the result of an ffmpeg execution
@@ -42,24 +63,94 @@ type FFmpegResult struct {
 // FFmpeg wraps ffmpeg command execution
 type FFmpeg struct {
 	binaryPath string
+	probePath  string
 }
 
-// NewFFmpeg creates a new FFmpeg executor
+// NewFFmpeg creates a new FFmpeg executor using the default toolchain
 func NewFFmpeg() (*FFmpeg, error) {
-	// Check if ffmpeg is available
-	path, err := exec.LookPath("ffmpeg")
+	tc, _ := LookupToolchain(DefaultToolchain)
+	return NewFFmpegWithPaths(tc.FFmpegPath, tc.FFprobePath)
+}
+
+// NewFFmpegWithPaths creates a new FFmpeg executor for specific binaries.
+// An empty ffmpegPath looks up "ffmpeg" in PATH; an empty ffprobePath is
+// derived from the ffmpeg location (see findFFprobe).
+func NewFFmpegWithPaths(ffmpegPath, ffprobePath string) (*FFmpeg, error) {
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+
+	path, err := exec.LookPath(ffmpegPath)
 	if err != nil {
-		return nil, fmt.Errorf("ffmpeg not found in PATH: %w", err)
+		return nil, fmt.Errorf("ffmpeg not found (%s): %w", ffmpegPath, err)
+	}
+
+	probePath := ""
+	if ffprobePath != "" {
+		probePath, err = exec.LookPath(ffprobePath)
+		if err != nil {
+			return nil, fmt.Errorf("ffprobe not found (%s): %w", ffprobePath, err)
+		}
+	} else {
+		probePath = findFFprobe(path)
 	}
 
 	return &FFmpeg{
 		binaryPath: path,
+		probePath:  probePath,
 	}, nil
 }
 
+// findFFprobe locates ffprobe for an ffmpeg binary: first a sibling named
+// like the ffmpeg binary (ffmpeg-static -> ffprobe-static), then a plain
+// "ffprobe" sibling, then PATH. Returns "" if none is found.
+func findFFprobe(ffmpegPath string) string {
+	dir := filepath.Dir(ffmpegPath)
+	base := filepath.Base(ffmpegPath)
+
+	candidates := []string{}
+	if strings.Contains(base, "ffmpeg") {
+		candidates = append(candidates, filepath.Join(dir, strings.Replace(base, "ffmpeg", "ffprobe", 1)))
+	}
+	candidates = append(candidates, filepath.Join(dir, "ffprobe"+filepath.Ext(base)))
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+
+	if path, err := exec.LookPath("ffprobe"); err == nil {
+		return path
+	}
+	return ""
+}
+
+// BinaryPath returns the resolved ffmpeg path
+func (f *FFmpeg) BinaryPath() string {
+	return f.binaryPath
+}
+
+// ProbePath returns the resolved ffprobe path ("" if not found)
+func (f *FFmpeg) ProbePath() string {
+	return f.probePath
+}
+
 // Convert executes ffmpeg to convert a file
 func (f *FFmpeg) Convert(ctx context.Context, input, output string, opts FFmpegOptions) (*FFmpegResult, error) {
-	args := f.buildArgs(input, output, opts)
+	return f.Run(ctx, f.buildArgs(input, output, opts), RunOptions{
+		Verbose:  opts.Verbose,
+		Duration: opts.Duration,
+		Progress: opts.Progress,
+	})
+}
+
+// Run executes ffmpeg with args. When opts.Progress is set, machine-readable
+// progress output is requested and parsed.
+func (f *FFmpeg) Run(ctx context.Context, args []string, opts RunOptions) (*FFmpegResult, error) {
+	if opts.Progress != nil {
+		args = append([]string{"-progress", "pipe:1", "-nostats"}, args...)
+	}
 
 	if opts.Verbose {
 		fmt.Printf("[ffmpeg] %s %s\n", f.binaryPath, strings.Join(args, " "))
@@ -67,13 +158,18 @@ func (f *FFmpeg) Convert(ctx context.Context, input, output string, opts FFmpegO
 
 	start := time.Now()
 
-	cmd := exec.CommandContext(ctx, f.binaryPath, args...)
+	cmd := Command(ctx, f.binaryPath, args...)
 
 	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	var err error
+	if opts.Progress != nil {
+		err = runWithProgress(cmd, &stdout, opts.Duration, opts.Progress)
+	} else {
+		cmd.Stdout = &stdout
+		err = cmd.Run()
+	}
 	duration := time.Since(start)
 
 	result := &FFmpegResult{
@@ -87,9 +183,50 @@ func (f *FFmpeg) Convert(ctx context.Context, input, output string, opts FFmpegO
 	return result, err
 }
 
+// Command builds an isolated, context-bound command: it runs in its own
+// process group so only an explicit cancellation stops it
+func Command(ctx context.Context, path string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, path, args...)
+	isolateProcess(cmd)
+	cmd.WaitDelay = 5 * time.Second
+	return cmd
+}
+
+// runWithProgress runs cmd while parsing its "-progress pipe:1" output
+func runWithProgress(cmd *exec.Cmd, stdout *bytes.Buffer, total time.Duration, fn ProgressFunc) error {
+	pipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// Keep a copy of stdout so it is still available in FFmpegResult. The
+	// parser may stop early (e.g., on an overlong line); drain the rest so
+	// ffmpeg never blocks on a full pipe and Wait can return.
+	tee := io.TeeReader(pipe, stdout)
+	parseProgress(tee, total, fn)
+	io.Copy(io.Discard, tee)
+
+	return cmd.Wait()
+}
+
+// GetDuration returns the container duration of a media file using ffprobe
+func (f *FFmpeg) GetDuration(ctx context.Context, input string) (time.Duration, error) {
+	info, err := f.GetInfo(ctx, input)
+	if err != nil {
+		return 0, err
+	}
+	return info.Duration(), nil
+}
+
 // GetInfo retrieves media file information using ffprobe
-func (f *FFmpeg) GetInfo(ctx context.Context, input string) (string, error) {
-	probePath := strings.Replace(f.binaryPath, "ffmpeg", "ffprobe", 1)
+func (f *FFmpeg) GetInfo(ctx context.Context, input string) (*media.MediaInfo, error) {
+	if f.probePath == "" {
+		return nil, fmt.Errorf("ffprobe not found next to %s or in PATH", f.binaryPath)
+	}
 
 	args := []string{
 		"-v", "quiet",
@@ -99,14 +236,22 @@ func (f *FFmpeg) GetInfo(ctx context.Context, input string) (string, error) {
 		input,
 	}
 
-	cmd := exec.CommandContext(ctx, probePath, args...)
+	cmd := Command(ctx, f.probePath, args...)
 
-	output, err := cmd.CombinedOutput()
+	output, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("ffprobe failed: %w", err)
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
 	}
 
-	return string(output), nil
+	info, err := media.ParseProbe(output)
+	if err != nil {
+		return nil, err
+	}
+	if info.Path == "" {
+		info.Path = input
+	}
+
+	return info, nil
 }
 
 // buildArgs constructs ffmpeg command arguments
@@ -115,7 +260,7 @@ func (f *FFmpeg) buildArgs(input, output string, opts FFmpegOptions) []string {
 
 	// Hardware acceleration (must come before input)
 	if opts.HWAccel != "" {
-		args = append(args, "-hwaccel", opts.HWAccel)
+		args = append(args, "-hwaccel", HWAccelFor(opts.HWAccel))
 		if opts.HWAccelDevice != "" {
 			args = append(args, "-hwaccel_device", opts.HWAccelDevice)
 		}
@@ -124,27 +269,14 @@ func (f *FFmpeg) buildArgs(input, output string, opts FFmpegOptions) []string {
 	// Input file
 	args = append(args, "-i", input)
 
-	// Video codec
-	if opts.VideoCodec != "" {
-		codec := opts.VideoCodec
-		// Map to hardware-accelerated codec if requested
-		if opts.HWAccel == "videotoolbox" {
-			switch codec {
-			case "h264":
-				codec = "h264_videotoolbox"
-			case "h265", "hevc":
-				codec = "hevc_videotoolbox"
-			}
-		}
-		args = append(args, "-c:v", codec)
-	} else {
-		args = append(args, "-c:v", "libx264")
-	}
+	// Video codec (mapped to hardware-accelerated encoder if requested)
+	encoder := EncoderFor(opts.VideoCodec, opts.HWAccel)
+	args = append(args, "-c:v", encoder)
 
 	// Quality settings
 	if opts.CRF > 0 {
 		// CRF only works with certain codecs
-		if !strings.Contains(opts.VideoCodec, "videotoolbox") {
+		if !strings.Contains(encoder, "videotoolbox") {
 			args = append(args, "-crf", fmt.Sprintf("%d", opts.CRF))
 		} else {
 			// VideoToolbox uses different quality scale
@@ -153,7 +285,7 @@ func (f *FFmpeg) buildArgs(input, output string, opts FFmpegOptions) []string {
 	}
 
 	// Preset (encoding speed vs compression)
-	if opts.Preset != "" && !strings.Contains(opts.VideoCodec, "videotoolbox") {
+	if opts.Preset != "" && !strings.Contains(encoder, "videotoolbox") {
 		args = append(args, "-preset", opts.Preset)
 	}
 
@@ -174,17 +306,41 @@ func (f *FFmpeg) buildArgs(input, output string, opts FFmpegOptions) []string {
 		args = append(args, "-b:a", opts.AudioBitrate)
 	}
 
+	// Audio filters
+	if opts.AudioFilter != "" {
+		args = append(args, "-af", opts.AudioFilter)
+	}
+
 	// Extra arguments
 	if len(opts.ExtraArgs) > 0 {
 		args = append(args, opts.ExtraArgs...)
 	}
 
+	// Output container
+	if opts.Format != "" {
+		args = append(args, "-f", opts.Format)
+	}
+
 	// Output file
 	args = append(args, output)
 
 	return args
 }
 
+// Requirements returns the ffmpeg components needed to run a conversion with opts
+func (opts FFmpegOptions) Requirements() Requirements {
+	req := Requirements{
+		Encoders: []string{
+			EncoderFor(opts.VideoCodec, opts.HWAccel),
+			AudioEncoderFor(opts.AudioCodec),
+		},
+	}
+	if opts.HWAccel != "" {
+		req.HWAccels = []string{HWAccelFor(opts.HWAccel)}
+	}
+	return req
+}
+
 // CheckVersion returns the ffmpeg version
 func (f *FFmpeg) CheckVersion() (string, error) {
 	cmd := exec.Command(f.binaryPath, "-version")
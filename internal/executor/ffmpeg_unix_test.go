@@ -0,0 +1,36 @@
+//go:build !windows
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRunWithProgressOversizedLine(t *testing.T) {
+	// A line over the scanner limit, then more output than a pipe buffers
+	cmd := Command(context.Background(), "/bin/sh", "-c", `head -c 100000 /dev/zero | tr '\0' 'x'
+echo
+head -c 2000000 /dev/zero | tr '\0' 'y'
+`)
+
+	var stdout bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- runWithProgress(cmd, &stdout, time.Second, func(ProgressInfo) {})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("runWithProgress() error = %v", err)
+		}
+		if stdout.Len() != 2100001 {
+			t.Errorf("kept %d bytes of stdout, want 2100001", stdout.Len())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("runWithProgress() hung on an oversized output line")
+	}
+}
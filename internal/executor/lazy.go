@@ -0,0 +1,45 @@
+package executor
+
+import "sync"
+
+// Lazy holds the executor of a converter, created for the selected
+// toolchain on first use. The zero value uses the default toolchain.
+type Lazy struct {
+	mu        sync.Mutex
+	toolchain string
+	exec      Executor
+}
+
+// Get returns the executor, creating one for the toolchain if needed
+func (l *Lazy) Get() (Executor, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.exec == nil {
+		e, err := New(l.toolchain)
+		if err != nil {
+			return nil, err
+		}
+		l.exec = e
+	}
+	return l.exec, nil
+}
+
+// Set replaces the executor until a different toolchain is selected
+func (l *Lazy) Set(e Executor) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.exec = e
+}
+
+// SetToolchain selects the toolchain; a different one drops the executor
+// so the next Get creates a new one
+func (l *Lazy) SetToolchain(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if name != l.toolchain {
+		l.exec = nil
+	}
+	l.toolchain = name
+}
@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+)
+
+// fakeExecutor is an Executor that is never run
+type fakeExecutor struct{ Executor }
+
+func TestLazy(t *testing.T) {
+	var l Lazy
+	fake := &fakeExecutor{}
+
+	l.Set(fake)
+	if e, err := l.Get(); err != nil || e != fake {
+		t.Fatalf("Get() = %v, %v, want the executor set", e, err)
+	}
+
+	// Selecting the same toolchain keeps the executor
+	l.SetToolchain("")
+	if e, _ := l.Get(); e != fake {
+		t.Errorf("Get() after the same toolchain = %v, want the executor set", e)
+	}
+
+	// A different toolchain drops it; this one is not configured
+	l.SetToolchain("missing")
+	if _, err := l.Get(); err == nil || !strings.Contains(err.Error(), `toolchain "missing" not configured`) {
+		t.Errorf("Get() error = %v, want the toolchain lookup to fail", err)
+	}
+
+	l.Set(fake)
+	if e, _ := l.Get(); e != fake {
+		t.Errorf("Get() after Set = %v, want the executor set", e)
+	}
+}
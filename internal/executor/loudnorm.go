@@ -0,0 +1,166 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// LoudnessTarget contains EBU R128 normalization targets for the loudnorm
+// filter
+type LoudnessTarget struct {
+	I   float64 // integrated loudness in LUFS (-70 to -5)
+	TP  float64 // maximum true peak in dBTP (-9 to 0)
+	LRA float64 // loudness range in LU (1 to 50)
+}
+
+// DefaultLoudnessTarget returns the targets commonly used for podcasts and
+// online video
+func DefaultLoudnessTarget() LoudnessTarget {
+	return LoudnessTarget{I: -16, TP: -1.5, LRA: 11}
+}
+
+// Validate checks the targets against the ranges loudnorm accepts
+func (t LoudnessTarget) Validate() error {
+	if t.I < -70 || t.I > -5 {
+		return fmt.Errorf("integrated loudness must be between -70 and -5 LUFS, got %g", t.I)
+	}
+	if t.TP < -9 || t.TP > 0 {
+		return fmt.Errorf("true peak must be between -9 and 0 dBTP, got %g", t.TP)
+	}
+	if t.LRA < 1 || t.LRA > 50 {
+		return fmt.Errorf("loudness range must be between 1 and 50 LU, got %g", t.LRA)
+	}
+	return nil
+}
+
+// LoudnessStats contains the statistics loudnorm prints: the loudness of its
+// input and of what it wrote
+type LoudnessStats struct {
+	InputI      float64
+	InputTP     float64
+	InputLRA    float64
+	InputThresh float64
+
+	OutputI   float64
+	OutputTP  float64
+	OutputLRA float64
+
+	// TargetOffset is the gain left to apply after normalization
+	TargetOffset float64
+
+	// NormalizationType is "linear" or "dynamic"; loudnorm falls back to
+	// dynamic when a linear gain would exceed the true peak target
+	NormalizationType string
+}
+
+// Linear reports whether loudnorm applied a single linear gain
+func (s LoudnessStats) Linear() bool {
+	return s.NormalizationType == "linear"
+}
+
+// MeasureLoudness runs the first loudnorm pass over the first audio stream
+// of input (or the one selected by stream, e.g., "0:a:1") and returns its
+// measurements
+func MeasureLoudness(ctx context.Context, e Executor, input, stream string, target LoudnessTarget, opts RunOptions) (LoudnessStats, *FFmpegResult, error) {
+	if stream == "" {
+		stream = "0:a:0"
+	}
+	args := []string{"-i", input, "-map", stream, "-af", target.filter(), "-f", "null", "-"}
+
+	ffResult, err := e.Run(ctx, args, opts)
+	if err != nil {
+		return LoudnessStats{}, ffResult, err
+	}
+	stats, err := ParseLoudness(ffResult.Stderr)
+	if err != nil {
+		return LoudnessStats{}, ffResult, err
+	}
+	if math.IsInf(stats.InputI, -1) {
+		return LoudnessStats{}, ffResult, fmt.Errorf("audio is silent, nothing to normalize")
+	}
+	return stats, ffResult, nil
+}
+
+// Filter returns the second-pass loudnorm filter applying a linear
+// correction for the measured statistics. loudnorm resamples to 192 kHz
+// internally, so the result is resampled to sampleRate (0 = 48 kHz).
+func (t LoudnessTarget) Filter(measured LoudnessStats, sampleRate int) string {
+	if sampleRate <= 0 {
+		sampleRate = 48000
+	}
+	return fmt.Sprintf("%s:measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true,aresample=%d",
+		t.filter(),
+		formatLevel(measured.InputI),
+		formatLevel(measured.InputTP),
+		formatLevel(measured.InputLRA),
+		formatLevel(measured.InputThresh),
+		formatLevel(measured.TargetOffset),
+		sampleRate)
+}
+
+// LoudnessRequirements returns the ffmpeg filters two-pass normalization needs
+func LoudnessRequirements() Requirements {
+	return Requirements{Filters: []string{"loudnorm", "aresample"}}
+}
+
+// filter returns the loudnorm filter for the targets, printing its
+// statistics as JSON
+func (t LoudnessTarget) filter() string {
+	return fmt.Sprintf("loudnorm=I=%s:TP=%s:LRA=%s:print_format=json",
+		formatLevel(t.I), formatLevel(t.TP), formatLevel(t.LRA))
+}
+
+// ParseLoudness extracts the statistics loudnorm prints as JSON at the end of
+// ffmpeg's stderr
+func ParseLoudness(stderr string) (LoudnessStats, error) {
+	end := strings.LastIndex(stderr, "}")
+	if end < 0 {
+		return LoudnessStats{}, fmt.Errorf("no loudnorm statistics in ffmpeg output")
+	}
+	begin := strings.LastIndex(stderr[:end], "{")
+	if begin < 0 {
+		return LoudnessStats{}, fmt.Errorf("no loudnorm statistics in ffmpeg output")
+	}
+
+	// All values, numbers included, are printed as strings
+	var raw map[string]string
+	if err := json.Unmarshal([]byte(stderr[begin:end+1]), &raw); err != nil {
+		return LoudnessStats{}, fmt.Errorf("failed to parse loudnorm statistics: %w", err)
+	}
+
+	stats := LoudnessStats{NormalizationType: raw["normalization_type"]}
+	fields := []struct {
+		key string
+		dst *float64
+	}{
+		{"input_i", &stats.InputI},
+		{"input_tp", &stats.InputTP},
+		{"input_lra", &stats.InputLRA},
+		{"input_thresh", &stats.InputThresh},
+		{"output_i", &stats.OutputI},
+		{"output_tp", &stats.OutputTP},
+		{"output_lra", &stats.OutputLRA},
+		{"target_offset", &stats.TargetOffset},
+	}
+	for _, f := range fields {
+		v, ok := raw[f.key]
+		if !ok {
+			return LoudnessStats{}, fmt.Errorf("loudnorm statistics lack %s", f.key)
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return LoudnessStats{}, fmt.Errorf("invalid loudnorm %s %q", f.key, v)
+		}
+		*f.dst = n
+	}
+	return stats, nil
+}
+
+// formatLevel formats a level for a filter argument
+func formatLevel(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
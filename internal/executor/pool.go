@@ -2,10 +2,14 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"runtime"
 	"sync"
 )
 
+// ErrNotStarted is reported for jobs skipped because the pool was cancelled
+var ErrNotStarted = errors.New("job not started: batch interrupted")
+
 // Job represents a unit of work to be processed
 type Job func(ctx context.Context) error
 
@@ -16,22 +20,34 @@ type Pool struct {
 	results chan error
 	wg      sync.WaitGroup
 	ctx     context.Context
+	jobCtx  context.Context
 	cancel  context.CancelFunc
 }
 
 // NewPool creates a new worker pool
 func NewPool(workers int) *Pool {
+	return NewPoolWithContext(context.Background(), nil, workers)
+}
+
+// NewPoolWithContext creates a worker pool that stops starting new jobs once
+// ctx is cancelled. Running jobs receive jobCtx (nil = ctx), which allows them
+// to finish after ctx is cancelled until jobCtx is cancelled too.
+func NewPoolWithContext(ctx, jobCtx context.Context, workers int) *Pool {
 	if workers <= 0 {
 		workers = runtime.NumCPU()
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
+	if jobCtx == nil {
+		jobCtx = ctx
+	}
 
 	return &Pool{
 		workers: workers,
 		jobs:    make(chan Job),
 		results: make(chan error),
 		ctx:     ctx,
+		jobCtx:  jobCtx,
 		cancel:  cancel,
 	}
 }
@@ -61,7 +77,7 @@ func (p *Pool) Results() <-chan error {
 	return p.results
 }
 
-// Cancel cancels all pending jobs
+// Cancel stops the pool from starting pending jobs
 func (p *Pool) Cancel() {
 	p.cancel()
 }
@@ -71,13 +87,12 @@ func (p *Pool) worker() {
 	defer p.wg.Done()
 
 	for job := range p.jobs {
-		select {
-		case <-p.ctx.Done():
-			p.results <- p.ctx.Err()
-			return
-		default:
-			err := job(p.ctx)
-			p.results <- err
+		// Keep draining the queue after cancellation so Submit never blocks,
+		// reporting every remaining job as not started
+		if p.ctx.Err() != nil {
+			p.results <- ErrNotStarted
+			continue
 		}
+		p.results <- job(p.jobCtx)
 	}
 }
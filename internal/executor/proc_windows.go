@@ -0,0 +1,14 @@
+//go:build windows
+
+package executor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// isolateProcess starts cmd in its own process group so a console Ctrl-C
+// does not reach it directly; cancellation kills the process
+func isolateProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProgressInfo contains encoding progress reported by ffmpeg
+type ProgressInfo struct {
+	OutTime    time.Duration // media time encoded so far
+	Duration   time.Duration // total input duration (0 if unknown)
+	Frame      int64
+	FPS        float64
+	Speed      float64 // encoding speed relative to realtime (e.g., 2.5 = 2.5x)
+	Bitrate    string  // e.g., "1534.2kbits/s"
+	TotalSize  int64   // bytes written so far
+	Percentage float64 // 0-100, only meaningful when Duration is known
+	ETA        time.Duration
+	Done       bool
+}
+
+// ProgressFunc receives progress updates while ffmpeg is running
+type ProgressFunc func(ProgressInfo)
+
+// parseProgress reads ffmpeg "-progress" key=value output and reports
+// one ProgressInfo per block (each block is terminated by a progress= line)
+func parseProgress(r io.Reader, total time.Duration, fn ProgressFunc) {
+	scanner := bufio.NewScanner(r)
+	info := ProgressInfo{Duration: total}
+
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "frame":
+			info.Frame, _ = strconv.ParseInt(value, 10, 64)
+		case "fps":
+			info.FPS, _ = strconv.ParseFloat(value, 64)
+		case "bitrate":
+			if value != "N/A" {
+				info.Bitrate = value
+			}
+		case "total_size":
+			info.TotalSize, _ = strconv.ParseInt(value, 10, 64)
+		case "out_time_us", "out_time_ms":
+			// Both keys are reported in microseconds by ffmpeg
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+				info.OutTime = time.Duration(us) * time.Microsecond
+			}
+		case "speed":
+			info.Speed, _ = strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64)
+		case "progress":
+			info.Done = value == "end"
+			computeProgress(&info)
+			fn(info)
+		}
+	}
+}
+
+// computeProgress derives percentage and ETA from the current block
+func computeProgress(info *ProgressInfo) {
+	if info.Done {
+		info.Percentage = 100
+		info.ETA = 0
+		return
+	}
+
+	if info.Duration <= 0 {
+		return
+	}
+
+	pct := float64(info.OutTime) / float64(info.Duration) * 100
+	if pct > 100 {
+		pct = 100
+	}
+	if pct < 0 {
+		pct = 0
+	}
+	info.Percentage = pct
+
+	if info.Speed > 0 {
+		remaining := info.Duration - info.OutTime
+		if remaining < 0 {
+			remaining = 0
+		}
+		info.ETA = time.Duration(float64(remaining) / info.Speed)
+	}
+}
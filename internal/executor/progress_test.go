@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseProgress(t *testing.T) {
+	output := strings.Join([]string{
+		"frame=120",
+		"fps=30.00",
+		"bitrate=1534.2kbits/s",
+		"total_size=262144",
+		"out_time_us=5000000",
+		"speed=2.5x",
+		"progress=continue",
+		"frame=240",
+		"fps=29.5",
+		"bitrate=N/A",
+		"total_size=524288",
+		"out_time_ms=10000000",
+		"speed=2x",
+		"progress=end",
+	}, "\n")
+
+	var got []ProgressInfo
+	parseProgress(strings.NewReader(output), 20*time.Second, func(p ProgressInfo) {
+		got = append(got, p)
+	})
+
+	if len(got) != 2 {
+		t.Fatalf("got %d updates, want 2", len(got))
+	}
+
+	first := got[0]
+	if first.Frame != 120 || first.FPS != 30 || first.TotalSize != 262144 {
+		t.Errorf("first update counters = %+v", first)
+	}
+	if first.Bitrate != "1534.2kbits/s" {
+		t.Errorf("first bitrate = %q", first.Bitrate)
+	}
+	if first.OutTime != 5*time.Second || first.Speed != 2.5 {
+		t.Errorf("first out time/speed = %v/%v", first.OutTime, first.Speed)
+	}
+	if first.Percentage != 25 || first.ETA != 6*time.Second || first.Done {
+		t.Errorf("first percentage/ETA/done = %v/%v/%v", first.Percentage, first.ETA, first.Done)
+	}
+
+	last := got[1]
+	if last.Bitrate != "1534.2kbits/s" {
+		t.Errorf("N/A bitrate replaced the previous value: %q", last.Bitrate)
+	}
+	if last.OutTime != 10*time.Second {
+		t.Errorf("out_time_ms not read as microseconds: %v", last.OutTime)
+	}
+	if !last.Done || last.Percentage != 100 || last.ETA != 0 {
+		t.Errorf("last update = %+v, want done at 100%%", last)
+	}
+}
+
+func TestParseProgressIgnoresNoise(t *testing.T) {
+	output := "garbage line\nout_time_us=-1\nout_time_us=N/A\nprogress=continue\n"
+
+	var got []ProgressInfo
+	parseProgress(strings.NewReader(output), 0, func(p ProgressInfo) {
+		got = append(got, p)
+	})
+
+	if len(got) != 1 {
+		t.Fatalf("got %d updates, want 1", len(got))
+	}
+	if got[0].OutTime != 0 || got[0].Percentage != 0 {
+		t.Errorf("update = %+v, want zero progress", got[0])
+	}
+}
+
+func TestComputeProgress(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    ProgressInfo
+		wantPct float64
+		wantETA time.Duration
+	}{
+		{"halfway at 2x",
+			ProgressInfo{OutTime: 30 * time.Second, Duration: time.Minute, Speed: 2},
+			50, 15 * time.Second},
+		{"unknown duration",
+			ProgressInfo{OutTime: 30 * time.Second, Speed: 2},
+			0, 0},
+		{"unknown speed",
+			ProgressInfo{OutTime: 15 * time.Second, Duration: time.Minute},
+			25, 0},
+		{"past the end is clamped",
+			ProgressInfo{OutTime: 2 * time.Minute, Duration: time.Minute, Speed: 1},
+			100, 0},
+		{"done",
+			ProgressInfo{OutTime: 10 * time.Second, Duration: time.Minute, Speed: 1, ETA: time.Minute, Done: true},
+			100, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tt.info
+			computeProgress(&info)
+			if info.Percentage != tt.wantPct {
+				t.Errorf("Percentage = %v, want %v", info.Percentage, tt.wantPct)
+			}
+			if info.ETA != tt.wantETA {
+				t.Errorf("ETA = %v, want %v", info.ETA, tt.wantETA)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PartialSuffix marks output files that are still being written
+const PartialSuffix = ".sb-partial"
+
+// StalePartialAge is how long a partial file must be untouched before a
+// sweep considers it left over from a crashed run
+const StalePartialAge = 5 * time.Minute
+
+// PartialPath returns the hidden sibling path used while writing output,
+// e.g., "out/video.mp4" -> "out/.video.mp4.sb-partial"
+func PartialPath(output string) string {
+	dir, base := filepath.Split(output)
+	return filepath.Join(dir, "."+base+PartialSuffix)
+}
+
+// IsPartial reports whether path is a partial output file
+func IsPartial(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasPrefix(base, ".") && strings.HasSuffix(base, PartialSuffix)
+}
+
+// Commit flushes a finished partial file to disk and atomically renames it
+// to its final path
+func Commit(partial, output string) error {
+	f, err := os.OpenFile(partial, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open partial output: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync output: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close output: %w", err)
+	}
+
+	if err := os.Rename(partial, output); err != nil {
+		return fmt.Errorf("failed to move output into place: %w", err)
+	}
+
+	// Persist the rename itself (best effort; not supported on all platforms)
+	if dir, err := os.Open(filepath.Dir(output)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+
+	return nil
+}
+
+// Discard removes a partial file if it exists
+func Discard(partial string) error {
+	if err := os.Remove(partial); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// CommitDir moves the entries of a finished partial directory into dir,
+// replacing entries of the same name, and removes the partial directory.
+// Each entry is renamed atomically, but the set as a whole is not; the
+// entries named in last are moved after all others, in order, so that an
+// index such as a playlist appears only once the files it lists are in place.
+func CommitDir(partial, dir string, last ...string) error {
+	entries, err := os.ReadDir(partial)
+	if err != nil {
+		return fmt.Errorf("failed to read partial output: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	present := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		present[entry.Name()] = true
+		if !slices.Contains(last, entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	for _, name := range last {
+		if present[name] {
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		target := filepath.Join(dir, name)
+		// Rename replaces files in place but not directories
+		if info, err := os.Lstat(target); err == nil && info.IsDir() {
+			if err := os.RemoveAll(target); err != nil {
+				return fmt.Errorf("failed to replace %s: %w", target, err)
+			}
+		}
+		if err := os.Rename(filepath.Join(partial, name), target); err != nil {
+			return fmt.Errorf("failed to move output into place: %w", err)
+		}
+	}
+	return os.Remove(partial)
+}
+
+// DiscardDir removes a partial directory and its contents
+func DiscardDir(partial string) error {
+	return os.RemoveAll(partial)
+}
+
+// SweepPartials removes partial files and directories in dirs
+// (non-recursively) that have not been modified for at least minAge,
+// returning the removed paths
+func SweepPartials(dirs []string, minAge time.Duration) ([]string, error) {
+	removed := []string{}
+	seen := make(map[string]bool)
+	cutoff := time.Now().Add(-minAge)
+
+	for _, dir := range dirs {
+		if dir == "" {
+			dir = "."
+		}
+		dir = filepath.Clean(dir)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return removed, fmt.Errorf("error reading directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			if !IsPartial(entry.Name()) {
+				continue
+			}
+
+			info, err := entry.Info()
+			if err != nil || info.ModTime().After(cutoff) {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			if err := os.RemoveAll(path); err == nil {
+				removed = append(removed, path)
+			}
+		}
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,63 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile writes data to path, creating parent directories
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommitDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "clip")
+	partial := PartialPath(dir + "/master.m3u8")
+
+	// A previous run left a playlist, a rung directory and an unrelated file
+	writeFile(t, filepath.Join(dir, "master.m3u8"), "old master")
+	writeFile(t, filepath.Join(dir, "720p", "old.ts"), "old segment")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "kept")
+
+	writeFile(t, filepath.Join(partial, "master.m3u8"), "new master")
+	writeFile(t, filepath.Join(partial, "master.mpd"), "new manifest")
+	writeFile(t, filepath.Join(partial, "720p", "chunk-0.ts"), "new segment")
+
+	if err := CommitDir(partial, dir, "master.mpd", "master.m3u8", "missing.m3u8"); err != nil {
+		t.Fatalf("CommitDir() error = %v", err)
+	}
+
+	want := map[string]string{
+		"master.m3u8":     "new master",
+		"master.mpd":      "new manifest",
+		"720p/chunk-0.ts": "new segment",
+		"notes.txt":       "kept",
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != content {
+			t.Errorf("%s = %q, %v, want %q", name, data, err, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "720p", "old.ts")); !os.IsNotExist(err) {
+		t.Errorf("replaced directory kept old.ts (stat error %v)", err)
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Errorf("partial directory still exists (stat error %v)", err)
+	}
+}
+
+func TestCommitDirMissingPartial(t *testing.T) {
+	root := t.TempDir()
+	if err := CommitDir(filepath.Join(root, "missing"), filepath.Join(root, "out")); err == nil {
+		t.Error("CommitDir() of a missing partial directory succeeded")
+	}
+}
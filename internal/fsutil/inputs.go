@@ -0,0 +1,97 @@
+package fsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Filter decides whether a discovered file should be included
+type Filter func(path string) bool
+
+// GatherInputs collects input files from args (files, globs or directories) and an
+// optional input directory, keeping only files accepted by the filter
+func GatherInputs(args []string, dir string, recursive bool, accept Filter) ([]string, error) {
+	inputs := []string{}
+
+	// If directory specified, scan it
+	if dir != "" {
+		found, err := scanDir(dir, recursive, accept)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, found...)
+	}
+
+	// Add files from args
+	for _, arg := range args {
+		// Check if it's a glob pattern
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+		}
+
+		if len(matches) > 0 {
+			for _, match := range matches {
+				info, err := os.Stat(match)
+				if err != nil {
+					continue
+				}
+				if info.IsDir() {
+					// Directory argument (or glob match): scan it
+					found, err := scanDir(match, recursive, accept)
+					if err != nil {
+						return nil, err
+					}
+					inputs = append(inputs, found...)
+				} else if !IsPartial(match) && accept(match) {
+					inputs = append(inputs, match)
+				}
+			}
+		} else {
+			// Direct file path
+			if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+				inputs = append(inputs, arg)
+			}
+		}
+	}
+
+	return inputs, nil
+}
+
+// scanDir lists accepted files in dir, descending into subdirectories if
+// recursive. Partial outputs of running or crashed conversions are skipped.
+func scanDir(dir string, recursive bool, accept Filter) ([]string, error) {
+	inputs := []string{}
+
+	if recursive {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && !IsPartial(path) && accept(path) {
+				inputs = append(inputs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error walking directory: %w", err)
+		}
+		return inputs, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			path := filepath.Join(dir, entry.Name())
+			if !IsPartial(path) && accept(path) {
+				inputs = append(inputs, path)
+			}
+		}
+	}
+
+	return inputs, nil
+}
@@ -0,0 +1,67 @@
+package interrupt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+type abortKey struct{}
+
+// Notify returns a context that is cancelled on the first SIGINT/SIGTERM.
+// A second signal cancels the abort context attached to it (see Abort);
+// any further signal gets the default behavior and terminates the process.
+// stop releases the signal handler; it may be called more than once.
+func Notify(parent context.Context) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(parent)
+	abort, cancelAbort := context.WithCancel(parent)
+	ctx = context.WithValue(ctx, abortKey{}, abort)
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			fmt.Fprintln(os.Stderr, "\nInterrupt received: finishing running jobs, no new jobs will start (press Ctrl-C again to abort)")
+			cancel()
+		case <-done:
+			return
+		}
+
+		select {
+		case <-signals:
+			fmt.Fprintln(os.Stderr, "\nAborting running jobs...")
+			signal.Stop(signals)
+			cancelAbort()
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+			cancel()
+			cancelAbort()
+		})
+	}
+	return ctx, stop
+}
+
+// Abort returns the context cancelled on the second interrupt, or ctx itself
+// if it was not created by Notify
+func Abort(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	if abort, ok := ctx.Value(abortKey{}).(context.Context); ok {
+		return abort
+	}
+	return ctx
+}
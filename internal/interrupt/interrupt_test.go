@@ -0,0 +1,16 @@
+package interrupt
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStopTwice(t *testing.T) {
+	ctx, stop := Notify(context.Background())
+	stop()
+	stop()
+
+	if ctx.Err() == nil || Abort(ctx).Err() == nil {
+		t.Error("stop() left the contexts running")
+	}
+}
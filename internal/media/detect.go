@@ -0,0 +1,163 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Detection configures how input files are matched against the formats a
+// converter accepts
+type Detection struct {
+	// TrustExtension decides on the file extension alone, without reading files
+	TrustExtension bool
+
+	// Probe, if set, confirms every file with ffprobe and returns its format
+	// name (e.g., "mov,mp4,m4a,3gp,3g2,mj2"). Files without a recognized
+	// signature must then also match by format name.
+	Probe func(path string) (string, error)
+}
+
+var (
+	detectionMu sync.RWMutex
+	detection   Detection
+)
+
+// SetDetection replaces the detection settings used by CheckInput
+func SetDetection(d Detection) {
+	detectionMu.Lock()
+	defer detectionMu.Unlock()
+	detection = d
+}
+
+// currentDetection returns the detection settings
+func currentDetection() Detection {
+	detectionMu.RLock()
+	defer detectionMu.RUnlock()
+	return detection
+}
+
+// demuxerExtensions maps ffprobe format names to the extensions they cover
+// where the two differ
+var demuxerExtensions = map[string][]string{
+	"matroska": {".mkv", ".mka", ".mk3d"},
+	"mpegts":   {".ts", ".mts", ".m2ts"},
+	"mpeg":     {".mpg", ".mpeg", ".vob"},
+	"asf":      {".wmv", ".wma", ".asf"},
+	"aiff":     {".aiff", ".aif"},
+	"jpeg":     {".jpg", ".jpeg"},
+	"tiff":     {".tif", ".tiff"},
+	"adts":     {".aac"},
+}
+
+// CheckInput verifies that the file at path holds media in one of the formats
+// named by exts. The extension decides when it fits the file's signature;
+// misnamed files and files without an extension are judged by their
+// signature, and files whose format has no signature by their extension.
+func CheckInput(path string, exts []string) error {
+	d := currentDetection()
+	ext := strings.ToLower(filepath.Ext(path))
+
+	if d.TrustExtension {
+		if HasExtension(path, exts) {
+			return nil
+		}
+		return fmt.Errorf("unsupported file format: %s", describeExt(ext))
+	}
+
+	c, err := Sniff(path)
+	switch {
+	case err == nil && ext != "" && c.Matches([]string{ext}):
+		// Correctly named: the extension picks among formats sharing a container
+		if !HasExtension(path, exts) {
+			return fmt.Errorf("unsupported file format: %s", ext)
+		}
+	case err == nil && !c.Matches(exts):
+		// Misnamed or without extension: the content decides
+		if ext == "" {
+			return fmt.Errorf("unsupported file format: %s", c)
+		}
+		return fmt.Errorf("unsupported file format: %s content in %s file", c, ext)
+	case err == nil:
+		// Misnamed, but the content is a supported format
+	case errors.Is(err, ErrUnknownContainer):
+		if !HasExtension(path, exts) {
+			return fmt.Errorf("unsupported file format: %s", describeExt(ext))
+		}
+	default:
+		return fmt.Errorf("cannot read file: %w", err)
+	}
+
+	if d.Probe == nil {
+		return nil
+	}
+
+	name, err := d.Probe(path)
+	if err != nil {
+		return fmt.Errorf("not a readable media file: %w", err)
+	}
+	if c.Name == "" && !probeMatches(name, exts) {
+		return fmt.Errorf("unsupported file format: ffprobe reports %s", name)
+	}
+	return nil
+}
+
+// Accepts reports whether the file at path holds media in one of the formats
+// named by exts; it is meant as an input filter
+func Accepts(path string, exts []string) bool {
+	return CheckInput(path, exts) == nil
+}
+
+// IsMedia reports whether the file at path is a known media format, by
+// signature or, for formats without one (or when extensions are trusted),
+// by extension
+func IsMedia(path string) bool {
+	if !currentDetection().TrustExtension {
+		if _, err := Sniff(path); err == nil {
+			return true
+		}
+	}
+	return IsMediaFile(path)
+}
+
+// DetectExt returns the extension that describes the content of the file at
+// path: its own extension if that fits the content (or detection trusts
+// extensions), otherwise the preferred extension of the sniffed container
+func DetectExt(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if currentDetection().TrustExtension {
+		return ext
+	}
+
+	c, err := Sniff(path)
+	if err != nil || c.Matches([]string{ext}) {
+		return ext
+	}
+	return c.Ext()
+}
+
+// probeMatches reports whether one of the comma-separated ffprobe format
+// names covers one of exts
+func probeMatches(formatName string, exts []string) bool {
+	for _, name := range strings.Split(formatName, ",") {
+		covered, ok := demuxerExtensions[name]
+		if !ok {
+			covered = []string{"." + name}
+		}
+		if slices.ContainsFunc(covered, func(e string) bool { return slices.Contains(exts, e) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeExt names an extension for error messages
+func describeExt(ext string) string {
+	if ext == "" {
+		return "no extension"
+	}
+	return ext
+}
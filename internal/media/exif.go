@@ -0,0 +1,215 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// exifOrientationTag is the EXIF tag holding the image orientation
+const exifOrientationTag = 0x0112
+
+// maxAPP1Payload is the largest EXIF payload a JPEG APP1 segment can hold
+// (65535 bytes minus the length field and the "Exif\0\0" header)
+const maxAPP1Payload = 0xFFFF - 2 - 6
+
+// ErrExifTooLarge is returned when EXIF data does not fit a JPEG APP1 segment
+var ErrExifTooLarge = errors.New("EXIF data too large for a JPEG segment")
+
+// isTIFFHeader reports whether b starts with a TIFF header
+func isTIFFHeader(b []byte) bool {
+	return len(b) >= 8 && (bytes.HasPrefix(b, []byte("II*\x00")) || bytes.HasPrefix(b, []byte("MM\x00*")))
+}
+
+// tiffByteOrder returns the byte order of a TIFF structure
+func tiffByteOrder(tiff []byte) (binary.ByteOrder, error) {
+	if !isTIFFHeader(tiff) {
+		return nil, errors.New("not a TIFF structure")
+	}
+	if tiff[0] == 'I' {
+		return binary.LittleEndian, nil
+	}
+	return binary.BigEndian, nil
+}
+
+// ExifOrientation returns the orientation (1-8) recorded in the IFD0 of a
+// TIFF-structured EXIF block, or 0 if there is none
+func ExifOrientation(tiff []byte) int {
+	order, err := tiffByteOrder(tiff)
+	if err != nil {
+		return 0
+	}
+	if off, ok := findIFD0Entry(tiff, order, exifOrientationTag); ok {
+		return int(order.Uint16(tiff[off+8:]))
+	}
+	return 0
+}
+
+// SetExifOrientation returns a copy of a TIFF-structured EXIF block with the
+// orientation tag set to orientation. Blocks without the tag are returned
+// unchanged.
+func SetExifOrientation(tiff []byte, orientation int) ([]byte, error) {
+	order, err := tiffByteOrder(tiff)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte{}, tiff...)
+	if off, ok := findIFD0Entry(out, order, exifOrientationTag); ok {
+		order.PutUint16(out[off+8:], uint16(orientation))
+	}
+	return out, nil
+}
+
+// findIFD0Entry returns the offset of the IFD0 entry for tag
+func findIFD0Entry(tiff []byte, order binary.ByteOrder, tag uint16) (int, bool) {
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		off := ifd + 2 + i*12
+		if off+12 > len(tiff) {
+			return 0, false
+		}
+		if order.Uint16(tiff[off:]) == tag {
+			return off, true
+		}
+	}
+	return 0, false
+}
+
+// InsertJPEGExif returns jpeg with a TIFF-structured EXIF block stored in an
+// APP1 segment, placed after the JFIF header if there is one. Existing EXIF
+// segments are replaced.
+func InsertJPEGExif(jpeg, tiff []byte) ([]byte, error) {
+	if !bytes.HasPrefix(jpeg, []byte{0xFF, 0xD8}) {
+		return nil, errors.New("not a JPEG file")
+	}
+	if !isTIFFHeader(tiff) {
+		return nil, errors.New("not a TIFF structure")
+	}
+	if len(tiff) > maxAPP1Payload {
+		return nil, fmt.Errorf("%w: %d bytes", ErrExifTooLarge, len(tiff))
+	}
+
+	segment := make([]byte, 0, 10+len(tiff))
+	segment = append(segment, 0xFF, 0xE1)
+	segment = binary.BigEndian.AppendUint16(segment, uint16(2+6+len(tiff)))
+	segment = append(segment, "Exif\x00\x00"...)
+	segment = append(segment, tiff...)
+
+	out := make([]byte, 0, len(jpeg)+len(segment))
+	out = append(out, jpeg[:2]...)
+	inserted := false
+
+	// Copy the leading APPn segments, dropping old EXIF and inserting ours
+	pos := 2
+	for pos+4 <= len(jpeg) && jpeg[pos] == 0xFF && jpeg[pos+1] >= 0xE0 && jpeg[pos+1] <= 0xEF {
+		length := int(binary.BigEndian.Uint16(jpeg[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(jpeg) {
+			return nil, errors.New("truncated JPEG segment")
+		}
+		isJFIF := jpeg[pos+1] == 0xE0
+		isExif := jpeg[pos+1] == 0xE1 && bytes.HasPrefix(jpeg[pos+4:end], []byte("Exif\x00\x00"))
+
+		if !isJFIF && !inserted {
+			out = append(out, segment...)
+			inserted = true
+		}
+		if !isExif {
+			out = append(out, jpeg[pos:end]...)
+		}
+		pos = end
+	}
+	if !inserted {
+		out = append(out, segment...)
+	}
+	return append(out, jpeg[pos:]...), nil
+}
+
+// ImageExif returns the TIFF-structured EXIF block of a JPEG, PNG, WebP or
+// TIFF image, or nil if it has none. For TIFF files the image itself is
+// returned, since its first IFD carries the EXIF orientation.
+func ImageExif(data []byte) []byte {
+	switch {
+	case isTIFFHeader(data):
+		return data
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		return jpegExif(data)
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return pngExif(data)
+	case len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && string(data[8:12]) == "WEBP":
+		return webpExif(data)
+	}
+	return nil
+}
+
+// jpegExif returns the payload of the Exif APP1 segment of a JPEG
+func jpegExif(jpeg []byte) []byte {
+	pos := 2
+	for pos+4 <= len(jpeg) && jpeg[pos] == 0xFF {
+		marker := jpeg[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// Image data or end of image: no more metadata segments
+			break
+		}
+		length := int(binary.BigEndian.Uint16(jpeg[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(jpeg) {
+			break
+		}
+		if marker == 0xE1 && bytes.HasPrefix(jpeg[pos+4:end], []byte("Exif\x00\x00")) {
+			return exifBlock(jpeg[pos+10 : end])
+		}
+		pos = end
+	}
+	return nil
+}
+
+// pngExif returns the eXIf chunk of a PNG
+func pngExif(png []byte) []byte {
+	pos := 8
+	for pos+8 <= len(png) {
+		size := int(binary.BigEndian.Uint32(png[pos:]))
+		kind := string(png[pos+4 : pos+8])
+		end := pos + 8 + size
+		if size < 0 || end+4 > len(png) || kind == "IDAT" {
+			break
+		}
+		if kind == "eXIf" {
+			return exifBlock(png[pos+8 : end])
+		}
+		pos = end + 4 // CRC
+	}
+	return nil
+}
+
+// webpExif returns the EXIF chunk of a WebP image
+func webpExif(webp []byte) []byte {
+	pos := 12
+	for pos+8 <= len(webp) {
+		kind := string(webp[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(webp[pos+4:]))
+		end := pos + 8 + size
+		if size < 0 || end > len(webp) {
+			break
+		}
+		if kind == "EXIF" {
+			// Some writers keep the JPEG "Exif\0\0" header
+			return exifBlock(bytes.TrimPrefix(webp[pos+8:end], []byte("Exif\x00\x00")))
+		}
+		pos = end + size%2 // chunks are padded to an even size
+	}
+	return nil
+}
+
+// exifBlock returns b if it holds a TIFF structure, nil otherwise
+func exifBlock(b []byte) []byte {
+	if !isTIFFHeader(b) {
+		return nil
+	}
+	return b
+}
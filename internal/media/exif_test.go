@@ -0,0 +1,131 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// segment returns a JPEG marker segment holding payload
+func segment(marker byte, payload string) []byte {
+	return append([]byte{0xFF, marker, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+}
+
+// jpegOf returns a minimal JPEG made of the given segments and a scan
+func jpegOf(segments ...[]byte) []byte {
+	b := []byte{0xFF, 0xD8}
+	for _, s := range segments {
+		b = append(b, s...)
+	}
+	return append(b, 0xFF, 0xDA, 0x00, 0x02, 0x11, 0x22, 0xFF, 0xD9)
+}
+
+func TestInsertJPEGExif(t *testing.T) {
+	jfif := segment(0xE0, "JFIF\x00\x01\x02")
+	exif := segment(0xE1, "Exif\x00\x00"+string(testTIFF))
+	oldExif := segment(0xE1, "Exif\x00\x00MM\x00*\x00\x00\x00\x08")
+	xmp := segment(0xE1, "http://ns.adobe.com/xap/1.0/\x00<x/>")
+	icc := segment(0xE2, "ICC_PROFILE\x00")
+
+	tests := []struct {
+		name string
+		in   []byte
+		want []byte
+	}{
+		{"after JFIF", jpegOf(jfif, icc), jpegOf(jfif, exif, icc)},
+		{"without APPn segments", jpegOf(), jpegOf(exif)},
+		{"replaces old EXIF", jpegOf(oldExif, xmp), jpegOf(exif, xmp)},
+		{"before other APPn", jpegOf(xmp, icc), jpegOf(exif, xmp, icc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InsertJPEGExif(tt.in, testTIFF)
+			if err != nil {
+				t.Fatalf("InsertJPEGExif() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("InsertJPEGExif() = %x\nwant %x", got, tt.want)
+			}
+			if !bytes.Equal(jpegExif(got), testTIFF) {
+				t.Errorf("inserted EXIF does not read back")
+			}
+		})
+	}
+}
+
+func TestInsertJPEGExifErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		jpeg []byte
+		tiff []byte
+	}{
+		{"not a JPEG", []byte("\x89PNG\r\n\x1a\n"), testTIFF},
+		{"not TIFF", jpegOf(), []byte("Exif\x00\x00")},
+		{"truncated segment", append([]byte{0xFF, 0xD8}, segment(0xE0, "JFIF")[:5]...), testTIFF},
+		{"segment length below its header", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x00, 0xFF, 0xD9}, testTIFF},
+		{"EXIF too large", jpegOf(), append(append([]byte{}, testTIFF...), make([]byte, 0xFFFF)...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := InsertJPEGExif(tt.jpeg, tt.tiff); err == nil {
+				t.Error("InsertJPEGExif() succeeded, want an error")
+			}
+		})
+	}
+
+	large := append(append([]byte{}, testTIFF...), make([]byte, 0xFFFF)...)
+	if _, err := InsertJPEGExif(jpegOf(), large); !errors.Is(err, ErrExifTooLarge) {
+		t.Errorf("oversized EXIF error = %v, want ErrExifTooLarge", err)
+	}
+}
+
+func TestSetExifOrientation(t *testing.T) {
+	// Big-endian variant of testTIFF
+	bigEndian := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8,
+		0, 1,
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, 3, 0, 0,
+		0, 0, 0, 0,
+	}
+	noOrientation := append([]byte{}, testTIFF...)
+	binary.LittleEndian.PutUint16(noOrientation[10:], 0x010F) // Make
+
+	tests := []struct {
+		name string
+		tiff []byte
+		want int
+	}{
+		{"little endian", testTIFF, 1},
+		{"big endian", bigEndian, 1},
+		{"no orientation tag", noOrientation, 0},
+		{"IFD offset past the end", append([]byte{'I', 'I', 42, 0, 0xFF, 0, 0, 0}, testTIFF[8:]...), 0},
+		{"oversized entry count", append([]byte{'I', 'I', 42, 0, 8, 0, 0, 0, 0xFF, 0xFF}, noOrientation[10:]...), 0},
+		{"truncated entry", testTIFF[:16], 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := append([]byte{}, tt.tiff...)
+			got, err := SetExifOrientation(tt.tiff, 1)
+			if err != nil {
+				t.Fatalf("SetExifOrientation() error = %v", err)
+			}
+			if !bytes.Equal(tt.tiff, orig) {
+				t.Error("SetExifOrientation() modified its input")
+			}
+			if o := ExifOrientation(got); o != tt.want {
+				t.Errorf("orientation = %d, want %d", o, tt.want)
+			}
+			if tt.want == 0 && !bytes.Equal(got, orig) {
+				t.Error("block without an orientation tag was changed")
+			}
+		})
+	}
+
+	if _, err := SetExifOrientation([]byte("not tiff"), 1); err == nil {
+		t.Error("SetExifOrientation() of a non-TIFF block succeeded")
+	}
+}
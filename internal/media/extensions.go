@@ -0,0 +1,29 @@
+package media
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Known media file extensions by kind
+var (
+	VideoExtensions = []string{".mov", ".mp4", ".m4v", ".avi", ".mkv", ".flv", ".wmv", ".mpeg", ".mpg", ".webm", ".ts", ".mts", ".m2ts", ".3gp"}
+	AudioExtensions = []string{".mp3", ".m4a", ".aac", ".wav", ".flac", ".ogg", ".opus", ".wma", ".aiff", ".aif"}
+	ImageExtensions = []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".heic", ".heif", ".tif", ".tiff", ".bmp"}
+)
+
+// VideoInputs are the video formats the ffmpeg video converters accept
+var VideoInputs = []string{".mov", ".avi", ".mkv", ".flv", ".wmv", ".m4v", ".mpeg", ".mpg", ".webm", ".mp4"}
+
+// HasExtension reports whether path ends with one of the given extensions (case-insensitive)
+func HasExtension(path string, exts []string) bool {
+	return slices.Contains(exts, strings.ToLower(filepath.Ext(path)))
+}
+
+// IsMediaFile reports whether path has a known video, audio or image extension
+func IsMediaFile(path string) bool {
+	return HasExtension(path, VideoExtensions) ||
+		HasExtension(path, AudioExtensions) ||
+		HasExtension(path, ImageExtensions)
+}
@@ -0,0 +1,179 @@
+package media
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field extracts a single named value from a MediaInfo for tabular output
+type Field struct {
+	Name   string
+	Header string
+	Value  func(*MediaInfo) any
+}
+
+// DefaultFields is the field selection used when none is specified
+var DefaultFields = []string{
+	"path", "container", "duration", "size",
+	"video.codec", "width", "height", "fps",
+	"audio.codec", "audio.channels",
+}
+
+// fields lists every selectable field in display order
+var fields = []Field{
+	{"path", "FILE", func(m *MediaInfo) any { return m.Path }},
+	{"container", "CONTAINER", func(m *MediaInfo) any { return m.Format.Name }},
+	{"duration", "DURATION", func(m *MediaInfo) any { return m.Format.Duration }},
+	{"size", "SIZE", func(m *MediaInfo) any { return m.Format.Size }},
+	{"bitrate", "BITRATE", func(m *MediaInfo) any { return m.Format.Bitrate }},
+	{"streams", "STREAMS", func(m *MediaInfo) any { return m.Format.StreamCount }},
+	{"video.codec", "VCODEC", videoField(func(v *VideoStream) any { return v.Codec })},
+	{"video.profile", "VPROFILE", videoField(func(v *VideoStream) any { return v.Profile })},
+	{"video.width", "WIDTH", videoField(func(v *VideoStream) any { return v.Width })},
+	{"video.height", "HEIGHT", videoField(func(v *VideoStream) any { return v.Height })},
+	{"video.fps", "FPS", videoField(func(v *VideoStream) any { return v.FrameRate })},
+	{"video.pix_fmt", "PIX_FMT", videoField(func(v *VideoStream) any { return v.PixFmt })},
+	{"video.bit_depth", "DEPTH", videoField(func(v *VideoStream) any { return v.BitDepth })},
+	{"video.bitrate", "VBITRATE", videoField(func(v *VideoStream) any { return v.Bitrate })},
+	{"video.rotation", "ROTATION", videoField(func(v *VideoStream) any { return v.Rotation })},
+	{"video.hdr", "HDR", videoField(func(v *VideoStream) any {
+		if v.HDR == nil {
+			return ""
+		}
+		return v.HDR.Format
+	})},
+	{"audio.codec", "ACODEC", audioField(func(a *AudioStream) any { return a.Codec })},
+	{"audio.profile", "APROFILE", audioField(func(a *AudioStream) any { return a.Profile })},
+	{"audio.channels", "CH", audioField(func(a *AudioStream) any { return a.Channels })},
+	{"audio.layout", "LAYOUT", audioField(func(a *AudioStream) any { return a.ChannelLayout })},
+	{"audio.sample_rate", "RATE", audioField(func(a *AudioStream) any { return a.SampleRate })},
+	{"audio.bitrate", "ABITRATE", audioField(func(a *AudioStream) any { return a.Bitrate })},
+	{"audio.language", "ALANG", audioField(func(a *AudioStream) any { return a.Language })},
+	{"audio.tracks", "ATRACKS", func(m *MediaInfo) any { return len(m.Audio) }},
+	{"subtitles", "SUBS", func(m *MediaInfo) any { return len(m.Subtitles) }},
+}
+
+// fieldAliases maps short names to their canonical field names
+var fieldAliases = map[string]string{
+	"file":        "path",
+	"format":      "container",
+	"codec":       "video.codec",
+	"width":       "video.width",
+	"height":      "video.height",
+	"fps":         "video.fps",
+	"pix_fmt":     "video.pix_fmt",
+	"rotation":    "video.rotation",
+	"hdr":         "video.hdr",
+	"channels":    "audio.channels",
+	"sample_rate": "audio.sample_rate",
+	"language":    "audio.language",
+}
+
+// LookupField returns the field with the given name or alias
+func LookupField(name string) (Field, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if canonical, ok := fieldAliases[name]; ok {
+		name = canonical
+	}
+
+	for _, f := range fields {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+
+	return Field{}, fmt.Errorf("unknown field %q (available: %s)", name, strings.Join(FieldNames(), ", "))
+}
+
+// LookupFields resolves a list of field names, preserving order
+func LookupFields(names []string) ([]Field, error) {
+	result := make([]Field, 0, len(names))
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		f, err := LookupField(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, f)
+	}
+	return result, nil
+}
+
+// FieldNames returns all canonical field names and aliases, sorted
+func FieldNames() []string {
+	names := make([]string, 0, len(fields)+len(fieldAliases))
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+	for alias := range fieldAliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatValue renders a field value for human-readable output
+func FormatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case Duration:
+		return formatClock(val.Std())
+	case float64:
+		if val == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(math.Round(val*1000)/1000, 'f', -1, 64)
+	case int:
+		if val == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d", val)
+	case int64:
+		if val == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d", val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// videoField adapts an accessor on the primary video stream
+func videoField(fn func(*VideoStream) any) func(*MediaInfo) any {
+	return func(m *MediaInfo) any {
+		if v := m.PrimaryVideo(); v != nil {
+			return fn(v)
+		}
+		return nil
+	}
+}
+
+// audioField adapts an accessor on the primary audio stream
+func audioField(fn func(*AudioStream) any) func(*MediaInfo) any {
+	return func(m *MediaInfo) any {
+		if a := m.PrimaryAudio(); a != nil {
+			return fn(a)
+		}
+		return nil
+	}
+}
+
+// formatClock formats a duration as H:MM:SS.s
+func formatClock(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	// Round first so that, e.g., 59.95s carries into the minutes
+	d = d.Round(100 * time.Millisecond)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	s := (d % time.Minute).Seconds()
+	return fmt.Sprintf("%d:%02d:%04.1f", h, m, s)
+}
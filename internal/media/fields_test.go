@@ -0,0 +1,27 @@
+package media
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatClock(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, ""},
+		{40 * time.Millisecond, "0:00:00.0"},
+		{1500 * time.Millisecond, "0:00:01.5"},
+		{59940 * time.Millisecond, "0:00:59.9"},
+		{59950 * time.Millisecond, "0:01:00.0"},
+		{time.Hour - 50*time.Millisecond, "1:00:00.0"},
+		{time.Hour + 2*time.Minute + 3250*time.Millisecond, "1:02:03.3"},
+	}
+
+	for _, tt := range tests {
+		if got := formatClock(tt.in); got != tt.want {
+			t.Errorf("formatClock(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,597 @@
+package media
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// HEIF describes the item structure of a HEIF/HEIC file: which image is the
+// primary one, how it is tiled, and its EXIF metadata
+type HEIF struct {
+	PrimaryID uint32
+	Items     []HEIFItem
+	Exif      []byte // TIFF-structured EXIF of the primary image (nil if none)
+}
+
+// HEIFItem is an item of a HEIF file
+type HEIFItem struct {
+	ID       uint32
+	Type     string   // e.g., "hvc1", "av01", "grid", "iden", "Exif"
+	Hidden   bool     // not meant to be displayed on its own
+	Tiles    []uint32 // image items composed by a grid ("dimg" references)
+	ThumbOf  uint32   // item this is a thumbnail of (0 = none)
+	AuxOf    uint32   // item this is an auxiliary image of, e.g., depth or alpha (0 = none)
+	Width    int      // from the ispe property
+	Height   int      // from the ispe property
+	Rotation int      // counter-clockwise degrees from the irot property
+	Mirror   bool     // an imir property is present
+}
+
+// IsImage reports whether the item is a coded or derived image
+func (it HEIFItem) IsImage() bool {
+	switch it.Type {
+	case "hvc1", "av01", "jpeg", "grid", "iden", "iovl", "unci":
+		return true
+	}
+	return false
+}
+
+// Primary returns the primary image item
+func (h *HEIF) Primary() (HEIFItem, bool) {
+	return h.Item(h.PrimaryID)
+}
+
+// Item returns the item with the given ID
+func (h *HEIF) Item(id uint32) (HEIFItem, bool) {
+	for _, it := range h.Items {
+		if it.ID == id {
+			return it, true
+		}
+	}
+	return HEIFItem{}, false
+}
+
+// Images returns the top-level images: image items that are not grid tiles,
+// thumbnails, auxiliary images or hidden. A burst holds several of them.
+func (h *HEIF) Images() []HEIFItem {
+	tiles := make(map[uint32]bool)
+	for _, it := range h.Items {
+		for _, id := range it.Tiles {
+			tiles[id] = true
+		}
+	}
+
+	images := []HEIFItem{}
+	for _, it := range h.Items {
+		if it.IsImage() && !it.Hidden && !tiles[it.ID] && it.ThumbOf == 0 && it.AuxOf == 0 {
+			images = append(images, it)
+		}
+	}
+	return images
+}
+
+// ReadHEIF parses the item structure of the HEIF file at path
+func ReadHEIF(path string) (*HEIF, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return ParseHEIF(f, info.Size())
+}
+
+// errBadHEIF reports a malformed HEIF structure
+var errBadHEIF = errors.New("malformed HEIF file")
+
+// maxMetaSize bounds the meta box read into memory
+const maxMetaSize = 16 << 20
+
+// ParseHEIF parses the item structure of a HEIF file of the given size
+func ParseHEIF(r io.ReaderAt, size int64) (*HEIF, error) {
+	// Locate the top-level meta box
+	var meta []byte
+scan:
+	for off := int64(0); off < size; {
+		hdr := make([]byte, 16)
+		if _, err := r.ReadAt(hdr[:8], off); err != nil {
+			return nil, fmt.Errorf("%w: %v", errBadHEIF, err)
+		}
+		boxSize := int64(binary.BigEndian.Uint32(hdr[:4]))
+		boxType := string(hdr[4:8])
+		headerLen := int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = size - off
+		case 1:
+			if _, err := r.ReadAt(hdr[8:16], off+8); err != nil {
+				return nil, fmt.Errorf("%w: %v", errBadHEIF, err)
+			}
+			boxSize = int64(binary.BigEndian.Uint64(hdr[8:16]))
+			headerLen = 16
+		}
+		if boxSize < headerLen || off+boxSize > size {
+			return nil, fmt.Errorf("%w: box %q overruns the file", errBadHEIF, boxType)
+		}
+
+		if boxType == "meta" {
+			if boxSize-headerLen > maxMetaSize {
+				return nil, fmt.Errorf("%w: meta box too large", errBadHEIF)
+			}
+			meta = make([]byte, boxSize-headerLen)
+			if _, err := r.ReadAt(meta, off+headerLen); err != nil {
+				return nil, fmt.Errorf("%w: %v", errBadHEIF, err)
+			}
+			break scan
+		}
+		off += boxSize
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("%w: no meta box", errBadHEIF)
+	}
+	if len(meta) < 4 {
+		return nil, errBadHEIF
+	}
+
+	p := &heifParser{heif: &HEIF{}, props: [][]byte{nil}}
+	if err := p.parseMeta(meta[4:]); err != nil {
+		return nil, err
+	}
+	p.resolve()
+
+	if exif, ok := p.exifFor(p.heif.PrimaryID); ok {
+		data, err := p.readItem(r, size, exif)
+		if err == nil {
+			p.heif.Exif = exifPayload(data)
+		}
+	}
+
+	return p.heif, nil
+}
+
+// heifParser collects the boxes of a meta box
+type heifParser struct {
+	heif      *HEIF
+	locations map[uint32]itemLocation
+	idat      []byte
+	props     [][]byte            // ipco property boxes (type + payload), 1-based
+	propTypes []string            // types of props, parallel to props
+	assoc     map[uint32][]int    // property indices by item
+	refs      []itemReference     // iref entries
+	cdsc      map[uint32][]uint32 // metadata items describing an item
+}
+
+// itemLocation is an iloc entry
+type itemLocation struct {
+	method  int // 0 = file offset, 1 = idat offset
+	base    uint64
+	extents [][2]uint64 // offset, length
+}
+
+// itemReference is an iref entry
+type itemReference struct {
+	kind string
+	from uint32
+	to   []uint32
+}
+
+// parseMeta walks the children of the meta box
+func (p *heifParser) parseMeta(b []byte) error {
+	return walkBoxes(b, func(typ string, body []byte) error {
+		switch typ {
+		case "pitm":
+			return p.parsePitm(body)
+		case "iinf":
+			return p.parseIinf(body)
+		case "iloc":
+			return p.parseIloc(body)
+		case "iref":
+			return p.parseIref(body)
+		case "idat":
+			p.idat = body
+		case "iprp":
+			return walkBoxes(body, func(typ string, body []byte) error {
+				switch typ {
+				case "ipco":
+					return walkBoxes(body, func(typ string, body []byte) error {
+						p.props = append(p.props, body)
+						p.propTypes = append(p.propTypes, typ)
+						return nil
+					})
+				case "ipma":
+					return p.parseIpma(body)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+}
+
+func (p *heifParser) parsePitm(b []byte) error {
+	rd := &byteReader{b: b}
+	version := rd.u8()
+	rd.skip(3)
+	if version == 0 {
+		p.heif.PrimaryID = uint32(rd.u16())
+	} else {
+		p.heif.PrimaryID = rd.u32()
+	}
+	return rd.err
+}
+
+func (p *heifParser) parseIinf(b []byte) error {
+	rd := &byteReader{b: b}
+	version := rd.u8()
+	rd.skip(3)
+	if version == 0 {
+		rd.u16()
+	} else {
+		rd.u32()
+	}
+	if rd.err != nil {
+		return rd.err
+	}
+
+	return walkBoxes(b[rd.pos:], func(typ string, body []byte) error {
+		if typ != "infe" {
+			return nil
+		}
+		rd := &byteReader{b: body}
+		version := rd.u8()
+		flags := uint32(rd.u8())<<16 | uint32(rd.u16())
+		if version < 2 {
+			return nil // pre-HEIF item info without item types
+		}
+		it := HEIFItem{Hidden: flags&1 != 0}
+		if version == 2 {
+			it.ID = uint32(rd.u16())
+		} else {
+			it.ID = rd.u32()
+		}
+		rd.u16() // protection index
+		it.Type = string(rd.bytes(4))
+		if rd.err != nil {
+			return rd.err
+		}
+		p.heif.Items = append(p.heif.Items, it)
+		return nil
+	})
+}
+
+func (p *heifParser) parseIloc(b []byte) error {
+	rd := &byteReader{b: b}
+	version := rd.u8()
+	rd.skip(3)
+	sizes := rd.u16()
+	offsetSize := int(sizes >> 12)
+	lengthSize := int(sizes >> 8 & 0x0F)
+	baseSize := int(sizes >> 4 & 0x0F)
+	indexSize := 0
+	if version == 1 || version == 2 {
+		indexSize = int(sizes & 0x0F)
+	}
+
+	idSize := 2
+	count := uint32(0)
+	if version < 2 {
+		count = uint32(rd.u16())
+	} else {
+		idSize = 4
+		count = rd.u32()
+	}
+
+	// Each entry holds at least its ID, method, data reference, base offset
+	// and extent count
+	minEntry := idSize + 2 + baseSize + 2
+	if version == 1 || version == 2 {
+		minEntry += 2
+	}
+	if uint64(count) > uint64(len(b)-rd.pos)/uint64(minEntry) {
+		return fmt.Errorf("%w: iloc count %d overruns the box", errBadHEIF, count)
+	}
+
+	p.locations = make(map[uint32]itemLocation, count)
+	for i := uint32(0); i < count && rd.err == nil; i++ {
+		var id uint32
+		if version < 2 {
+			id = uint32(rd.u16())
+		} else {
+			id = rd.u32()
+		}
+		loc := itemLocation{}
+		if version == 1 || version == 2 {
+			loc.method = int(rd.u16() & 0x0F)
+		}
+		rd.u16() // data reference index
+		loc.base = rd.uint(baseSize)
+		extents := int(rd.u16())
+		for e := 0; e < extents && rd.err == nil; e++ {
+			if indexSize > 0 {
+				rd.uint(indexSize)
+			}
+			offset := rd.uint(offsetSize)
+			length := rd.uint(lengthSize)
+			loc.extents = append(loc.extents, [2]uint64{offset, length})
+		}
+		p.locations[id] = loc
+	}
+	return rd.err
+}
+
+func (p *heifParser) parseIref(b []byte) error {
+	rd := &byteReader{b: b}
+	version := rd.u8()
+	rd.skip(3)
+	if rd.err != nil {
+		return rd.err
+	}
+
+	return walkBoxes(b[rd.pos:], func(typ string, body []byte) error {
+		rd := &byteReader{b: body}
+		ref := itemReference{kind: typ}
+		if version == 0 {
+			ref.from = uint32(rd.u16())
+		} else {
+			ref.from = rd.u32()
+		}
+		n := int(rd.u16())
+		for i := 0; i < n && rd.err == nil; i++ {
+			if version == 0 {
+				ref.to = append(ref.to, uint32(rd.u16()))
+			} else {
+				ref.to = append(ref.to, rd.u32())
+			}
+		}
+		p.refs = append(p.refs, ref)
+		return rd.err
+	})
+}
+
+func (p *heifParser) parseIpma(b []byte) error {
+	rd := &byteReader{b: b}
+	version := rd.u8()
+	flags := uint32(rd.u8())<<16 | uint32(rd.u16())
+	count := rd.u32()
+
+	// Each entry holds at least its ID and association count
+	minEntry := 3
+	if version >= 1 {
+		minEntry = 5
+	}
+	if rd.err == nil && uint64(count) > uint64(len(b)-rd.pos)/uint64(minEntry) {
+		return fmt.Errorf("%w: ipma count %d overruns the box", errBadHEIF, count)
+	}
+
+	p.assoc = make(map[uint32][]int, count)
+	for i := uint32(0); i < count && rd.err == nil; i++ {
+		var id uint32
+		if version < 1 {
+			id = uint32(rd.u16())
+		} else {
+			id = rd.u32()
+		}
+		n := int(rd.u8())
+		for a := 0; a < n && rd.err == nil; a++ {
+			var index int
+			if flags&1 != 0 {
+				index = int(rd.u16() & 0x7FFF)
+			} else {
+				index = int(rd.u8() & 0x7F)
+			}
+			p.assoc[id] = append(p.assoc[id], index)
+		}
+	}
+	return rd.err
+}
+
+// resolve applies references and properties to the items
+func (p *heifParser) resolve() {
+	p.cdsc = make(map[uint32][]uint32)
+	index := make(map[uint32]int, len(p.heif.Items))
+	for i, it := range p.heif.Items {
+		index[it.ID] = i
+	}
+
+	for _, ref := range p.refs {
+		switch ref.kind {
+		case "dimg":
+			if i, ok := index[ref.from]; ok {
+				p.heif.Items[i].Tiles = append(p.heif.Items[i].Tiles, ref.to...)
+			}
+		case "thmb", "auxl":
+			if i, ok := index[ref.from]; ok && len(ref.to) > 0 {
+				if ref.kind == "thmb" {
+					p.heif.Items[i].ThumbOf = ref.to[0]
+				} else {
+					p.heif.Items[i].AuxOf = ref.to[0]
+				}
+			}
+		case "cdsc":
+			for _, to := range ref.to {
+				p.cdsc[to] = append(p.cdsc[to], ref.from)
+			}
+		}
+	}
+
+	for i := range p.heif.Items {
+		it := &p.heif.Items[i]
+		for _, pi := range p.assoc[it.ID] {
+			if pi <= 0 || pi >= len(p.props) {
+				continue
+			}
+			body := p.props[pi]
+			switch p.propTypes[pi-1] {
+			case "ispe":
+				if len(body) >= 12 {
+					it.Width = int(binary.BigEndian.Uint32(body[4:8]))
+					it.Height = int(binary.BigEndian.Uint32(body[8:12]))
+				}
+			case "irot":
+				if len(body) >= 1 {
+					it.Rotation = int(body[0]&0x03) * 90
+				}
+			case "imir":
+				it.Mirror = true
+			}
+		}
+	}
+}
+
+// exifFor returns the Exif item describing the image id, falling back to
+// the only Exif item of the file
+func (p *heifParser) exifFor(id uint32) (uint32, bool) {
+	var exifs []uint32
+	for _, it := range p.heif.Items {
+		if it.Type == "Exif" {
+			exifs = append(exifs, it.ID)
+		}
+	}
+	for _, from := range p.cdsc[id] {
+		for _, e := range exifs {
+			if e == from {
+				return e, true
+			}
+		}
+	}
+	if len(exifs) == 1 {
+		return exifs[0], true
+	}
+	return 0, false
+}
+
+// readItem returns the data of an item
+func (p *heifParser) readItem(r io.ReaderAt, size int64, id uint32) ([]byte, error) {
+	loc, ok := p.locations[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: item %d has no location", errBadHEIF, id)
+	}
+
+	var limit uint64
+	switch loc.method {
+	case 0:
+		limit = uint64(size)
+	case 1:
+		limit = uint64(len(p.idat))
+	default:
+		return nil, fmt.Errorf("unsupported construction method %d for item %d", loc.method, id)
+	}
+
+	// Compare by subtraction so that corrupt offsets cannot wrap around
+	var data []byte
+	for _, ext := range loc.extents {
+		if loc.base > limit || ext[0] > limit-loc.base {
+			return nil, fmt.Errorf("%w: item %d overruns its data", errBadHEIF, id)
+		}
+		offset, length := loc.base+ext[0], ext[1]
+		if length == 0 || length > limit-offset {
+			return nil, fmt.Errorf("%w: item %d overruns its data", errBadHEIF, id)
+		}
+		if length > maxMetaSize-uint64(len(data)) {
+			return nil, fmt.Errorf("%w: item %d too large", errBadHEIF, id)
+		}
+
+		if loc.method == 1 {
+			data = append(data, p.idat[offset:offset+length]...)
+			continue
+		}
+		buf := make([]byte, length)
+		if _, err := r.ReadAt(buf, int64(offset)); err != nil {
+			return nil, err
+		}
+		data = append(data, buf...)
+	}
+	return data, nil
+}
+
+// exifPayload strips the HEIF Exif item header (a 4-byte offset to the TIFF
+// header) and returns the TIFF structure, or nil if it is malformed
+func exifPayload(data []byte) []byte {
+	if len(data) < 4 {
+		return nil
+	}
+	start := 4 + int(binary.BigEndian.Uint32(data[:4]))
+	if start >= len(data) {
+		return nil
+	}
+	tiff := data[start:]
+	if !isTIFFHeader(tiff) {
+		return nil
+	}
+	return tiff
+}
+
+// walkBoxes calls fn for each box in b
+func walkBoxes(b []byte, fn func(typ string, body []byte) error) error {
+	for len(b) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(b[:4]))
+		typ := string(b[4:8])
+		header := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(b))
+		case 1:
+			if len(b) < 16 {
+				return errBadHEIF
+			}
+			size = binary.BigEndian.Uint64(b[8:16])
+			header = 16
+		}
+		if size < header || size > uint64(len(b)) {
+			return fmt.Errorf("%w: box %q overruns its parent", errBadHEIF, typ)
+		}
+		if err := fn(typ, b[header:size]); err != nil {
+			return err
+		}
+		b = b[size:]
+	}
+	return nil
+}
+
+// byteReader reads big-endian integers, recording the first overrun
+type byteReader struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (r *byteReader) bytes(n int) []byte {
+	if r.err != nil || r.pos+n > len(r.b) {
+		r.err = errBadHEIF
+		return make([]byte, n)
+	}
+	v := r.b[r.pos : r.pos+n]
+	r.pos += n
+	return v
+}
+
+func (r *byteReader) skip(n int) { r.bytes(n) }
+func (r *byteReader) u8() uint8  { return r.bytes(1)[0] }
+func (r *byteReader) u16() uint16 {
+	return binary.BigEndian.Uint16(r.bytes(2))
+}
+func (r *byteReader) u32() uint32 {
+	return binary.BigEndian.Uint32(r.bytes(4))
+}
+
+// uint reads an unsigned integer of 0, 4 or 8 bytes
+func (r *byteReader) uint(n int) uint64 {
+	switch n {
+	case 0:
+		return 0
+	case 4:
+		return uint64(r.u32())
+	case 8:
+		return binary.BigEndian.Uint64(r.bytes(8))
+	default:
+		r.err = fmt.Errorf("%w: unsupported field size %d", errBadHEIF, n)
+		return 0
+	}
+}
@@ -0,0 +1,203 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// box returns an ISO BMFF box of the given type holding the parts
+func box(typ string, parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(b, typ...), body...)
+}
+
+// fullBox returns a box with a version and zero flags
+func fullBox(typ string, version byte, parts ...[]byte) []byte {
+	return box(typ, append([][]byte{{version, 0, 0, 0}}, parts...)...)
+}
+
+func u16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func u32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+func u64(v uint64) []byte { return binary.BigEndian.AppendUint64(nil, v) }
+
+// infe returns a version 2 item info entry
+func infe(id uint16, typ string, hidden bool) []byte {
+	flags := byte(0)
+	if hidden {
+		flags = 1
+	}
+	return box("infe", []byte{2, 0, 0, flags}, u16(id), u16(0), []byte(typ), []byte{0})
+}
+
+// testTIFF is a little-endian EXIF block whose IFD0 holds an orientation
+var testTIFF = []byte{
+	'I', 'I', 42, 0, 8, 0, 0, 0, // header, IFD0 at 8
+	1, 0, // one entry
+	0x12, 0x01, 3, 0, 1, 0, 0, 0, 6, 0, 0, 0, // orientation (SHORT) = 6
+	0, 0, 0, 0, // no next IFD
+}
+
+// exifItem is the HEIF Exif item: a 4-byte offset to the TIFF header, then EXIF
+var exifItem = append(u32(0), testTIFF...)
+
+// heicFile builds a HEIC file with a 2x2 grid primary image (item 1, tiles
+// 2-5), a thumbnail (6) and an Exif item (7) stored by iloc
+func heicFile(iloc []byte, idat []byte) []byte {
+	items := [][]byte{u16(7), infe(1, "grid", false)}
+	for id := uint16(2); id <= 5; id++ {
+		items = append(items, infe(id, "hvc1", true))
+	}
+	items = append(items, infe(6, "hvc1", false), infe(7, "Exif", false))
+
+	meta := fullBox("meta", 0,
+		box("hdlr", make([]byte, 24)),
+		fullBox("pitm", 0, u16(1)),
+		fullBox("iinf", 0, items...),
+		iloc,
+		fullBox("iref", 0,
+			box("dimg", u16(1), u16(4), u16(2), u16(3), u16(4), u16(5)),
+			box("thmb", u16(6), u16(1), u16(1)),
+			box("cdsc", u16(7), u16(1), u16(1)),
+		),
+		box("iprp",
+			box("ipco",
+				fullBox("ispe", 0, u32(4032), u32(3024)),
+				box("irot", []byte{1}),
+				box("imir", []byte{0}),
+			),
+			fullBox("ipma", 0, u32(1), u16(1), []byte{3, 0x81, 0x82, 0x03}),
+		),
+	)
+	if idat != nil {
+		meta = append(meta[:0:0], meta...)
+		meta = append(meta, box("idat", idat)...)
+		binary.BigEndian.PutUint32(meta, uint32(len(meta)))
+	}
+	return append(box("ftyp", []byte("heicmif1heic")), meta...)
+}
+
+// idatIloc locates item 7 at the start of idat (construction method 1)
+func idatIloc(offset, length uint32) []byte {
+	return fullBox("iloc", 1, u16(0x4400), u16(1),
+		u16(7), u16(1), u16(0), u16(1), u32(offset), u32(length))
+}
+
+func TestParseHEIF(t *testing.T) {
+	data := heicFile(idatIloc(0, uint32(len(exifItem))), exifItem)
+
+	h, err := ParseHEIF(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("ParseHEIF() error = %v", err)
+	}
+
+	primary, ok := h.Primary()
+	if !ok {
+		t.Fatal("no primary item")
+	}
+	if primary.Type != "grid" || len(primary.Tiles) != 4 {
+		t.Errorf("primary = %+v, want a grid of 4 tiles", primary)
+	}
+	if primary.Width != 4032 || primary.Height != 3024 || primary.Rotation != 90 || !primary.Mirror {
+		t.Errorf("primary properties = %dx%d rotation %d mirror %v", primary.Width, primary.Height, primary.Rotation, primary.Mirror)
+	}
+
+	images := h.Images()
+	if len(images) != 1 || images[0].ID != 1 {
+		t.Errorf("Images() = %+v, want only the primary grid", images)
+	}
+	if thumb, _ := h.Item(6); thumb.ThumbOf != 1 {
+		t.Errorf("thumbnail ThumbOf = %d, want 1", thumb.ThumbOf)
+	}
+
+	if !bytes.Equal(h.Exif, testTIFF) {
+		t.Errorf("Exif = %x, want %x", h.Exif, testTIFF)
+	}
+	if got := ExifOrientation(h.Exif); got != 6 {
+		t.Errorf("ExifOrientation() = %d, want 6", got)
+	}
+}
+
+func TestParseHEIFFileOffsetExif(t *testing.T) {
+	// Construction method 0: the Exif item follows the meta box in mdat
+	build := func(offset uint32) []byte {
+		iloc := fullBox("iloc", 0, u16(0x4400), u16(1),
+			u16(7), u16(0), u16(1), u32(offset), u32(uint32(len(exifItem))))
+		return heicFile(iloc, nil)
+	}
+	offset := uint32(len(build(0)) + 8)
+	data := append(build(offset), box("mdat", exifItem)...)
+
+	h, err := ParseHEIF(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(h.Exif, testTIFF) {
+		t.Errorf("Exif = %x, want %x", h.Exif, testTIFF)
+	}
+}
+
+func TestParseHEIFMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"no meta box", box("ftyp", []byte("heicmif1"))},
+		{"box overruns the file", append(u32(1000), "meta"...)},
+		{"oversized iloc count", heicFile(fullBox("iloc", 2, u16(0x4400), u32(0xFFFFFFFF)), nil)},
+		{"oversized ipma count", box("meta", u32(0),
+			box("iprp", fullBox("ipma", 1, u32(0xFFFFFFFF), u32(1))))},
+		{"truncated iloc", heicFile(fullBox("iloc", 1, u16(0x4400), u16(1), u16(7)), nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseHEIF(bytes.NewReader(tt.data), int64(len(tt.data))); !errors.Is(err, errBadHEIF) {
+				t.Errorf("ParseHEIF() error = %v, want errBadHEIF", err)
+			}
+		})
+	}
+}
+
+func TestParseHEIFTruncated(t *testing.T) {
+	data := heicFile(idatIloc(0, uint32(len(exifItem))), exifItem)
+	for n := 0; n < len(data); n++ {
+		if _, err := ParseHEIF(bytes.NewReader(data[:n]), int64(n)); err == nil {
+			t.Fatalf("ParseHEIF() of %d/%d bytes succeeded", n, len(data))
+		}
+	}
+}
+
+func TestParseHEIFBadExifLocation(t *testing.T) {
+	// Broken Exif locations drop the EXIF block without failing the parse
+	wide := func(method uint16, base, offset, length uint64) []byte {
+		return fullBox("iloc", 1, u16(0x8880), u16(1),
+			u16(7), u16(method), u16(0), u64(base), u16(1), u64(offset), u64(length))
+	}
+	tests := []struct {
+		name string
+		iloc []byte
+	}{
+		{"idat offset wraps around", wide(1, 0, 0xFFFFFFFFFFFFFFF0, 0x20)},
+		{"idat base wraps around", wide(1, 0xFFFFFFFFFFFFFFF0, 0x10, 0x08)},
+		{"idat length past the end", wide(1, 0, 4, uint64(len(exifItem)))},
+		{"file offset wraps around", wide(0, 0xFFFFFFFFFFFFFFFF, 2, 0x10)},
+		{"file length past the end", wide(0, 0, 0, 1<<40)},
+		{"unsupported method", wide(2, 0, 0, 4)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := heicFile(tt.iloc, exifItem)
+			h, err := ParseHEIF(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatalf("ParseHEIF() error = %v", err)
+			}
+			if h.Exif != nil {
+				t.Errorf("Exif = %x, want nil", h.Exif)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package media
+
+import (
+	"strconv"
+	"time"
+)
+
+// MediaInfo describes a media file as reported by ffprobe
+type MediaInfo struct {
+	Path      string           `json:"path"`
+	Format    Format           `json:"format"`
+	Video     []VideoStream    `json:"video,omitempty"`
+	Audio     []AudioStream    `json:"audio,omitempty"`
+	Subtitles []SubtitleStream `json:"subtitles,omitempty"`
+}
+
+// Format contains container-level information
+type Format struct {
+	Name        string            `json:"name"`      // e.g., "mov,mp4,m4a,3gp,3g2,mj2"
+	LongName    string            `json:"long_name"` // e.g., "QuickTime / MOV"
+	Duration    Duration          `json:"duration"`
+	StartTime   Duration          `json:"start_time"`
+	Size        int64             `json:"size"`
+	Bitrate     int64             `json:"bitrate"` // bits per second
+	StreamCount int               `json:"stream_count"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// Stream contains properties shared by all stream types
+type Stream struct {
+	Index         int               `json:"index"`
+	Codec         string            `json:"codec"`
+	CodecLongName string            `json:"codec_long_name,omitempty"`
+	CodecTag      string            `json:"codec_tag,omitempty"`
+	Profile       string            `json:"profile,omitempty"`
+	Bitrate       int64             `json:"bitrate,omitempty"`
+	Duration      Duration          `json:"duration,omitempty"`
+	Language      string            `json:"language,omitempty"`
+	Title         string            `json:"title,omitempty"`
+	Default       bool              `json:"default"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+// VideoStream describes a video stream
+type VideoStream struct {
+	Stream
+	Width          int      `json:"width"`
+	Height         int      `json:"height"`
+	PixFmt         string   `json:"pix_fmt,omitempty"`
+	BitDepth       int      `json:"bit_depth,omitempty"`
+	FrameRate      float64  `json:"frame_rate"`     // r_frame_rate
+	AvgFrameRate   float64  `json:"avg_frame_rate"` // avg_frame_rate
+	FrameCount     int64    `json:"frame_count,omitempty"`
+	Rotation       int      `json:"rotation"` // clockwise degrees needed for display (0, 90, 180, 270)
+	FieldOrder     string   `json:"field_order,omitempty"`
+	ColorRange     string   `json:"color_range,omitempty"`
+	ColorSpace     string   `json:"color_space,omitempty"`
+	ColorTransfer  string   `json:"color_transfer,omitempty"`
+	ColorPrimaries string   `json:"color_primaries,omitempty"`
+	HDR            *HDRInfo `json:"hdr,omitempty"`
+}
+
+// AudioStream describes an audio stream
+type AudioStream struct {
+	Stream
+	SampleRate    int    `json:"sample_rate"`
+	Channels      int    `json:"channels"`
+	ChannelLayout string `json:"channel_layout,omitempty"`
+	SampleFmt     string `json:"sample_fmt,omitempty"`
+	BitDepth      int    `json:"bit_depth,omitempty"`
+}
+
+// SubtitleStream describes a subtitle stream
+type SubtitleStream struct {
+	Stream
+	Forced bool `json:"forced"`
+}
+
+// HDRInfo contains high dynamic range metadata
+type HDRInfo struct {
+	Format             string             `json:"format"` // HDR10, HDR10+, HLG, Dolby Vision
+	MasteringDisplay   *MasteringDisplay  `json:"mastering_display,omitempty"`
+	ContentLight       *ContentLightLevel `json:"content_light,omitempty"`
+	DolbyVisionProfile int                `json:"dolby_vision_profile,omitempty"`
+}
+
+// MasteringDisplay contains SMPTE ST 2086 mastering display metadata
+type MasteringDisplay struct {
+	RedX, RedY     float64 `json:"-"`
+	GreenX, GreenY float64 `json:"-"`
+	BlueX, BlueY   float64 `json:"-"`
+	WhiteX, WhiteY float64 `json:"-"`
+	MinLuminance   float64 `json:"min_luminance"` // cd/m²
+	MaxLuminance   float64 `json:"max_luminance"` // cd/m²
+}
+
+// ContentLightLevel contains CTA-861.3 content light level metadata
+type ContentLightLevel struct {
+	MaxContent int `json:"max_cll"`  // MaxCLL, cd/m²
+	MaxAverage int `json:"max_fall"` // MaxFALL, cd/m²
+}
+
+// Duration is a time.Duration that serializes as seconds
+type Duration time.Duration
+
+// Std returns the value as a time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// Seconds returns the duration in seconds
+func (d Duration) Seconds() float64 {
+	return time.Duration(d).Seconds()
+}
+
+// MarshalJSON encodes the duration as fractional seconds
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatFloat(d.Seconds(), 'f', -1, 64)), nil
+}
+
+// PrimaryVideo returns the default video stream, or the first one
+func (m *MediaInfo) PrimaryVideo() *VideoStream {
+	for i := range m.Video {
+		if m.Video[i].Default {
+			return &m.Video[i]
+		}
+	}
+	if len(m.Video) > 0 {
+		return &m.Video[0]
+	}
+	return nil
+}
+
+// PrimaryAudio returns the default audio stream, or the first one
+func (m *MediaInfo) PrimaryAudio() *AudioStream {
+	for i := range m.Audio {
+		if m.Audio[i].Default {
+			return &m.Audio[i]
+		}
+	}
+	if len(m.Audio) > 0 {
+		return &m.Audio[0]
+	}
+	return nil
+}
+
+// HasVideo reports whether the file contains a video stream
+func (m *MediaInfo) HasVideo() bool {
+	return len(m.Video) > 0
+}
+
+// HasAudio reports whether the file contains an audio stream
+func (m *MediaInfo) HasAudio() bool {
+	return len(m.Audio) > 0
+}
+
+// Duration returns the container duration
+func (m *MediaInfo) Duration() time.Duration {
+	return m.Format.Duration.Std()
+}
+
+// DisplaySize returns the video dimensions after applying rotation
+func (v *VideoStream) DisplaySize() (width, height int) {
+	if v.Rotation == 90 || v.Rotation == 270 {
+		return v.Height, v.Width
+	}
+	return v.Width, v.Height
+}
+
+// IsHDR reports whether the stream carries HDR metadata or transfer characteristics
+func (v *VideoStream) IsHDR() bool {
+	return v.HDR != nil
+}
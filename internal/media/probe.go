@@ -0,0 +1,258 @@
+package media
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// probeOutput mirrors the JSON emitted by ffprobe -show_format -show_streams
+type probeOutput struct {
+	Format  probeFormat   `json:"format"`
+	Streams []probeStream `json:"streams"`
+}
+
+type probeFormat struct {
+	Filename   string            `json:"filename"`
+	NbStreams  int               `json:"nb_streams"`
+	FormatName string            `json:"format_name"`
+	LongName   string            `json:"format_long_name"`
+	StartTime  string            `json:"start_time"`
+	Duration   string            `json:"duration"`
+	Size       string            `json:"size"`
+	BitRate    string            `json:"bit_rate"`
+	Tags       map[string]string `json:"tags"`
+}
+
+type probeStream struct {
+	Index            int               `json:"index"`
+	CodecName        string            `json:"codec_name"`
+	CodecLongName    string            `json:"codec_long_name"`
+	CodecType        string            `json:"codec_type"`
+	CodecTagString   string            `json:"codec_tag_string"`
+	Profile          string            `json:"profile"`
+	Width            int               `json:"width"`
+	Height           int               `json:"height"`
+	PixFmt           string            `json:"pix_fmt"`
+	FieldOrder       string            `json:"field_order"`
+	ColorRange       string            `json:"color_range"`
+	ColorSpace       string            `json:"color_space"`
+	ColorTransfer    string            `json:"color_transfer"`
+	ColorPrimaries   string            `json:"color_primaries"`
+	RFrameRate       string            `json:"r_frame_rate"`
+	AvgFrameRate     string            `json:"avg_frame_rate"`
+	NbFrames         string            `json:"nb_frames"`
+	SampleFmt        string            `json:"sample_fmt"`
+	SampleRate       string            `json:"sample_rate"`
+	Channels         int               `json:"channels"`
+	ChannelLayout    string            `json:"channel_layout"`
+	BitsPerRawSample string            `json:"bits_per_raw_sample"`
+	BitsPerSample    int               `json:"bits_per_sample"`
+	Duration         string            `json:"duration"`
+	BitRate          string            `json:"bit_rate"`
+	Disposition      map[string]int    `json:"disposition"`
+	Tags             map[string]string `json:"tags"`
+	SideDataList     []map[string]any  `json:"side_data_list"`
+}
+
+// ParseProbe builds a MediaInfo from ffprobe JSON output
+// (ffprobe -print_format json -show_format -show_streams)
+func ParseProbe(data []byte) (*MediaInfo, error) {
+	var raw probeOutput
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid ffprobe output: %w", err)
+	}
+
+	info := &MediaInfo{
+		Path: raw.Format.Filename,
+		Format: Format{
+			Name:        raw.Format.FormatName,
+			LongName:    raw.Format.LongName,
+			Duration:    parseSeconds(raw.Format.Duration),
+			StartTime:   parseSeconds(raw.Format.StartTime),
+			Size:        parseInt(raw.Format.Size),
+			Bitrate:     parseInt(raw.Format.BitRate),
+			StreamCount: raw.Format.NbStreams,
+			Tags:        raw.Format.Tags,
+		},
+	}
+
+	for _, s := range raw.Streams {
+		base := Stream{
+			Index:         s.Index,
+			Codec:         s.CodecName,
+			CodecLongName: s.CodecLongName,
+			CodecTag:      s.CodecTagString,
+			Profile:       s.Profile,
+			Bitrate:       parseInt(s.BitRate),
+			Duration:      parseSeconds(s.Duration),
+			Language:      s.Tags["language"],
+			Title:         s.Tags["title"],
+			Default:       s.Disposition["default"] == 1,
+			Tags:          s.Tags,
+		}
+
+		switch s.CodecType {
+		case "video":
+			// Cover art is exposed as a single-frame video stream
+			if s.Disposition["attached_pic"] == 1 {
+				continue
+			}
+			info.Video = append(info.Video, parseVideoStream(base, s))
+		case "audio":
+			info.Audio = append(info.Audio, AudioStream{
+				Stream:        base,
+				SampleRate:    int(parseInt(s.SampleRate)),
+				Channels:      s.Channels,
+				ChannelLayout: s.ChannelLayout,
+				SampleFmt:     s.SampleFmt,
+				BitDepth:      bitDepth(s),
+			})
+		case "subtitle":
+			info.Subtitles = append(info.Subtitles, SubtitleStream{
+				Stream: base,
+				Forced: s.Disposition["forced"] == 1,
+			})
+		}
+	}
+
+	return info, nil
+}
+
+// parseVideoStream converts a raw video stream including rotation and HDR side data
+func parseVideoStream(base Stream, s probeStream) VideoStream {
+	v := VideoStream{
+		Stream:         base,
+		Width:          s.Width,
+		Height:         s.Height,
+		PixFmt:         s.PixFmt,
+		BitDepth:       bitDepth(s),
+		FrameRate:      parseRational(s.RFrameRate),
+		AvgFrameRate:   parseRational(s.AvgFrameRate),
+		FrameCount:     parseInt(s.NbFrames),
+		FieldOrder:     s.FieldOrder,
+		ColorRange:     s.ColorRange,
+		ColorSpace:     s.ColorSpace,
+		ColorTransfer:  s.ColorTransfer,
+		ColorPrimaries: s.ColorPrimaries,
+	}
+
+	// Legacy rotate tag (clockwise)
+	if r, err := strconv.Atoi(s.Tags["rotate"]); err == nil {
+		v.Rotation = normalizeRotation(r)
+	}
+
+	hdr := &HDRInfo{}
+	for _, sd := range s.SideDataList {
+		switch sd["side_data_type"] {
+		case "Display Matrix":
+			// Display matrix rotation is counter-clockwise
+			if r, ok := sd["rotation"].(float64); ok {
+				v.Rotation = normalizeRotation(-int(math.Round(r)))
+			}
+		case "Mastering display metadata":
+			hdr.MasteringDisplay = &MasteringDisplay{
+				RedX:         sideDataFloat(sd, "red_x"),
+				RedY:         sideDataFloat(sd, "red_y"),
+				GreenX:       sideDataFloat(sd, "green_x"),
+				GreenY:       sideDataFloat(sd, "green_y"),
+				BlueX:        sideDataFloat(sd, "blue_x"),
+				BlueY:        sideDataFloat(sd, "blue_y"),
+				WhiteX:       sideDataFloat(sd, "white_point_x"),
+				WhiteY:       sideDataFloat(sd, "white_point_y"),
+				MinLuminance: sideDataFloat(sd, "min_luminance"),
+				MaxLuminance: sideDataFloat(sd, "max_luminance"),
+			}
+		case "Content light level metadata":
+			hdr.ContentLight = &ContentLightLevel{
+				MaxContent: int(sideDataFloat(sd, "max_content")),
+				MaxAverage: int(sideDataFloat(sd, "max_average")),
+			}
+		case "DOVI configuration record":
+			hdr.Format = "Dolby Vision"
+			hdr.DolbyVisionProfile = int(sideDataFloat(sd, "dv_profile"))
+		case "HDR Dynamic Metadata SMPTE2094-40 (HDR10+)":
+			if hdr.Format == "" {
+				hdr.Format = "HDR10+"
+			}
+		}
+	}
+
+	if hdr.Format == "" {
+		switch s.ColorTransfer {
+		case "smpte2084":
+			hdr.Format = "HDR10"
+		case "arib-std-b67":
+			hdr.Format = "HLG"
+		}
+	}
+	if hdr.Format != "" || hdr.MasteringDisplay != nil || hdr.ContentLight != nil {
+		if hdr.Format == "" {
+			hdr.Format = "HDR10"
+		}
+		v.HDR = hdr
+	}
+
+	return v
+}
+
+// parseSeconds parses an ffprobe seconds value such as "12.345000"
+func parseSeconds(s string) Duration {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return Duration(time.Duration(f * float64(time.Second)))
+}
+
+// parseInt parses an integer value, returning 0 for "N/A" or empty strings
+func parseInt(s string) int64 {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// parseRational parses ffprobe rationals such as "30000/1001"
+func parseRational(s string) float64 {
+	num, den, ok := strings.Cut(s, "/")
+	if !ok {
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	}
+
+	n, err1 := strconv.ParseFloat(num, 64)
+	d, err2 := strconv.ParseFloat(den, 64)
+	if err1 != nil || err2 != nil || d == 0 {
+		return 0
+	}
+	return n / d
+}
+
+// sideDataFloat reads a numeric or rational side data value
+func sideDataFloat(sd map[string]any, key string) float64 {
+	switch v := sd[key].(type) {
+	case float64:
+		return v
+	case string:
+		return parseRational(v)
+	}
+	return 0
+}
+
+// bitDepth returns the sample bit depth of a stream if known
+func bitDepth(s probeStream) int {
+	if n := parseInt(s.BitsPerRawSample); n > 0 {
+		return int(n)
+	}
+	return s.BitsPerSample
+}
+
+// normalizeRotation maps any rotation into the range [0, 360)
+func normalizeRotation(deg int) int {
+	return ((deg % 360) + 360) % 360
+}
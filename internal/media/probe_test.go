@@ -0,0 +1,84 @@
+package media
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseProbe(t *testing.T) {
+	tests := []struct {
+		fixture       string
+		width, height int
+		rotation      int
+		displayW      int // width after rotation
+		hdr           string
+		maxLuminance  float64
+		maxContent    int
+		audio         int
+		transfer      string
+		primaries     string
+	}{
+		{"portrait_displaymatrix.json", 1920, 1080, 90, 1080, "", 0, 0, 1, "bt709", "bt709"},
+		{"portrait_rotate_tag.json", 1280, 720, 270, 720, "", 0, 0, 0, "bt709", "bt709"},
+		{"hdr10.json", 3840, 2160, 0, 3840, "HDR10", 1000, 1000, 0, "smpte2084", "bt2020"},
+		{"hlg.json", 1920, 1080, 90, 1080, "HLG", 0, 0, 0, "arib-std-b67", "bt2020"},
+		{"sdr.json", 1920, 1080, 0, 1920, "", 0, 0, 1, "bt709", "bt709"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "probe", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, err := ParseProbe(data)
+			if err != nil {
+				t.Fatalf("ParseProbe() error = %v", err)
+			}
+
+			if len(info.Video) != 1 {
+				t.Fatalf("got %d video streams, want 1", len(info.Video))
+			}
+			v := info.Video[0]
+			if v.Width != tt.width || v.Height != tt.height {
+				t.Errorf("size = %dx%d, want %dx%d", v.Width, v.Height, tt.width, tt.height)
+			}
+			if v.Rotation != tt.rotation {
+				t.Errorf("Rotation = %d, want %d", v.Rotation, tt.rotation)
+			}
+			if w, _ := v.DisplaySize(); w != tt.displayW {
+				t.Errorf("display width = %d, want %d", w, tt.displayW)
+			}
+			if v.ColorTransfer != tt.transfer || v.ColorPrimaries != tt.primaries {
+				t.Errorf("transfer, primaries = %s, %s, want %s, %s", v.ColorTransfer, v.ColorPrimaries, tt.transfer, tt.primaries)
+			}
+			if len(info.Audio) != tt.audio {
+				t.Errorf("got %d audio streams, want %d", len(info.Audio), tt.audio)
+			}
+
+			if tt.hdr == "" {
+				if v.HDR != nil {
+					t.Errorf("HDR = %+v, want none", *v.HDR)
+				}
+				return
+			}
+			if v.HDR == nil {
+				t.Fatalf("HDR = nil, want %s", tt.hdr)
+			}
+			if v.HDR.Format != tt.hdr {
+				t.Errorf("HDR format = %q, want %q", v.HDR.Format, tt.hdr)
+			}
+			if tt.maxLuminance != 0 {
+				if md := v.HDR.MasteringDisplay; md == nil || md.MaxLuminance != tt.maxLuminance || md.MinLuminance != 0.005 {
+					t.Errorf("MasteringDisplay = %+v, want max %g cd/m², min 0.005", md, tt.maxLuminance)
+				}
+			}
+			if tt.maxContent != 0 {
+				if cl := v.HDR.ContentLight; cl == nil || cl.MaxContent != tt.maxContent {
+					t.Errorf("ContentLight = %+v, want MaxCLL %d", cl, tt.maxContent)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// sniffLen is how many leading bytes Sniff reads; enough for three MPEG-TS
+// packets and the ftyp box or EBML header of common files
+const sniffLen = 1024
+
+// ErrUnknownContainer is returned by Sniff for files without a recognized signature
+var ErrUnknownContainer = errors.New("unrecognized file signature")
+
+// Container is a file format recognized from its leading bytes
+type Container struct {
+	Name       string   // e.g., "isobmff", "matroska", "mpegts"
+	Brand      string   // ISO BMFF major brand or Matroska DocType, if any
+	Extensions []string // extensions used for this format, preferred first
+}
+
+// Ext returns the preferred extension of the container
+func (c Container) Ext() string {
+	if len(c.Extensions) == 0 {
+		return ""
+	}
+	return c.Extensions[0]
+}
+
+// Matches reports whether files in this container are one of exts
+func (c Container) Matches(exts []string) bool {
+	return slices.ContainsFunc(c.Extensions, func(e string) bool { return slices.Contains(exts, e) })
+}
+
+// String returns the container name and brand, e.g., "isobmff (qt)"
+func (c Container) String() string {
+	if c.Brand == "" {
+		return c.Name
+	}
+	return fmt.Sprintf("%s (%s)", c.Name, c.Brand)
+}
+
+// Sniff identifies the container of the file at path from its signature
+func Sniff(path string) (Container, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Container{}, err
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return Container{}, err
+	}
+
+	// A tag larger than the sniffed bytes (cover art) hides the audio stream
+	if size, ok := id3Size(head[:n]); ok && size >= n {
+		after := make([]byte, sniffLen)
+		if m, err := f.ReadAt(after, int64(size)); m > 0 && (err == nil || err == io.EOF) {
+			if c, ok := sniffTagged(after[:m]); ok {
+				return c, nil
+			}
+		}
+	}
+
+	if c, ok := SniffBytes(head[:n]); ok {
+		return c, nil
+	}
+	return Container{}, ErrUnknownContainer
+}
+
+// SniffBytes identifies a container from the leading bytes of a file
+func SniffBytes(head []byte) (Container, bool) {
+	if c, ok := sniffISOBMFF(head); ok {
+		return c, true
+	}
+	if c, ok := sniffEBML(head); ok {
+		return c, true
+	}
+	if c, ok := sniffMPEGTS(head); ok {
+		return c, true
+	}
+
+	switch {
+	case len(head) >= 12 && (hasPrefix(head, "RIFF") || hasPrefix(head, "RF64")):
+		switch string(head[8:12]) {
+		case "WAVE":
+			return Container{Name: "wav", Extensions: []string{".wav"}}, true
+		case "AVI ":
+			return Container{Name: "avi", Extensions: []string{".avi"}}, true
+		case "WEBP":
+			return Container{Name: "webp", Extensions: []string{".webp"}}, true
+		}
+	case len(head) >= 12 && hasPrefix(head, "FORM") && (string(head[8:12]) == "AIFF" || string(head[8:12]) == "AIFC"):
+		return Container{Name: "aiff", Extensions: []string{".aiff", ".aif"}}, true
+	case bytes.HasPrefix(head, []byte{0x00, 0x00, 0x01, 0xBA}):
+		return Container{Name: "mpeg", Extensions: []string{".mpg", ".mpeg", ".vob"}}, true
+	case bytes.HasPrefix(head, []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11}):
+		return Container{Name: "asf", Extensions: []string{".wmv", ".wma", ".asf"}}, true
+	case hasPrefix(head, "FLV\x01"):
+		return Container{Name: "flv", Extensions: []string{".flv"}}, true
+	case hasPrefix(head, "OggS"):
+		return Container{Name: "ogg", Extensions: []string{".ogg", ".opus", ".oga"}}, true
+	case hasPrefix(head, "fLaC"):
+		return Container{Name: "flac", Extensions: []string{".flac"}}, true
+	case hasPrefix(head, "ID3"):
+		// FLAC and ADTS files may carry an ID3 tag too; mp3 is the fallback
+		if size, ok := id3Size(head); ok && size < len(head) {
+			if c, ok := sniffTagged(head[size:]); ok {
+				return c, true
+			}
+		}
+		return Container{Name: "mp3", Extensions: []string{".mp3"}}, true
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}):
+		return Container{Name: "jpeg", Extensions: []string{".jpg", ".jpeg"}}, true
+	case hasPrefix(head, "\x89PNG\r\n\x1a\n"):
+		return Container{Name: "png", Extensions: []string{".png"}}, true
+	case hasPrefix(head, "GIF87a") || hasPrefix(head, "GIF89a"):
+		return Container{Name: "gif", Extensions: []string{".gif"}}, true
+	case hasPrefix(head, "II*\x00") || hasPrefix(head, "MM\x00*"):
+		return Container{Name: "tiff", Extensions: []string{".tif", ".tiff"}}, true
+	case len(head) >= 18 && hasPrefix(head, "BM") && binary.LittleEndian.Uint32(head[6:]) == 0 && isBMPHeaderSize(binary.LittleEndian.Uint32(head[14:])):
+		return Container{Name: "bmp", Extensions: []string{".bmp"}}, true
+	case len(head) >= 3 && head[0] == 0xFF && head[1]&0xF6 == 0xF0:
+		return Container{Name: "aac", Brand: "adts", Extensions: []string{".aac"}}, true
+	case len(head) >= 3 && isMPEGAudioFrame(head):
+		return Container{Name: "mp3", Extensions: []string{".mp3"}}, true
+	}
+
+	return Container{}, false
+}
+
+// id3Size returns the length of the ID3v2 tag at the start of head, header
+// and footer included
+func id3Size(head []byte) (int, bool) {
+	if len(head) < 10 || !hasPrefix(head, "ID3") || head[3] == 0xFF || head[4] == 0xFF {
+		return 0, false
+	}
+	// The size is a syncsafe integer: four 7-bit digits, excluding the header
+	size := 0
+	for _, b := range head[6:10] {
+		if b&0x80 != 0 {
+			return 0, false
+		}
+		size = size<<7 | int(b)
+	}
+	size += 10
+	if head[5]&0x10 != 0 {
+		size += 10 // footer
+	}
+	return size, true
+}
+
+// sniffTagged identifies the audio stream that follows an ID3 tag
+func sniffTagged(head []byte) (Container, bool) {
+	if hasPrefix(head, "ID3") {
+		return Container{}, false
+	}
+	c, ok := SniffBytes(head)
+	if !ok {
+		return Container{}, false
+	}
+	switch c.Name {
+	case "flac", "aac", "mp3":
+		return c, true
+	}
+	return Container{}, false
+}
+
+// isBMPHeaderSize reports whether n is the size of a known BMP info header
+// (BITMAPCOREHEADER through BITMAPV5HEADER)
+func isBMPHeaderSize(n uint32) bool {
+	switch n {
+	case 12, 40, 52, 56, 64, 108, 124:
+		return true
+	}
+	return false
+}
+
+// isMPEGAudioFrame reports whether head starts with a plausible MPEG audio
+// frame header (sync bits plus valid version, layer, bitrate and sample rate)
+func isMPEGAudioFrame(head []byte) bool {
+	version := head[1] >> 3 & 0x03
+	layer := head[1] >> 1 & 0x03
+	bitrate := head[2] >> 4
+	rate := head[2] >> 2 & 0x03
+	return head[0] == 0xFF && head[1]&0xE0 == 0xE0 &&
+		version != 1 && layer != 0 && bitrate != 0 && bitrate != 0x0F && rate != 3
+}
+
+// Brands of ISO BMFF files holding HEIF/AVIF images
+var (
+	heifBrands = map[string]bool{"heic": true, "heix": true, "heim": true, "heis": true, "hevc": true, "hevx": true}
+	avifBrands = map[string]bool{"avif": true, "avis": true}
+)
+
+// sniffISOBMFF recognizes MP4, QuickTime, 3GP, M4A and HEIF files by their
+// ftyp box, and old QuickTime files by a leading top-level atom
+func sniffISOBMFF(head []byte) (Container, bool) {
+	if len(head) < 12 {
+		return Container{}, false
+	}
+
+	switch string(head[4:8]) {
+	case "ftyp":
+	case "moov", "mdat", "wide", "free", "skip", "pnot":
+		return Container{Name: "isobmff", Brand: "qt", Extensions: []string{".mov", ".mp4", ".m4v"}}, true
+	default:
+		return Container{}, false
+	}
+
+	size := int(head[0])<<24 | int(head[1])<<16 | int(head[2])<<8 | int(head[3])
+	if size < 16 || size > len(head) {
+		size = len(head)
+	}
+	major := string(head[8:12])
+	brands := []string{major}
+	for i := 16; i+4 <= size; i += 4 {
+		brands = append(brands, string(head[i:i+4]))
+	}
+
+	// mif1/msf1 only say "image file"; the compatible brands tell which codec
+	for _, b := range brands {
+		switch {
+		case heifBrands[b]:
+			return Container{Name: "heif", Brand: b, Extensions: []string{".heic", ".heif"}}, true
+		case avifBrands[b]:
+			return Container{Name: "avif", Brand: b, Extensions: []string{".avif"}}, true
+		}
+	}
+	if major == "mif1" || major == "msf1" {
+		return Container{Name: "heif", Brand: major, Extensions: []string{".heif", ".heic"}}, true
+	}
+
+	switch {
+	case major == "qt  ":
+		return Container{Name: "isobmff", Brand: "qt", Extensions: []string{".mov", ".mp4", ".m4v"}}, true
+	case major == "M4A " || major == "M4B ":
+		return Container{Name: "isobmff", Brand: major[:3], Extensions: []string{".m4a", ".mp4", ".aac"}}, true
+	case major[:3] == "3gp" || major[:3] == "3g2":
+		return Container{Name: "isobmff", Brand: major, Extensions: []string{".3gp", ".3g2", ".mp4", ".mov"}}, true
+	}
+	return Container{Name: "isobmff", Brand: string(bytes.TrimSpace(head[8:12])), Extensions: []string{".mp4", ".m4v", ".mov", ".m4a", ".3gp"}}, true
+}
+
+// sniffEBML recognizes Matroska and WebM files by their EBML header and DocType
+func sniffEBML(head []byte) (Container, bool) {
+	if !bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}) {
+		return Container{}, false
+	}
+
+	docType := ""
+	if i := bytes.Index(head, []byte{0x42, 0x82}); i >= 0 && i+3 <= len(head) {
+		// DocType sizes are single-byte EBML varints in practice
+		if n := int(head[i+2] & 0x7F); head[i+2]&0x80 != 0 && i+3+n <= len(head) {
+			docType = string(head[i+3 : i+3+n])
+		}
+	}
+
+	if docType == "webm" {
+		return Container{Name: "matroska", Brand: docType, Extensions: []string{".webm", ".mkv"}}, true
+	}
+	return Container{Name: "matroska", Brand: docType, Extensions: []string{".mkv", ".mka", ".mk3d"}}, true
+}
+
+// sniffMPEGTS recognizes MPEG transport streams by the sync byte of three
+// consecutive packets, either 188-byte TS or 192-byte M2TS packets
+func sniffMPEGTS(head []byte) (Container, bool) {
+	for _, layout := range []struct{ offset, size int }{{0, 188}, {4, 192}} {
+		if len(head) < layout.offset+2*layout.size+1 {
+			continue
+		}
+		synced := true
+		for i := 0; i < 3; i++ {
+			if head[layout.offset+i*layout.size] != 0x47 {
+				synced = false
+				break
+			}
+		}
+		if synced {
+			return Container{Name: "mpegts", Extensions: []string{".ts", ".mts", ".m2ts"}}, true
+		}
+	}
+	return Container{}, false
+}
+
+// hasPrefix reports whether head starts with the signature s
+func hasPrefix(head []byte, s string) bool {
+	return bytes.HasPrefix(head, []byte(s))
+}
@@ -0,0 +1,159 @@
+package media
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// pad extends b with zero bytes to n bytes
+func pad(b []byte, n int) []byte {
+	if len(b) >= n {
+		return b
+	}
+	return append(b, make([]byte, n-len(b))...)
+}
+
+// ftyp returns an ftyp box with the major brand and compatible brands
+func ftyp(major string, compatible ...string) []byte {
+	size := 16 + 4*len(compatible)
+	box := []byte{0, 0, 0, byte(size)}
+	box = append(box, "ftyp"+major+"\x00\x00\x00\x00"...)
+	for _, b := range compatible {
+		box = append(box, b...)
+	}
+	return pad(box, 32)
+}
+
+// id3Tag returns an ID3v2.4 tag whose body is size bytes of padding
+func id3Tag(size int) []byte {
+	tag := []byte{'I', 'D', '3', 4, 0, 0,
+		byte(size >> 21 & 0x7F), byte(size >> 14 & 0x7F), byte(size >> 7 & 0x7F), byte(size & 0x7F)}
+	return append(tag, make([]byte, size)...)
+}
+
+// tsPackets returns three MPEG-TS packets of the given size after offset bytes
+func tsPackets(offset, size int) []byte {
+	b := make([]byte, offset+3*size)
+	for i := 0; i < 3; i++ {
+		b[offset+i*size] = 0x47
+	}
+	return b
+}
+
+var (
+	mp3Frame  = []byte{0xFF, 0xFB, 0x90, 0x64}
+	adtsFrame = []byte{0xFF, 0xF1, 0x50, 0x80}
+)
+
+func TestSniffBytes(t *testing.T) {
+	tests := []struct {
+		name      string
+		head      []byte
+		want      string // Container.String()
+		wantExt   string
+		wantFound bool
+	}{
+		{"mp4", ftyp("isom", "isom", "avc1"), "isobmff (isom)", ".mp4", true},
+		{"quicktime", ftyp("qt  "), "isobmff (qt)", ".mov", true},
+		{"old quicktime", pad([]byte("\x00\x00\x00\x08wide"), 16), "isobmff (qt)", ".mov", true},
+		{"m4a", ftyp("M4A ", "M4A ", "mp42"), "isobmff (M4A)", ".m4a", true},
+		{"3gp", ftyp("3gp5", "3gp5"), "isobmff (3gp5)", ".3gp", true},
+		{"heic", ftyp("mif1", "mif1", "heic"), "heif (heic)", ".heic", true},
+		{"avif", ftyp("avif", "mif1"), "avif (avif)", ".avif", true},
+		{"generic heif", ftyp("mif1", "mif1"), "heif (mif1)", ".heif", true},
+		{"webm", []byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x82, 0x84, 'w', 'e', 'b', 'm'}, "matroska (webm)", ".webm", true},
+		{"matroska", []byte{0x1A, 0x45, 0xDF, 0xA3, 0xA3, 0x42, 0x82, 0x88, 'm', 'a', 't', 'r', 'o', 's', 'k', 'a'}, "matroska (matroska)", ".mkv", true},
+		{"mpegts", tsPackets(0, 188), "mpegts", ".ts", true},
+		{"m2ts", tsPackets(4, 192), "mpegts", ".ts", true},
+		{"wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), "wav", ".wav", true},
+		{"avi", []byte("RIFF\x24\x00\x00\x00AVI LIST"), "avi", ".avi", true},
+		{"webp", []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), "webp", ".webp", true},
+		{"aiff", []byte("FORM\x00\x00\x00\x00AIFFCOMM"), "aiff", ".aiff", true},
+		{"mpeg program stream", []byte{0x00, 0x00, 0x01, 0xBA, 0x44}, "mpeg", ".mpg", true},
+		{"asf", []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11, 0xA6, 0xD9}, "asf", ".wmv", true},
+		{"flv", []byte("FLV\x01\x05"), "flv", ".flv", true},
+		{"ogg", []byte("OggS\x00\x02"), "ogg", ".ogg", true},
+		{"flac", []byte("fLaC\x00\x00\x00\x22"), "flac", ".flac", true},
+		{"jpeg", []byte{0xFF, 0xD8, 0xFF, 0xE1}, "jpeg", ".jpg", true},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"), "png", ".png", true},
+		{"gif", []byte("GIF89a\x01\x00"), "gif", ".gif", true},
+		{"tiff", []byte("II*\x00\x08\x00\x00\x00"), "tiff", ".tif", true},
+		{"bmp", pad([]byte("BM\x46\x00\x00\x00\x00\x00\x00\x00\x36\x00\x00\x00\x28\x00\x00\x00"), 18), "bmp", ".bmp", true},
+		{"adts", adtsFrame, "aac (adts)", ".aac", true},
+		{"mp3 frame", mp3Frame, "mp3", ".mp3", true},
+		{"id3 mp3", append(id3Tag(64), mp3Frame...), "mp3", ".mp3", true},
+		{"id3 flac", append(id3Tag(64), "fLaC\x00\x00\x00\x22"...), "flac", ".flac", true},
+		{"id3 aac", append(id3Tag(64), adtsFrame...), "aac (adts)", ".aac", true},
+		{"id3 with footer", append(append([]byte("ID3\x04\x00\x10\x00\x00\x00\x04"), make([]byte, 14)...), "fLaC"...), "flac", ".flac", true},
+		{"id3 tag past the head", id3Tag(4096)[:sniffLen], "mp3", ".mp3", true},
+		{"id3 before other content", append(id3Tag(16), "\x89PNG\r\n\x1a\n"...), "mp3", ".mp3", true},
+		{"bm text is not bmp", []byte("BMP files are bitmaps"), "", "", false},
+		{"invalid mpeg audio header", []byte{0xFF, 0xFF, 0xFF, 0xFF}, "", "", false},
+		{"text", []byte("hello world, not media"), "", "", false},
+		{"empty", nil, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := SniffBytes(tt.head)
+			if ok != tt.wantFound {
+				t.Fatalf("SniffBytes() found = %v (%s), want %v", ok, c, tt.wantFound)
+			}
+			if c.String() != tt.want || c.Ext() != tt.wantExt {
+				t.Errorf("SniffBytes() = %s %s, want %s %s", c, c.Ext(), tt.want, tt.wantExt)
+			}
+		})
+	}
+}
+
+func TestSniffLargeID3Tag(t *testing.T) {
+	// Cover art pushes the audio stream past the first sniffed bytes
+	path := filepath.Join(t.TempDir(), "song")
+	data := append(id3Tag(64*1024), "fLaC\x00\x00\x00\x22"...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Sniff(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "flac" {
+		t.Errorf("Sniff() = %s, want flac", c)
+	}
+}
+
+func TestDetectExt(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content []byte
+		trust   bool
+		want    string
+	}{
+		{"clip.mov", ftyp("qt  "), false, ".mov"},
+		{"clip.mp4", ftyp("qt  "), false, ".mp4"}, // shared container, own extension fits
+		{"photo.jpg", []byte("\x89PNG\r\n\x1a\n"), false, ".png"},
+		{"photo.jpg", []byte("\x89PNG\r\n\x1a\n"), true, ".jpg"},
+		{"song.mp3", append(id3Tag(64), "fLaC"...), false, ".flac"},
+		{"song.mp3", append(id3Tag(64), mp3Frame...), false, ".mp3"},
+		{"noext", ftyp("mif1", "heic"), false, ".heic"},
+		{"notes.SRT", []byte("1\n00:00:01,000 --> 00:00:02,000\n"), false, ".srt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDetection(Detection{TrustExtension: tt.trust})
+			defer SetDetection(Detection{})
+
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, tt.content, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if got := DetectExt(path); got != tt.want {
+				t.Errorf("DetectExt(%s) = %s, want %s", tt.name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cachedHandshake is the on-disk form of a handshake, valid while the
+// executable keeps its size and modification time
+type cachedHandshake struct {
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"mod_time"`
+	Handshake Handshake `json:"handshake"`
+}
+
+// handshakeCachePath returns the on-disk cache location for the plugin at path
+func handshakeCachePath(path string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(dir, "sb", "plugin-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// loadCachedHandshake returns the cached handshake of the plugin at path if
+// the executable has not changed since it was stored
+func loadCachedHandshake(path string, stat os.FileInfo) (Handshake, bool) {
+	cachePath, err := handshakeCachePath(path)
+	if err != nil {
+		return Handshake{}, false
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return Handshake{}, false
+	}
+
+	var cached cachedHandshake
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return Handshake{}, false
+	}
+	if cached.Path != path || cached.Size != stat.Size() || !cached.ModTime.Equal(stat.ModTime()) {
+		return Handshake{}, false
+	}
+	if cached.Handshake.Validate() != nil {
+		return Handshake{}, false
+	}
+	return cached.Handshake, true
+}
+
+// saveCachedHandshake stores the handshake of the plugin at path; failures
+// are ignored
+func saveCachedHandshake(path string, stat os.FileInfo, info Handshake) {
+	cachePath, err := handshakeCachePath(path)
+	if err != nil {
+		return
+	}
+
+	data, err := json.Marshal(cachedHandshake{
+		Path:      path,
+		Size:      stat.Size(),
+		ModTime:   stat.ModTime(),
+		Handshake: info,
+	})
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return
+	}
+	os.WriteFile(cachePath, data, 0644)
+}
@@ -0,0 +1,309 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/ui"
+)
+
+// HandshakeTimeout bounds handshake and validate calls
+const HandshakeTimeout = 10 * time.Second
+
+// Converter is a proxy that forwards Validate and Convert to a plugin executable
+type Converter struct {
+	path   string
+	info   Handshake
+	schema []converter.OptionSpec
+
+	mu     sync.Mutex
+	values converter.OptionValues
+}
+
+// Load starts the plugin at path, performs the handshake and returns a
+// proxy converter for it
+func Load(ctx context.Context, path string) (*Converter, error) {
+	info, err := handshake(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	for i, ext := range info.Inputs {
+		info.Inputs[i] = converter.NormalizeExt(ext)
+	}
+	info.OutputExtension = converter.NormalizeExt(info.OutputExtension)
+
+	schema, err := info.Schema()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Converter{path: path, info: info, schema: schema}, nil
+}
+
+// copy returns a new, unconfigured proxy for the same plugin
+func (c *Converter) copy() converter.Converter {
+	return &Converter{path: c.path, info: c.info, schema: c.schema}
+}
+
+// handshake returns the plugin's handshake, from the cache when the
+// executable is unchanged since it was last run
+func handshake(ctx context.Context, path string) (Handshake, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return Handshake{}, err
+	}
+	if info, ok := loadCachedHandshake(path, stat); ok {
+		return info, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, HandshakeTimeout)
+	defer cancel()
+
+	stdout, err := run(ctx, path, Request{Protocol: ProtocolVersion, Method: MethodHandshake}, nil)
+	if err != nil {
+		return Handshake{}, err
+	}
+
+	var info Handshake
+	if err := json.Unmarshal(stdout, &info); err != nil {
+		return Handshake{}, fmt.Errorf("invalid handshake: %w", err)
+	}
+	if err := info.Validate(); err != nil {
+		return Handshake{}, err
+	}
+
+	saveCachedHandshake(path, stat, info)
+	return info, nil
+}
+
+// Name returns the converter name announced by the plugin
+func (c *Converter) Name() string {
+	return c.info.Name
+}
+
+// Description returns the plugin's description
+func (c *Converter) Description() string {
+	return c.info.Description + " (plugin)"
+}
+
+// SupportedInputs returns the input extensions announced by the plugin
+func (c *Converter) SupportedInputs() []string {
+	return c.info.Inputs
+}
+
+// OutputExtension returns the output extension announced by the plugin
+func (c *Converter) OutputExtension() string {
+	return c.info.OutputExtension
+}
+
+// Path returns the plugin executable
+func (c *Converter) Path() string {
+	return c.path
+}
+
+// Cost returns the plugin's cost hint
+func (c *Converter) Cost() int {
+	return c.info.Cost
+}
+
+// OptionSchema returns the options announced by the plugin
+func (c *Converter) OptionSchema() []converter.OptionSpec {
+	return c.schema
+}
+
+// Configure stores option values sent with every convert call
+func (c *Converter) Configure(values converter.OptionValues) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values = values
+	return nil
+}
+
+// Validate checks the input file locally (by content) and, if the plugin asks for it,
+// with a validate call
+func (c *Converter) Validate(input string) error {
+	return c.validate(context.Background(), input)
+}
+
+// validate is Validate with the validate call bounded by ctx
+func (c *Converter) validate(ctx context.Context, input string) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return fmt.Errorf("cannot access file: %w", err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("input is a directory, not a file")
+	}
+
+	if err := media.CheckInput(input, c.info.Inputs); err != nil {
+		return err
+	}
+
+	if !c.info.Validates {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, HandshakeTimeout)
+	defer cancel()
+
+	_, err = c.call(ctx, Request{Method: MethodValidate, Input: input}, nil)
+	return err
+}
+
+// Convert runs the plugin on a single job. The plugin writes to a partial
+// file that is renamed into place once it reports success.
+func (c *Converter) Convert(ctx context.Context, job converter.Job) (*converter.Result, error) {
+	input, opts := job.Input, job.Options
+
+	c.mu.Lock()
+	values := c.values
+	c.mu.Unlock()
+
+	return converter.RunJob(ctx, job, converter.Task{
+		// Ctrl-C interrupts the validate call too
+		Validate: func(input string) error { return c.validate(ctx, input) },
+		Encode: func(partial string) (*executor.FFmpegResult, error) {
+			req := Request{
+				Method:          MethodConvert,
+				Input:           input,
+				Output:          partial,
+				OutputExtension: c.info.OutputExtension,
+				Options:         encodeValues(values),
+				Verbose:         opts.Verbose,
+			}
+
+			ui.PrintVerbose(opts.Verbose, "Running plugin %s", c.path)
+
+			_, err := c.call(ctx, req, func(m Message) {
+				switch m.Type {
+				case MessageProgress:
+					if opts.OnProgress != nil {
+						opts.OnProgress(converter.ProgressFrom(executor.ProgressInfo{
+							Percentage: m.Percentage,
+							Speed:      m.Speed,
+							ETA:        time.Duration(m.ETASeconds * float64(time.Second)),
+						}, input, 0, 1))
+					}
+				case MessageLog:
+					ui.PrintVerbose(opts.Verbose, "[%s] %s", c.info.Name, m.Message)
+				}
+			})
+			return nil, err
+		},
+	})
+}
+
+// call sends req and streams the plugin's messages to onMessage until the
+// result message, which is returned
+func (c *Converter) call(ctx context.Context, req Request, onMessage func(Message)) (*Message, error) {
+	req.Protocol = ProtocolVersion
+
+	var result *Message
+	var decodeErr error
+	_, err := run(ctx, c.path, req, func(line []byte) {
+		var m Message
+		if err := json.Unmarshal(line, &m); err != nil {
+			if decodeErr == nil {
+				decodeErr = fmt.Errorf("invalid message from plugin: %q", line)
+			}
+			return
+		}
+		if m.Type == MessageResult {
+			result = &m
+			return
+		}
+		if onMessage != nil {
+			onMessage(m)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	if result == nil {
+		return nil, fmt.Errorf("plugin exited without a result")
+	}
+	if !result.OK {
+		if result.Error == "" {
+			result.Error = "plugin reported failure"
+		}
+		return result, fmt.Errorf("%s", result.Error)
+	}
+	return result, nil
+}
+
+// run starts the plugin with req on stdin. With a nil onLine, stdout is
+// returned whole; otherwise each non-empty stdout line is passed to onLine.
+func run(ctx context.Context, path string, req Request, onLine func([]byte)) ([]byte, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cancelling kills the plugin if its output cannot be read to the end
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := executor.Command(ctx, path)
+	cmd.Stdin = bytes.NewReader(append(body, '\n'))
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if onLine == nil {
+		cmd.Stdout = &stdout
+		err = cmd.Run()
+	} else {
+		pipe, pipeErr := cmd.StdoutPipe()
+		if pipeErr != nil {
+			return nil, pipeErr
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start plugin: %w", err)
+		}
+
+		scanner := bufio.NewScanner(pipe)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+				onLine(line)
+			}
+		}
+		if scanErr := scanner.Err(); scanErr != nil {
+			// The plugin may be blocked writing to the full pipe
+			cancel()
+			cmd.Wait()
+			return nil, fmt.Errorf("failed to read plugin output: %w", scanErr)
+		}
+		err = cmd.Wait()
+	}
+
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, lastLine(msg))
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// lastLine returns the last line of s
+func lastLine(s string) string {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
@@ -0,0 +1,145 @@
+//go:build !windows
+
+package plugin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onedusk/sb/internal/converter"
+)
+
+// writePlugin writes an executable shell script plugin into dir
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const handshakeScript = `cat >/dev/null
+echo '{"protocol":1,"name":"%s","inputs":[".txt"],"output_extension":".out"}'
+`
+
+func TestRunOversizedLine(t *testing.T) {
+	// A line over the scanner limit, then more output than a pipe buffers
+	path := writePlugin(t, t.TempDir(), "noisy", `cat >/dev/null
+head -c 2000000 /dev/zero | tr '\0' 'x'
+echo
+head -c 2000000 /dev/zero | tr '\0' 'y'
+sleep 30
+`)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := run(context.Background(), path, Request{Method: MethodConvert}, func([]byte) {})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "plugin output") {
+			t.Errorf("run() error = %v, want a read error", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("run() hung on an oversized output line")
+	}
+}
+
+func TestHandshakeCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	count := filepath.Join(dir, "count")
+	path := writePlugin(t, dir, "upper", "echo run >>"+count+"\n"+strings.Replace(handshakeScript, "%s", "upper", 1))
+
+	runs := func() int {
+		data, _ := os.ReadFile(count)
+		return strings.Count(string(data), "run")
+	}
+
+	for i := 0; i < 2; i++ {
+		conv, err := Load(context.Background(), path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if conv.Name() != "upper" {
+			t.Errorf("Name() = %q, want upper", conv.Name())
+		}
+	}
+	if n := runs(); n != 1 {
+		t.Errorf("plugin ran %d times, want 1 (second load from cache)", n)
+	}
+
+	// A changed executable is handshaked again
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(context.Background(), path); err != nil {
+		t.Fatal(err)
+	}
+	if n := runs(); n != 2 {
+		t.Errorf("plugin ran %d times, want 2 after modification", n)
+	}
+}
+
+func TestLoadAllOrder(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	for _, name := range []string{"b", "a", "c"} {
+		writePlugin(t, dir, name, strings.Replace(handshakeScript, "%s", name, 1))
+	}
+	writePlugin(t, dir, "broken", "exit 1\n")
+
+	r := converter.NewRegistry()
+	loaded, err := LoadAll(context.Background(), r, []string{dir})
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("LoadAll() error = %v, want the broken plugin reported", err)
+	}
+
+	names := []string{}
+	for _, conv := range loaded {
+		names = append(names, conv.Name())
+	}
+	if strings.Join(names, ",") != "a,b,c" {
+		t.Errorf("loaded %v, want a,b,c in discovery order", names)
+	}
+}
+
+func TestConvertInterruptsValidate(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	path := writePlugin(t, dir, "slow", `req=$(cat)
+case "$req" in
+*handshake*) echo '{"protocol":1,"name":"slow","inputs":[".txt"],"output_extension":".out","validate":true}' ;;
+*) sleep 30 ;;
+esac
+`)
+	input := filepath.Join(dir, "in.txt")
+	if err := os.WriteFile(input, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conv, err := Load(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	conv.Convert(ctx, converter.Job{Input: input, Output: filepath.Join(dir, "in.out")})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Convert() returned after %v, want the validate call cancelled with the job", elapsed)
+	}
+}
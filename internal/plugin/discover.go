@@ -0,0 +1,119 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/onedusk/sb/internal/converter"
+)
+
+// PathEnv lists additional plugin directories, separated like PATH
+const PathEnv = "SB_PLUGIN_PATH"
+
+// SearchPaths returns the plugin directories: ~/.sb/plugins followed by the
+// entries of $SB_PLUGIN_PATH
+func SearchPaths() []string {
+	dirs := []string{}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".sb", "plugins"))
+	}
+	for _, dir := range filepath.SplitList(os.Getenv(PathEnv)) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// Discover returns the executables in dirs, sorted by name within each
+// directory. Missing directories are skipped.
+func Discover(dirs []string) ([]string, error) {
+	found := []string{}
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return found, fmt.Errorf("error reading plugin directory: %w", err)
+		}
+
+		names := []string{}
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || !isExecutable(info) {
+				continue
+			}
+			names = append(names, entry.Name())
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			found = append(found, filepath.Join(dir, name))
+		}
+	}
+	return found, nil
+}
+
+// LoadAll discovers the plugins in dirs, handshakes with each (in parallel,
+// or from the handshake cache) and registers a proxy converter for it with
+// r. Plugins that fail the handshake or clash with an already registered
+// converter are reported in the returned error and skipped; the others are
+// still registered.
+func LoadAll(ctx context.Context, r *converter.Registry, dirs []string) ([]*Converter, error) {
+	paths, err := Discover(dirs)
+	errs := []error{}
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// Handshake with all plugins at once, then register in discovery order
+	convs := make([]*Converter, len(paths))
+	loadErrs := make([]error, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			convs[i], loadErrs[i] = Load(ctx, path)
+		}()
+	}
+	wg.Wait()
+
+	loaded := []*Converter{}
+	for i, path := range paths {
+		conv, err := convs[i], loadErrs[i]
+		if err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", path, err))
+			continue
+		}
+		if err := r.RegisterFactory(conv.copy); err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", path, err))
+			continue
+		}
+		loaded = append(loaded, conv)
+	}
+
+	return loaded, errors.Join(errs...)
+}
+
+// isExecutable reports whether a file can be run as a plugin
+func isExecutable(info os.FileInfo) bool {
+	if !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(info.Name()), ".exe")
+	}
+	return info.Mode().Perm()&0111 != 0
+}
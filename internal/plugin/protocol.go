@@ -0,0 +1,150 @@
+// Package plugin runs external converter executables through a JSON protocol
+// over stdin/stdout and registers them as converters.
+//
+// Every call starts the plugin once with a single JSON request on stdin. The
+// plugin answers with newline-delimited JSON messages on stdout: any number of
+// "progress" and "log" messages followed by exactly one "result" message
+// (handshake calls answer with a single handshake object instead).
+package plugin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/onedusk/sb/internal/converter"
+)
+
+// ProtocolVersion is the protocol version spoken by this build of sb
+const ProtocolVersion = 1
+
+// Methods of the protocol
+const (
+	MethodHandshake = "handshake"
+	MethodValidate  = "validate"
+	MethodConvert   = "convert"
+)
+
+// Message types sent by plugins during validate and convert calls
+const (
+	MessageProgress = "progress"
+	MessageLog      = "log"
+	MessageResult   = "result"
+)
+
+// Request is sent to the plugin on stdin
+type Request struct {
+	Protocol int    `json:"protocol"`
+	Method   string `json:"method"`
+
+	// validate and convert
+	Input string `json:"input,omitempty"`
+
+	// convert
+	Output          string         `json:"output,omitempty"` // temporary path to write; renamed into place by sb
+	OutputExtension string         `json:"output_extension,omitempty"`
+	Options         map[string]any `json:"options,omitempty"` // values for the handshake option schema
+	Verbose         bool           `json:"verbose,omitempty"`
+}
+
+// Handshake describes the plugin's converter
+type Handshake struct {
+	Protocol        int          `json:"protocol"`
+	Name            string       `json:"name"`
+	Description     string       `json:"description"`
+	Inputs          []string     `json:"inputs"`
+	OutputExtension string       `json:"output_extension"`
+	Options         []OptionSpec `json:"options,omitempty"`
+	Cost            int          `json:"cost,omitempty"`     // planner cost hint (0 = default)
+	Validates       bool         `json:"validate,omitempty"` // plugin implements the validate method
+}
+
+// OptionSpec is the wire form of converter.OptionSpec
+type OptionSpec struct {
+	Name      string   `json:"name"`
+	Short     string   `json:"short,omitempty"`
+	Type      string   `json:"type"`
+	Default   any      `json:"default,omitempty"`
+	Allowed   []string `json:"allowed,omitempty"`
+	Help      string   `json:"help,omitempty"`
+	ConfigKey string   `json:"config_key,omitempty"`
+}
+
+// Message is a line of plugin output during validate and convert calls
+type Message struct {
+	Type string `json:"type"`
+
+	// progress
+	Percentage float64 `json:"percentage,omitempty"`
+	ETASeconds float64 `json:"eta_seconds,omitempty"`
+	Speed      float64 `json:"speed,omitempty"`
+
+	// log
+	Message string `json:"message,omitempty"`
+
+	// result
+	OK    bool   `json:"ok,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Validate checks that a handshake describes a usable converter
+func (h *Handshake) Validate() error {
+	if h.Protocol != ProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %d (want %d)", h.Protocol, ProtocolVersion)
+	}
+	if h.Name == "" {
+		return fmt.Errorf("handshake has no name")
+	}
+	if len(h.Inputs) == 0 {
+		return fmt.Errorf("handshake has no inputs")
+	}
+	if h.OutputExtension == "" {
+		return fmt.Errorf("handshake has no output_extension")
+	}
+	return nil
+}
+
+// Schema converts the wire option specs into a converter option schema
+func (h *Handshake) Schema() ([]converter.OptionSpec, error) {
+	schema := make([]converter.OptionSpec, 0, len(h.Options))
+	for _, o := range h.Options {
+		spec := converter.OptionSpec{
+			Name:      o.Name,
+			Short:     o.Short,
+			Type:      converter.OptionType(o.Type),
+			Allowed:   o.Allowed,
+			Help:      o.Help,
+			ConfigKey: o.ConfigKey,
+		}
+		if spec.Type == "" {
+			spec.Type = converter.OptionString
+		}
+
+		def, err := converter.CoerceValue(spec.Type, o.Default)
+		if err != nil {
+			return nil, fmt.Errorf("option %s: %w", o.Name, err)
+		}
+		spec.Default = def
+		if err := spec.Check(spec.DefaultValue()); err != nil {
+			return nil, err
+		}
+
+		schema = append(schema, spec)
+	}
+	return schema, nil
+}
+
+// encodeValues converts option values to their wire form
+func encodeValues(values converter.OptionValues) map[string]any {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(values))
+	for name, v := range values {
+		if d, ok := v.(time.Duration); ok {
+			out[name] = d.String()
+			continue
+		}
+		out[name] = v
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+package adaptive_stream
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// rung is one rendition of the bitrate/resolution ladder
+type rung struct {
+	name    string // e.g., "720p"; also the HLS variant directory
+	height  int    // output height in pixels
+	bitrate int    // video bitrate in bits per second
+}
+
+// parseLadder parses rungs written as "HEIGHTp:BITRATE" separated by commas,
+// e.g., "1080p:5000k,720p:2800k", and returns them from highest to lowest
+func parseLadder(s string) ([]rung, error) {
+	rungs := []rung{}
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		res, rate, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid ladder rung %q (expected e.g. 720p:2800k)", part)
+		}
+		height, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(res), "p"))
+		if err != nil || height < 2 || height%2 != 0 {
+			return nil, fmt.Errorf("invalid ladder rung %q: height must be an even number of pixels", part)
+		}
+		bitrate, err := parseBitrate(rate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ladder rung %q: %w", part, err)
+		}
+		if seen[height] {
+			return nil, fmt.Errorf("duplicate ladder rung %dp", height)
+		}
+		seen[height] = true
+
+		rungs = append(rungs, rung{name: fmt.Sprintf("%dp", height), height: height, bitrate: bitrate})
+	}
+	if len(rungs) == 0 {
+		return nil, fmt.Errorf("ladder needs at least one rung")
+	}
+
+	sort.Slice(rungs, func(i, j int) bool { return rungs[i].height > rungs[j].height })
+	return rungs, nil
+}
+
+// parseBitrate parses a bitrate such as "800k", "5M" or "128000" into bits
+// per second (decimal units, as ffmpeg uses for bitrates)
+func parseBitrate(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	mult := 1
+	switch {
+	case strings.HasSuffix(s, "k"), strings.HasSuffix(s, "K"):
+		mult = 1000
+	case strings.HasSuffix(s, "m"), strings.HasSuffix(s, "M"):
+		mult = 1000 * 1000
+	}
+	if mult > 1 {
+		s = s[:len(s)-1]
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid bitrate %q", s)
+	}
+	return int(n * float64(mult)), nil
+}
+
+// selectRungs drops rungs above the source height. A source smaller than
+// every rung gets a single rendition at its own height with the bitrate of
+// the lowest rung.
+func selectRungs(rungs []rung, sourceHeight int) []rung {
+	if sourceHeight <= 0 {
+		return rungs
+	}
+
+	selected := []rung{}
+	for _, r := range rungs {
+		if r.height <= sourceHeight {
+			selected = append(selected, r)
+		}
+	}
+	if len(selected) > 0 {
+		return selected
+	}
+
+	lowest := rungs[len(rungs)-1]
+	height := max(2, sourceHeight/2*2)
+	return []rung{{name: fmt.Sprintf("%dp", height), height: height, bitrate: lowest.bitrate}}
+}
@@ -0,0 +1,268 @@
+package adaptive_stream
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+)
+
+// DefaultLadder is the default bitrate/resolution ladder
+const DefaultLadder = "1080p:5000k,720p:2800k,480p:1400k,360p:800k"
+
+// StreamOptions contains adaptive streaming packaging options
+type StreamOptions struct {
+	// Ladder lists the renditions as "HEIGHTp:BITRATE" rungs
+	Ladder string
+
+	// Segments is the HLS segment container: fmp4 or ts
+	Segments        string
+	SegmentDuration time.Duration
+
+	// DASH also writes an MPD manifest (fMP4 segments only)
+	DASH bool
+
+	// Video encoding
+	Codec  string // h264 or hevc
+	Preset string // x264/x265 preset
+
+	// Audio
+	Audio        string // aac or none
+	AudioBitrate string // e.g., "128k"
+
+	// Toolchain selects a named ffmpeg/ffprobe pair (empty = default)
+	Toolchain string
+}
+
+// DefaultStreamOptions returns default packaging options
+func DefaultStreamOptions() StreamOptions {
+	return StreamOptions{
+		Ladder:          DefaultLadder,
+		Segments:        "fmp4",
+		SegmentDuration: 6 * time.Second,
+		Codec:           "h264",
+		Preset:          "fast",
+		Audio:           "aac",
+		AudioBitrate:    "128k",
+	}
+}
+
+// optionSchema declares the options exposed on the command line and in the
+// "hls" config section
+func optionSchema() []converter.OptionSpec {
+	defaults := DefaultStreamOptions()
+	return []converter.OptionSpec{
+		{Name: "ladder", Short: "l", Type: converter.OptionString, Default: defaults.Ladder,
+			Help: "renditions as HEIGHTp:BITRATE rungs (e.g., 1080p:5000k,720p:2800k)"},
+		{Name: "segments", Type: converter.OptionString, Default: defaults.Segments,
+			Allowed: []string{"fmp4", "ts"},
+			Help:    "segment container"},
+		{Name: "segment-duration", Type: converter.OptionDuration, Default: defaults.SegmentDuration,
+			Help: "target segment length; keyframes are aligned to it"},
+		{Name: "dash", Type: converter.OptionBool, Default: defaults.DASH,
+			Help: "also write a DASH manifest (fmp4 segments only)"},
+		{Name: "codec", Short: "c", Type: converter.OptionString, Default: defaults.Codec,
+			Allowed: []string{"h264", "hevc"},
+			Help:    "video codec"},
+		{Name: "preset", Short: "p", Type: converter.OptionString, Default: defaults.Preset,
+			Allowed: []string{"ultrafast", "superfast", "veryfast", "faster", "fast", "medium", "slow", "slower", "veryslow"},
+			Help:    "encoding preset"},
+		{Name: "audio", Type: converter.OptionString, Default: defaults.Audio,
+			Allowed: []string{"aac", "none"},
+			Help:    "audio codec"},
+		{Name: "audio-bitrate", Type: converter.OptionString, Default: defaults.AudioBitrate,
+			Help: "audio bitrate (e.g., 96k, 128k)"},
+		{Name: "toolchain", Type: converter.OptionString,
+			Help: "named ffmpeg toolchain from config"},
+	}
+}
+
+// optionsFromValues builds StreamOptions from resolved schema values
+func optionsFromValues(values converter.OptionValues) StreamOptions {
+	opts := DefaultStreamOptions()
+	opts.Ladder = values.String("ladder")
+	opts.Segments = values.String("segments")
+	opts.SegmentDuration = values.Duration("segment-duration")
+	opts.DASH = values.Bool("dash")
+	opts.Codec = values.String("codec")
+	opts.Preset = values.String("preset")
+	opts.Audio = values.String("audio")
+	opts.AudioBitrate = values.String("audio-bitrate")
+	opts.Toolchain = values.String("toolchain")
+	return opts
+}
+
+// Validate checks if options are valid
+func (o *StreamOptions) Validate() error {
+	if _, err := parseLadder(o.Ladder); err != nil {
+		return err
+	}
+	switch o.Segments {
+	case "fmp4", "ts":
+	default:
+		return fmt.Errorf("invalid segment container %q (allowed: fmp4, ts)", o.Segments)
+	}
+	if o.DASH && o.Segments != "fmp4" {
+		return fmt.Errorf("DASH needs fmp4 segments; use --segments fmp4")
+	}
+	if o.SegmentDuration < time.Second {
+		return fmt.Errorf("segment-duration must be at least 1s")
+	}
+	switch o.Codec {
+	case "h264", "hevc":
+	default:
+		return fmt.Errorf("invalid codec %q (allowed: h264, hevc)", o.Codec)
+	}
+	switch o.Audio {
+	case "aac", "none":
+	default:
+		return fmt.Errorf("invalid audio codec %q (allowed: aac, none)", o.Audio)
+	}
+	return nil
+}
+
+// Requirements returns the ffmpeg components these options depend on
+func (o *StreamOptions) Requirements() executor.Requirements {
+	req := executor.Requirements{
+		Encoders: []string{o.encoder()},
+		Muxers:   []string{o.muxer()},
+		Filters:  []string{"split", "scale"},
+	}
+	if o.Audio != "none" {
+		req.Encoders = append(req.Encoders, executor.AudioEncoderFor(o.Audio))
+	}
+	return req
+}
+
+// encoder returns the ffmpeg video encoder
+func (o *StreamOptions) encoder() string {
+	return executor.EncoderFor(o.Codec, "")
+}
+
+// muxer returns the ffmpeg muxer writing the playlists
+func (o *StreamOptions) muxer() string {
+	if o.DASH {
+		return "dash"
+	}
+	return "hls"
+}
+
+// encodeArgs returns the input, filter and encoding arguments shared by the
+// HLS and DASH muxers: the video is split into one scaled stream per rung,
+// and the audio is encoded once for all of them
+func (o *StreamOptions) encodeArgs(input string, rungs []rung, audio bool) []string {
+	graph := fmt.Sprintf("[0:v]split=%d", len(rungs))
+	for i := range rungs {
+		graph += fmt.Sprintf("[s%d]", i)
+	}
+	for i, r := range rungs {
+		graph += fmt.Sprintf(";[s%d]scale=-2:%d[v%d]", i, r.height, i)
+	}
+
+	args := []string{"-y", "-i", input, "-filter_complex", graph}
+	for i := range rungs {
+		args = append(args, "-map", fmt.Sprintf("[v%d]", i))
+	}
+	if audio {
+		args = append(args, "-map", "0:a:0")
+	}
+
+	// Keyframes at every segment boundary, and none elsewhere from scene
+	// cuts, keep the GOPs of all renditions aligned for switching
+	seg := strconv.FormatFloat(o.SegmentDuration.Seconds(), 'f', -1, 64)
+	args = append(args,
+		"-c:v", o.encoder(), "-preset", o.Preset, "-pix_fmt", "yuv420p",
+		"-force_key_frames", "expr:gte(t,n_forced*"+seg+")")
+	if o.Codec == "hevc" {
+		args = append(args, "-x265-params", "scenecut=0:open-gop=0", "-tag:v", "hvc1")
+	} else {
+		args = append(args, "-sc_threshold", "0")
+	}
+
+	// Capped bitrates with a 1.5x buffer, per rendition
+	for i, r := range rungs {
+		args = append(args,
+			fmt.Sprintf("-b:v:%d", i), strconv.Itoa(r.bitrate),
+			fmt.Sprintf("-maxrate:v:%d", i), strconv.Itoa(r.bitrate*107/100),
+			fmt.Sprintf("-bufsize:v:%d", i), strconv.Itoa(r.bitrate*3/2))
+	}
+
+	if audio {
+		args = append(args, "-c:a", executor.AudioEncoderFor(o.Audio), "-ac", "2")
+		if o.AudioBitrate != "" {
+			args = append(args, "-b:a", o.AudioBitrate)
+		}
+	}
+	return args
+}
+
+// hlsArgs packages into dir with one subdirectory per rung holding its
+// media playlist and segments, and the master playlist named master
+func (o *StreamOptions) hlsArgs(input, dir, master string, rungs []rung, audio bool) []string {
+	streams := make([]string, 0, len(rungs)+1)
+	for i, r := range rungs {
+		s := fmt.Sprintf("v:%d,name:%s", i, r.name)
+		if audio {
+			s = fmt.Sprintf("v:%d,agroup:audio,name:%s", i, r.name)
+		}
+		streams = append(streams, s)
+	}
+	if audio {
+		streams = append(streams, "a:0,agroup:audio,name:audio")
+	}
+
+	segment := "seg_%05d.ts"
+	segmentType := "mpegts"
+	if o.Segments == "fmp4" {
+		segment = "seg_%05d.m4s"
+		segmentType = "fmp4"
+	}
+
+	args := append(o.encodeArgs(input, rungs, audio),
+		"-f", "hls",
+		"-hls_time", strconv.FormatFloat(o.SegmentDuration.Seconds(), 'f', -1, 64),
+		"-hls_playlist_type", "vod",
+		"-hls_flags", "independent_segments",
+		"-hls_segment_type", segmentType,
+		"-hls_segment_filename", filepath.Join(dir, "%v", segment))
+	if o.Segments == "fmp4" {
+		args = append(args, "-hls_fmp4_init_filename", "init.mp4")
+	}
+	return append(args,
+		"-master_pl_name", master,
+		"-var_stream_map", strings.Join(streams, " "),
+		filepath.Join(dir, "%v", "index.m3u8"))
+}
+
+// dashArgs packages into dir as DASH with an MPD manifest next to the HLS
+// master playlist, both referencing the same fMP4 segments
+func (o *StreamOptions) dashArgs(input, dir, master string, rungs []rung, audio bool) []string {
+	sets := "id=0,streams=v"
+	if audio {
+		sets += " id=1,streams=a"
+	}
+
+	args := append(o.encodeArgs(input, rungs, audio),
+		"-f", "dash",
+		"-seg_duration", strconv.FormatFloat(o.SegmentDuration.Seconds(), 'f', -1, 64),
+		"-use_template", "1",
+		"-use_timeline", "1",
+		"-adaptation_sets", sets,
+		"-init_seg_name", "init-$RepresentationID$.$ext$",
+		"-media_seg_name", "chunk-$RepresentationID$-$Number%05d$.$ext$",
+		"-hls_playlist", "1")
+	if master != "master.m3u8" {
+		args = append(args, "-hls_master_name", master)
+	}
+	return append(args, filepath.Join(dir, manifestName(master)))
+}
+
+// manifestName returns the DASH manifest written next to the master
+// playlist, e.g., "master.m3u8" -> "master.mpd"
+func manifestName(master string) string {
+	return strings.TrimSuffix(master, filepath.Ext(master)) + ".mpd"
+}
@@ -0,0 +1,254 @@
+package adaptive_stream
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/fsutil"
+	"github.com/onedusk/sb/internal/media"
+)
+
+// masterName is the master playlist of the default per-input directory
+const masterName = "master.m3u8"
+
+func init() {
+	// Auto-register this converter
+	converter.RegisterFactory(New)
+}
+
+// New creates an HLS/DASH packager ready for registration
+func New() converter.Converter {
+	return NewHLSConverter()
+}
+
+// HLSConverter packages a video for adaptive streaming: it encodes a
+// bitrate/resolution ladder in one ffmpeg run and writes HLS playlists and
+// segments (optionally with a DASH manifest) to a directory per input
+type HLSConverter struct {
+	mu      sync.Mutex
+	ffmpeg  executor.Lazy
+	options StreamOptions
+}
+
+// NewHLSConverter creates a new HLS/DASH packager
+func NewHLSConverter() *HLSConverter {
+	return &HLSConverter{
+		options: DefaultStreamOptions(),
+	}
+}
+
+// Name returns the converter name
+func (c *HLSConverter) Name() string {
+	return "hls"
+}
+
+// Description returns the converter description
+func (c *HLSConverter) Description() string {
+	return "Package video for adaptive streaming (HLS, optionally DASH)"
+}
+
+// SupportedInputs returns supported input formats
+func (c *HLSConverter) SupportedInputs() []string {
+	return slices.Clone(media.VideoInputs)
+}
+
+// OutputExtension returns the output extension (of the master playlist)
+func (c *HLSConverter) OutputExtension() string {
+	return ".m3u8"
+}
+
+// Cost returns the planner cost hint; every rung is a full encode
+func (c *HLSConverter) Cost() int {
+	return 40
+}
+
+// OutputPath places the master playlist in a directory named after the
+// input, e.g., "clip.mov" -> "clip/master.m3u8"
+func (c *HLSConverter) OutputPath(input string, opts converter.Options) string {
+	p := converter.DefaultOutputPath(c, input, opts)
+	return filepath.Join(strings.TrimSuffix(p, filepath.Ext(p)), masterName)
+}
+
+// Validate checks if the input file is valid
+func (c *HLSConverter) Validate(input string) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return fmt.Errorf("cannot access file: %w", err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("input is a directory, not a file")
+	}
+
+	return media.CheckInput(input, c.SupportedInputs())
+}
+
+// Convert processes a single file. Everything is written to a partial
+// directory next to the master playlist and moved into place on success,
+// the master playlist and DASH manifest last, so they never list segments
+// that are not in place yet. A failed or interrupted run leaves no new
+// files; one that fails while moving may leave new segments next to the
+// previous playlists.
+func (c *HLSConverter) Convert(ctx context.Context, job converter.Job) (*converter.Result, error) {
+	input, output, opts := job.Input, job.Output, job.Options
+
+	c.mu.Lock()
+	options := c.options
+	c.mu.Unlock()
+
+	var (
+		ff    executor.Executor
+		info  *media.MediaInfo
+		rungs []rung
+		audio bool
+		size  int64
+	)
+	master := filepath.Base(output)
+	return converter.RunJob(ctx, job, converter.Task{
+		Validate: c.Validate,
+		// Rungs above the source resolution are skipped
+		Prepare: func() error {
+			var err error
+			if ff, err = c.ffmpeg.Get(); err != nil {
+				return err
+			}
+			if info, err = ff.GetInfo(ctx, input); err != nil {
+				return fmt.Errorf("failed to probe input: %w", err)
+			}
+			video := info.PrimaryVideo()
+			if video == nil {
+				return fmt.Errorf("no video stream in input")
+			}
+			_, height := video.DisplaySize()
+			ladder, _ := parseLadder(options.Ladder) // validated by SetOptions
+			rungs = selectRungs(ladder, height)
+			audio = options.Audio != "none" && info.PrimaryAudio() != nil
+			return nil
+		},
+		Preview: func() {
+			names := make([]string, len(rungs))
+			for i, r := range rungs {
+				names[i] = r.name
+			}
+			fmt.Printf("[DRY-RUN] Would convert: %s -> %s (%s)\n", input, output, strings.Join(names, ", "))
+		},
+		Encode: func(partial string) (*executor.FFmpegResult, error) {
+			if err := c.prepare(partial, rungs, audio, options); err != nil {
+				return nil, fmt.Errorf("failed to create output directory: %w", err)
+			}
+
+			args := options.hlsArgs(input, partial, master, rungs, audio)
+			if options.DASH {
+				args = options.dashArgs(input, partial, master, rungs, audio)
+			}
+			return ff.Run(ctx, args, converter.RunOptionsFor(opts, input, info.Duration(), 0, 1))
+		},
+		Commit: func(partial string) error {
+			var err error
+			if size, err = dirSize(partial); err != nil {
+				return err
+			}
+			return fsutil.CommitDir(partial, filepath.Dir(output), manifestName(master), master)
+		},
+		Discard: func(partial string) {
+			fsutil.DiscardDir(partial)
+		},
+		Finish: func(result *converter.Result) {
+			result.OutputSize = size
+		},
+	})
+}
+
+// prepare creates an empty partial directory, with the HLS variant
+// directories the muxer writes into
+func (c *HLSConverter) prepare(partial string, rungs []rung, audio bool, options StreamOptions) error {
+	if err := fsutil.DiscardDir(partial); err != nil {
+		return err
+	}
+	if options.DASH {
+		return os.MkdirAll(partial, 0755)
+	}
+
+	dirs := []string{}
+	for _, r := range rungs {
+		dirs = append(dirs, r.name)
+	}
+	if audio {
+		dirs = append(dirs, "audio")
+	}
+	for _, d := range dirs {
+		if err := os.MkdirAll(filepath.Join(partial, d), 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dirSize returns the total size of the files under dir
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// Preflight verifies that ffmpeg has the encoders and muxer
+func (c *HLSConverter) Preflight() error {
+	ff, err := c.ffmpeg.Get()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	req := c.options.Requirements()
+	c.mu.Unlock()
+
+	return ff.Preflight(req)
+}
+
+// OptionSchema describes the options of the hls converter
+func (c *HLSConverter) OptionSchema() []converter.OptionSpec {
+	return optionSchema()
+}
+
+// Configure applies option values resolved against OptionSchema
+func (c *HLSConverter) Configure(values converter.OptionValues) error {
+	return c.SetOptions(optionsFromValues(values))
+}
+
+// SetOptions sets converter-specific options
+func (c *HLSConverter) SetOptions(opts StreamOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	c.ffmpeg.SetToolchain(opts.Toolchain)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options = opts
+	return nil
+}
+
+// SetExecutor overrides the executor used for conversions
+func (c *HLSConverter) SetExecutor(e executor.Executor) {
+	c.ffmpeg.Set(e)
+}
@@ -0,0 +1,54 @@
+// Package all links every built-in converter into the binary; importing it
+// registers them with the default converter registry
+package all
+
+import (
+	"errors"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/processors/adaptive_stream"
+	"github.com/onedusk/sb/internal/processors/extract_audio"
+	"github.com/onedusk/sb/internal/processors/heic_to_jpg"
+	"github.com/onedusk/sb/internal/processors/image_resize"
+	"github.com/onedusk/sb/internal/processors/loudness_normalize"
+	"github.com/onedusk/sb/internal/processors/mov_to_mp4"
+	"github.com/onedusk/sb/internal/processors/video_thumbnails"
+	"github.com/onedusk/sb/internal/processors/video_to_gif"
+	"github.com/onedusk/sb/internal/processors/web_video"
+)
+
+// Factories returns a factory for every built-in converter
+func Factories() []converter.Factory {
+	factories := []converter.Factory{
+		mov_to_mp4.New,         // MOV/AVI/MKV/... -> MP4
+		heic_to_jpg.New,        // HEIC/HEIF -> JPEG
+		image_resize.New,       // JPEG/PNG/WebP/GIF/TIFF/BMP resize and conversion
+		video_to_gif.New,       // video -> GIF
+		video_thumbnails.New,   // video -> poster/thumbnails/contact sheet
+		adaptive_stream.New,    // video -> HLS/DASH ladder
+		loudness_normalize.New, // audio -> EBU R128 normalized audio
+	}
+	factories = append(factories, extract_audio.Factories()...) // video/audio -> MP3/AAC/FLAC/Opus/WAV
+	factories = append(factories, web_video.Factories()...)     // video -> WebM (VP9/AV1), AV1 in MP4
+	return factories
+}
+
+// Builtins returns new instances of the built-in converters
+func Builtins() []converter.Converter {
+	convs := []converter.Converter{}
+	for _, factory := range Factories() {
+		convs = append(convs, factory())
+	}
+	return convs
+}
+
+// Register adds the built-in converters to r, with their factories
+func Register(r *converter.Registry) error {
+	var errs []error
+	for _, factory := range Factories() {
+		if err := r.RegisterFactory(factory); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
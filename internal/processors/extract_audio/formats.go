@@ -0,0 +1,107 @@
+package extract_audio
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/onedusk/sb/internal/media"
+)
+
+// format describes one output format of the audio extractor
+type format struct {
+	name        string   // converter name, e.g., "mp3"
+	description string   // converter description
+	ext         string   // output extension
+	muxer       string   // ffmpeg muxer
+	encoders    []string // ffmpeg encoders, default first
+	bitrate     string   // default bitrate ("" = lossless, no bitrate option)
+	vbr         [2]int   // VBR quality range of the default encoder ({0, 0} = none)
+	vbrHelp     string   // meaning of the VBR quality scale
+}
+
+// formats lists the supported output formats by converter name
+var formats = map[string]format{
+	"mp3": {
+		name:        "mp3",
+		description: "Extract audio as MP3 (LAME)",
+		ext:         ".mp3",
+		muxer:       "mp3",
+		encoders:    []string{"libmp3lame"},
+		bitrate:     "192k",
+		vbr:         [2]int{0, 9},
+		vbrHelp:     "LAME VBR quality (0-9, lower = better; -1 = use bitrate)",
+	},
+	"aac": {
+		name:        "aac",
+		description: "Extract audio as AAC in an M4A file",
+		ext:         ".m4a",
+		muxer:       "ipod",
+		encoders:    []string{"aac", "libfdk_aac", "aac_at"},
+		bitrate:     "192k",
+		vbr:         [2]int{1, 5},
+		vbrHelp:     "VBR mode of libfdk_aac (1-5, higher = better; -1 = use bitrate)",
+	},
+	"flac": {
+		name:        "flac",
+		description: "Extract audio as FLAC (lossless)",
+		ext:         ".flac",
+		muxer:       "flac",
+		encoders:    []string{"flac"},
+	},
+	"opus": {
+		name:        "opus",
+		description: "Extract audio as Opus",
+		ext:         ".opus",
+		muxer:       "opus",
+		encoders:    []string{"libopus", "opus"},
+		bitrate:     "128k",
+	},
+	"wav": {
+		name:        "wav",
+		description: "Extract audio as WAV (PCM)",
+		ext:         ".wav",
+		muxer:       "wav",
+		encoders:    []string{"pcm_s16le", "pcm_s24le", "pcm_f32le"},
+	},
+}
+
+// Formats returns the names of the supported output formats
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupFormat returns the format with the given name
+func lookupFormat(name string) (format, error) {
+	f, ok := formats[name]
+	if !ok {
+		return format{}, fmt.Errorf("unknown audio format %q (supported: %s)", name, strings.Join(Formats(), ", "))
+	}
+	return f, nil
+}
+
+// codecOf returns the codec name ffprobe reports for streams written by
+// encoder, e.g., "libmp3lame" -> "mp3"
+func codecOf(encoder string) string {
+	switch encoder {
+	case "libmp3lame":
+		return "mp3"
+	case "libfdk_aac", "aac_at":
+		return "aac"
+	case "libopus":
+		return "opus"
+	}
+	return encoder
+}
+
+// supportedInputs returns the extensions audio can be extracted from: the
+// video and audio formats
+func supportedInputs() []string {
+	return slices.Concat(media.VideoInputs, media.AudioExtensions)
+}
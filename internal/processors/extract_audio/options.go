@@ -0,0 +1,184 @@
+package extract_audio
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+)
+
+// AudioOptions contains audio extraction options
+type AudioOptions struct {
+	// Codec is the ffmpeg encoder (default: the format's first encoder)
+	Codec string
+
+	// Bitrate is the target bitrate of lossy formats, e.g., "192k"
+	Bitrate string
+
+	// VBR is the variable bitrate quality; -1 uses Bitrate instead
+	VBR int
+
+	// SampleRate resamples the audio in Hz (0 = keep the source rate)
+	SampleRate int
+
+	// Channels sets the channel layout: mono, stereo, 5.1 or 7.1 (empty = source)
+	Channels string
+
+	// Stream selects the audio stream: its position among the audio streams
+	// ("0", "1", ...) or a language code ("eng"); empty = the default stream
+	Stream string
+
+	// Copy copies the stream without re-encoding when the source codec
+	// already matches and no resampling or remixing is requested
+	Copy bool
+
+	// Tags carries container tags (title, artist, creation date, ...) over
+	// into ID3/Vorbis comments/MP4 tags
+	Tags bool
+
+	// Toolchain selects a named ffmpeg/ffprobe pair (empty = default)
+	Toolchain string
+}
+
+// DefaultAudioOptions returns default options for a format
+func DefaultAudioOptions(f format) AudioOptions {
+	return AudioOptions{
+		Codec:   f.encoders[0],
+		Bitrate: f.bitrate,
+		VBR:     -1,
+		Copy:    true,
+		Tags:    true,
+	}
+}
+
+// channelCounts maps channel layouts onto channel counts
+var channelCounts = map[string]int{
+	"mono":   1,
+	"stereo": 2,
+	"5.1":    6,
+	"7.1":    8,
+}
+
+// optionSchema declares the options exposed on the command line and in the
+// config section of a format
+func optionSchema(f format) []converter.OptionSpec {
+	defaults := DefaultAudioOptions(f)
+	schema := []converter.OptionSpec{
+		{Name: "codec", Short: "c", Type: converter.OptionString, Default: defaults.Codec,
+			Allowed: f.encoders,
+			Help:    "audio encoder"},
+	}
+	if f.bitrate != "" {
+		schema = append(schema, converter.OptionSpec{
+			Name: "bitrate", Short: "b", Type: converter.OptionString, Default: defaults.Bitrate,
+			Help: "audio bitrate (e.g., 128k, 192k)"})
+	}
+	if f.vbrHelp != "" {
+		schema = append(schema, converter.OptionSpec{
+			Name: "vbr", Type: converter.OptionInt, Default: defaults.VBR,
+			Help: f.vbrHelp})
+	}
+	return append(schema,
+		converter.OptionSpec{Name: "sample-rate", Type: converter.OptionInt,
+			Help: "sample rate in Hz (e.g., 44100, 48000; 0 = source)"},
+		converter.OptionSpec{Name: "channels", Type: converter.OptionString,
+			Allowed: []string{"mono", "stereo", "5.1", "7.1"},
+			Help:    "channel layout (empty = source)"},
+		converter.OptionSpec{Name: "stream", Type: converter.OptionString,
+			Help: "audio stream: index among audio streams (0, 1, ...) or language (e.g., eng)"},
+		converter.OptionSpec{Name: "copy", Type: converter.OptionBool, Default: defaults.Copy,
+			Help: "copy the stream without re-encoding when the source codec matches"},
+		converter.OptionSpec{Name: "tags", Type: converter.OptionBool, Default: defaults.Tags,
+			Help: "carry over tags (title, artist, creation date)"},
+		converter.OptionSpec{Name: "toolchain", Type: converter.OptionString,
+			Help: "named ffmpeg toolchain from config"},
+	)
+}
+
+// optionsFromValues builds AudioOptions from resolved schema values
+func optionsFromValues(f format, values converter.OptionValues) AudioOptions {
+	opts := DefaultAudioOptions(f)
+	opts.Codec = values.String("codec")
+	if f.bitrate != "" {
+		opts.Bitrate = values.String("bitrate")
+	}
+	if f.vbrHelp != "" {
+		opts.VBR = values.Int("vbr")
+	}
+	opts.SampleRate = values.Int("sample-rate")
+	opts.Channels = values.String("channels")
+	opts.Stream = values.String("stream")
+	opts.Copy = values.Bool("copy")
+	opts.Tags = values.Bool("tags")
+	opts.Toolchain = values.String("toolchain")
+	return opts
+}
+
+// Validate checks the options against the format
+func (o *AudioOptions) Validate(f format) error {
+	if o.Codec == "" {
+		o.Codec = f.encoders[0]
+	}
+	if !slices.Contains(f.encoders, o.Codec) {
+		return fmt.Errorf("invalid codec %q for %s (allowed: %s)", o.Codec, f.name, strings.Join(f.encoders, ", "))
+	}
+
+	if o.VBR >= 0 {
+		if f.vbrHelp == "" {
+			return fmt.Errorf("%s has no VBR quality setting", f.name)
+		}
+		if f.name == "aac" && o.Codec != "libfdk_aac" {
+			return fmt.Errorf("VBR quality requires the libfdk_aac encoder, not %s", o.Codec)
+		}
+		if o.VBR < f.vbr[0] || o.VBR > f.vbr[1] {
+			return fmt.Errorf("vbr must be between %d and %d, got %d", f.vbr[0], f.vbr[1], o.VBR)
+		}
+	}
+
+	if o.SampleRate < 0 {
+		return fmt.Errorf("sample rate cannot be negative")
+	}
+	if o.Channels != "" && channelCounts[o.Channels] == 0 {
+		return fmt.Errorf("invalid channel layout %q", o.Channels)
+	}
+	return nil
+}
+
+// Requirements returns the ffmpeg components these options depend on
+func (o *AudioOptions) Requirements(f format) executor.Requirements {
+	return executor.Requirements{
+		Encoders: []string{o.Codec},
+		Muxers:   []string{f.muxer},
+	}
+}
+
+// reencodes reports whether the options change the audio itself, which
+// rules out a stream copy
+func (o *AudioOptions) reencodes() bool {
+	return o.SampleRate > 0 || o.Channels != ""
+}
+
+// encodeArgs returns the codec arguments for a re-encode
+func (o *AudioOptions) encodeArgs(f format) []string {
+	args := []string{"-c:a", o.Codec}
+
+	switch {
+	case o.VBR >= 0 && o.Codec == "libfdk_aac":
+		args = append(args, "-vbr", strconv.Itoa(o.VBR))
+	case o.VBR >= 0:
+		args = append(args, "-q:a", strconv.Itoa(o.VBR))
+	case f.bitrate != "" && o.Bitrate != "":
+		args = append(args, "-b:a", o.Bitrate)
+	}
+
+	if o.SampleRate > 0 {
+		args = append(args, "-ar", strconv.Itoa(o.SampleRate))
+	}
+	if n := channelCounts[o.Channels]; n > 0 {
+		args = append(args, "-ac", strconv.Itoa(n))
+	}
+	return args
+}
@@ -0,0 +1,252 @@
+package extract_audio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/ui"
+)
+
+func init() {
+	// Auto-register one converter per output format
+	for _, factory := range Factories() {
+		converter.RegisterFactory(factory)
+	}
+}
+
+// Factories returns a factory for each of the audio extraction converters (mp3, aac, flac, opus, wav)
+func Factories() []converter.Factory {
+	factories := []converter.Factory{}
+	for _, name := range Formats() {
+		factories = append(factories, func() converter.Converter {
+			conv, _ := NewAudioConverter(name)
+			return conv
+		})
+	}
+	return factories
+}
+
+// AudioConverter extracts an audio stream from video or audio files into
+// one output format
+type AudioConverter struct {
+	format format
+
+	mu      sync.Mutex
+	ffmpeg  executor.Lazy
+	options AudioOptions
+}
+
+// NewAudioConverter creates an audio converter for the named format (see Formats)
+func NewAudioConverter(name string) (*AudioConverter, error) {
+	f, err := lookupFormat(name)
+	if err != nil {
+		return nil, err
+	}
+	return &AudioConverter{
+		format:  f,
+		options: DefaultAudioOptions(f),
+	}, nil
+}
+
+// Name returns the converter name
+func (c *AudioConverter) Name() string {
+	return c.format.name
+}
+
+// Description returns the converter description
+func (c *AudioConverter) Description() string {
+	return c.format.description
+}
+
+// SupportedInputs returns supported input formats
+func (c *AudioConverter) SupportedInputs() []string {
+	return supportedInputs()
+}
+
+// OutputExtension returns the output extension
+func (c *AudioConverter) OutputExtension() string {
+	return c.format.ext
+}
+
+// Cost returns the planner cost hint; audio encodes are cheap next to video
+func (c *AudioConverter) Cost() int {
+	return 5
+}
+
+// Validate checks if the input file is valid
+func (c *AudioConverter) Validate(input string) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return fmt.Errorf("cannot access file: %w", err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("input is a directory, not a file")
+	}
+
+	return media.CheckInput(input, c.SupportedInputs())
+}
+
+// Convert processes a single file
+func (c *AudioConverter) Convert(ctx context.Context, job converter.Job) (*converter.Result, error) {
+	input, opts := job.Input, job.Options
+
+	c.mu.Lock()
+	options := c.options
+	c.mu.Unlock()
+
+	var (
+		ff     executor.Executor
+		info   *media.MediaInfo
+		stream *media.AudioStream
+	)
+	return converter.RunJob(ctx, job, converter.Task{
+		Validate: c.Validate,
+		// Probe before the dry run so missing streams surface in previews too
+		Prepare: func() error {
+			var err error
+			if ff, err = c.ffmpeg.Get(); err != nil {
+				return err
+			}
+			if info, err = ff.GetInfo(ctx, input); err != nil {
+				return fmt.Errorf("failed to probe input: %w", err)
+			}
+			stream, err = selectStream(info, options.Stream)
+			return err
+		},
+		Encode: func(partial string) (*executor.FFmpegResult, error) {
+			args, copied := c.buildArgs(input, partial, info, stream, options)
+			if copied {
+				ui.PrintVerbose(opts.Verbose, "Copying %s stream %d of %s", stream.Codec, stream.Index, input)
+			}
+			return ff.Run(ctx, args, converter.RunOptionsFor(opts, input, info.Duration(), 0, 1))
+		},
+	})
+}
+
+// buildArgs returns the ffmpeg arguments extracting stream from input and
+// whether the stream is copied rather than re-encoded
+func (c *AudioConverter) buildArgs(input, output string, info *media.MediaInfo, stream *media.AudioStream, options AudioOptions) ([]string, bool) {
+	args := []string{"-y", "-i", input, "-map", fmt.Sprintf("0:%d", stream.Index)}
+
+	copied := options.Copy && !options.reencodes() && stream.Codec == codecOf(options.Codec)
+	if copied {
+		args = append(args, "-c:a", "copy")
+	} else {
+		args = append(args, options.encodeArgs(c.format)...)
+	}
+
+	if options.Tags {
+		args = append(args, "-map_metadata", "0")
+		if date := recordingDate(info.Format.Tags); date != "" {
+			args = append(args, "-metadata", "date="+date)
+		}
+	} else {
+		args = append(args, "-map_metadata", "-1")
+	}
+
+	return append(args, "-f", c.format.muxer, output), copied
+}
+
+// selectStream picks the audio stream named by sel: a position among the
+// audio streams, a language code, or the default stream when empty
+func selectStream(info *media.MediaInfo, sel string) (*media.AudioStream, error) {
+	if len(info.Audio) == 0 {
+		return nil, fmt.Errorf("no audio stream in input")
+	}
+	if sel == "" {
+		return info.PrimaryAudio(), nil
+	}
+
+	if n, err := strconv.Atoi(sel); err == nil {
+		if n < 0 || n >= len(info.Audio) {
+			return nil, fmt.Errorf("audio stream %d not found (input has %d)", n, len(info.Audio))
+		}
+		return &info.Audio[n], nil
+	}
+
+	// Prefer the default stream among those in the language
+	var match *media.AudioStream
+	for i := range info.Audio {
+		if !strings.EqualFold(info.Audio[i].Language, sel) {
+			continue
+		}
+		if match == nil || info.Audio[i].Default && !match.Default {
+			match = &info.Audio[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no audio stream in language %q", sel)
+	}
+	return match, nil
+}
+
+// recordingDate returns the date to tag the output with: the container's
+// creation time as YYYY-MM-DD when it has no date tag of its own
+func recordingDate(tags map[string]string) string {
+	for key := range tags {
+		if strings.EqualFold(key, "date") {
+			return ""
+		}
+	}
+	created, ok := tags["creation_time"]
+	if !ok {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339Nano, created)
+	if err != nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// Preflight verifies that ffmpeg has the configured encoder and muxer
+func (c *AudioConverter) Preflight() error {
+	ff, err := c.ffmpeg.Get()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	req := c.options.Requirements(c.format)
+	c.mu.Unlock()
+
+	return ff.Preflight(req)
+}
+
+// OptionSchema describes the options of the format
+func (c *AudioConverter) OptionSchema() []converter.OptionSpec {
+	return optionSchema(c.format)
+}
+
+// Configure applies option values resolved against OptionSchema
+func (c *AudioConverter) Configure(values converter.OptionValues) error {
+	return c.SetOptions(optionsFromValues(c.format, values))
+}
+
+// SetOptions sets converter-specific options
+func (c *AudioConverter) SetOptions(opts AudioOptions) error {
+	if err := opts.Validate(c.format); err != nil {
+		return err
+	}
+
+	c.ffmpeg.SetToolchain(opts.Toolchain)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options = opts
+	return nil
+}
+
+// SetExecutor overrides the executor used for conversions
+func (c *AudioConverter) SetExecutor(e executor.Executor) {
+	c.ffmpeg.Set(e)
+}
@@ -0,0 +1,113 @@
+package heic_to_jpg
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+)
+
+// JPGOptions contains JPEG-specific conversion options
+type JPGOptions struct {
+	// Quality is the JPEG quality (1-100, higher = better; default: 92)
+	Quality int
+
+	// AutoOrient rotates the pixels upright and resets the EXIF orientation;
+	// otherwise the stored pixels are kept and the orientation is recorded
+	AutoOrient bool
+
+	// Exif copies the EXIF metadata (capture date, camera, GPS, ...)
+	Exif bool
+
+	// AllImages also writes the other images of a burst as <name>_2.jpg, ...
+	AllImages bool
+
+	// Toolchain selects a named ffmpeg/ffprobe pair (empty = default)
+	Toolchain string
+}
+
+// DefaultJPGOptions returns default options for JPEG conversion
+func DefaultJPGOptions() JPGOptions {
+	return JPGOptions{
+		Quality:    92,
+		AutoOrient: true,
+		Exif:       true,
+	}
+}
+
+// optionSchema declares the options exposed on the command line and in the
+// "jpg" config section
+func optionSchema() []converter.OptionSpec {
+	defaults := DefaultJPGOptions()
+	return []converter.OptionSpec{
+		{Name: "quality", Short: "q", Type: converter.OptionInt, Default: defaults.Quality,
+			Help: "JPEG quality (1-100, higher = better)"},
+		{Name: "auto-orient", Type: converter.OptionBool, Default: defaults.AutoOrient,
+			Help: "rotate pixels upright instead of recording the orientation in EXIF"},
+		{Name: "exif", Type: converter.OptionBool, Default: defaults.Exif,
+			Help: "copy EXIF metadata (capture date, camera, GPS)"},
+		{Name: "all-images", Type: converter.OptionBool, Default: defaults.AllImages,
+			Help: "also write the other images of a burst as <name>_2.jpg, ..."},
+		{Name: "toolchain", Type: converter.OptionString,
+			Help: "named ffmpeg toolchain from config"},
+	}
+}
+
+// optionsFromValues builds JPGOptions from resolved schema values
+func optionsFromValues(values converter.OptionValues) JPGOptions {
+	opts := DefaultJPGOptions()
+	opts.Quality = values.Int("quality")
+	opts.AutoOrient = values.Bool("auto-orient")
+	opts.Exif = values.Bool("exif")
+	opts.AllImages = values.Bool("all-images")
+	opts.Toolchain = values.String("toolchain")
+	return opts
+}
+
+// Validate checks if options are valid
+func (o *JPGOptions) Validate() error {
+	if o.Quality < 1 || o.Quality > 100 {
+		return fmt.Errorf("quality must be between 1 and 100, got %d", o.Quality)
+	}
+	return nil
+}
+
+// Requirements returns the ffmpeg components these options depend on.
+// ffmpeg 7.1 is the first release that assembles HEIF grid tiles into the
+// full image; older releases decode a single tile.
+func (o *JPGOptions) Requirements() executor.Requirements {
+	return executor.Requirements{
+		Decoders:   []string{"hevc"},
+		Encoders:   []string{"mjpeg"},
+		Muxers:     []string{"image2"},
+		MinVersion: "7.1",
+	}
+}
+
+// qscale maps the 1-100 quality onto the mjpeg encoder's -q:v scale, where
+// 2 is the best and 31 the worst quality
+func (o *JPGOptions) qscale() int {
+	return 31 - (o.Quality-1)*29/99
+}
+
+// args builds the ffmpeg arguments writing the image selected by mapArg
+// (empty = ffmpeg's default choice) from input to output
+func (o *JPGOptions) args(input, output, mapArg string) []string {
+	args := []string{"-y"}
+	if !o.AutoOrient {
+		args = append(args, "-noautorotate")
+	}
+	args = append(args, "-i", input)
+	if mapArg != "" {
+		args = append(args, "-map", mapArg)
+	}
+	return append(args,
+		"-frames:v", "1",
+		"-c:v", "mjpeg",
+		"-q:v", strconv.Itoa(o.qscale()),
+		"-f", "image2",
+		"-update", "1",
+		output,
+	)
+}
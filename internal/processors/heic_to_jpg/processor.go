@@ -0,0 +1,339 @@
+package heic_to_jpg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/fsutil"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/ui"
+)
+
+func init() {
+	// Auto-register this converter
+	converter.RegisterFactory(New)
+}
+
+// New creates a JPEG converter ready for registration
+func New() converter.Converter {
+	return NewJPGConverter()
+}
+
+// JPGConverter converts HEIC/HEIF photos to JPEG. ffmpeg decodes the
+// image; the HEIF item structure is read here to pick the primary image of
+// multi-image files and to carry its EXIF metadata over.
+type JPGConverter struct {
+	mu      sync.Mutex
+	ffmpeg  executor.Lazy
+	options JPGOptions
+}
+
+// NewJPGConverter creates a new JPEG converter
+func NewJPGConverter() *JPGConverter {
+	return &JPGConverter{
+		options: DefaultJPGOptions(),
+	}
+}
+
+// Name returns the converter name
+func (c *JPGConverter) Name() string {
+	return "jpg"
+}
+
+// Description returns the converter description
+func (c *JPGConverter) Description() string {
+	return "Convert HEIC/HEIF photos to JPEG, keeping EXIF metadata"
+}
+
+// SupportedInputs returns supported input formats
+func (c *JPGConverter) SupportedInputs() []string {
+	return []string{".heic", ".heif"}
+}
+
+// OutputExtension returns the output extension
+func (c *JPGConverter) OutputExtension() string {
+	return ".jpg"
+}
+
+// Cost returns the planner cost hint; decoding a single image is cheap
+func (c *JPGConverter) Cost() int {
+	return 5
+}
+
+// Validate checks if the input file is valid
+func (c *JPGConverter) Validate(input string) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return fmt.Errorf("cannot access file: %w", err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("input is a directory, not a file")
+	}
+
+	return media.CheckInput(input, c.SupportedInputs())
+}
+
+// image is one image of the input written to its own output file
+type image struct {
+	output string
+	mapArg string // ffmpeg -map argument (empty = default stream selection)
+	exif   []byte // TIFF-structured EXIF to embed (nil = none)
+}
+
+// Convert processes a single file. The primary image goes to the job
+// output; with AllImages the other images go next to it. Every image is
+// written to a partial file, and all are moved into place once each is done.
+// With SkipExisting, only the images whose outputs are missing are written.
+func (c *JPGConverter) Convert(ctx context.Context, job converter.Job) (*converter.Result, error) {
+	input, output, opts := job.Input, job.Output, job.Options
+
+	c.mu.Lock()
+	options := c.options
+	c.mu.Unlock()
+
+	var (
+		ff      executor.Executor
+		images  []image // every image, planned once the input is validated
+		pending []image // the images to write, chosen by Prepare
+	)
+	planned := func() []image {
+		if images == nil {
+			images = c.plan(input, output, options, opts.Verbose)
+		}
+		return images
+	}
+
+	return converter.RunJob(ctx, job, converter.Task{
+		Validate: c.Validate,
+		Outputs: func() []string {
+			list := pending
+			if list == nil {
+				list = planned()
+			}
+			outputs := []string{}
+			for _, img := range list {
+				outputs = append(outputs, img.output)
+			}
+			return outputs
+		},
+		Prepare: func() (err error) {
+			pending = []image{}
+			for _, img := range planned() {
+				if opts.SkipExisting {
+					if _, err := os.Stat(img.output); err == nil {
+						ui.PrintVerbose(opts.Verbose, "Skipping %s (already exists)", img.output)
+						continue
+					}
+				}
+				pending = append(pending, img)
+			}
+			ff, err = c.ffmpeg.Get()
+			return err
+		},
+		Encode: func(string) (*executor.FFmpegResult, error) {
+			for _, img := range pending {
+				ffResult, err := c.write(ctx, ff, input, img, options, opts.Verbose)
+				if err != nil || ctx.Err() != nil {
+					return ffResult, err
+				}
+			}
+			return nil, nil
+		},
+		Commit: func(string) error {
+			for _, img := range pending {
+				if err := fsutil.Commit(fsutil.PartialPath(img.output), img.output); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Discard: func(string) {
+			for _, img := range pending {
+				fsutil.Discard(fsutil.PartialPath(img.output))
+			}
+		},
+	})
+}
+
+// plan lists the images to write: the primary image to output and, with
+// AllImages, the other top-level images of a burst next to it. Files whose
+// item structure cannot be read are left to ffmpeg's default selection.
+func (c *JPGConverter) plan(input, output string, options JPGOptions, verbose bool) []image {
+	heif, err := media.ReadHEIF(input)
+	if err != nil {
+		ui.PrintVerbose(verbose, "Unable to read HEIF structure of %s: %v", input, err)
+		return []image{{output: output}}
+	}
+
+	primary, ok := heif.Primary()
+	if !ok {
+		return []image{{output: output}}
+	}
+
+	main := image{output: output}
+	if options.Exif && heif.Exif != nil {
+		main.exif = exifFor(heif.Exif, primary, options.AutoOrient)
+	}
+
+	// A single image (possibly a grid of tiles) is ffmpeg's default choice;
+	// with several, the primary has to be selected explicitly
+	others := []media.HEIFItem{}
+	for _, it := range heif.Images() {
+		if it.ID != primary.ID {
+			others = append(others, it)
+		}
+	}
+	if len(others) == 0 {
+		return []image{main}
+	}
+	main.mapArg = mapArg(primary)
+
+	images := []image{main}
+	if !options.AllImages {
+		return images
+	}
+
+	ext := filepath.Ext(output)
+	base := strings.TrimSuffix(output, ext)
+	for i, it := range others {
+		img := image{
+			output: fmt.Sprintf("%s_%d%s", base, i+2, ext),
+			mapArg: mapArg(it),
+		}
+		if options.Exif && heif.Exif != nil {
+			img.exif = exifFor(heif.Exif, it, options.AutoOrient)
+		}
+		images = append(images, img)
+	}
+	return images
+}
+
+// write encodes one image into a partial file, embeds its EXIF and renames
+// write encodes img to its partial file and embeds its EXIF metadata
+func (c *JPGConverter) write(ctx context.Context, ff executor.Executor, input string, img image, options JPGOptions, verbose bool) (*executor.FFmpegResult, error) {
+	partial := fsutil.PartialPath(img.output)
+
+	ffResult, err := ff.Run(ctx, options.args(input, partial, img.mapArg), executor.RunOptions{Verbose: verbose})
+	if err == nil && img.exif != nil {
+		err = embedExif(partial, img.exif, verbose)
+	}
+	return ffResult, err
+}
+
+// mapArg selects an image item: coded images are streams and grids are
+// stream groups, both identified by item ID
+func mapArg(it media.HEIFItem) string {
+	if it.Type == "grid" {
+		return fmt.Sprintf("0:g:#%d", it.ID)
+	}
+	return fmt.Sprintf("0:#%d", it.ID)
+}
+
+// exifFor adjusts the EXIF orientation to the pixels ffmpeg writes: upright
+// pixels (auto-orient) need none, stored pixels need the rotation of item
+func exifFor(exif []byte, item media.HEIFItem, autoOrient bool) []byte {
+	orientation := 0
+	switch {
+	case autoOrient:
+		orientation = 1
+	case item.Mirror:
+		// Mirrored images keep the orientation recorded by the camera
+	case item.Rotation != 0:
+		orientation = orientationFor(item.Rotation)
+	}
+	if orientation == 0 {
+		return exif
+	}
+
+	adjusted, err := media.SetExifOrientation(exif, orientation)
+	if err != nil {
+		return exif
+	}
+	return adjusted
+}
+
+// orientationFor returns the EXIF orientation displaying an image rotated
+// by the given counter-clockwise HEIF rotation
+func orientationFor(rotation int) int {
+	switch rotation {
+	case 90:
+		return 8
+	case 180:
+		return 3
+	case 270:
+		return 6
+	default:
+		return 1
+	}
+}
+
+// embedExif inserts an EXIF segment into the JPEG file at path. EXIF too
+// large for a JPEG segment is dropped rather than failing the conversion.
+func embedExif(path string, exif []byte, verbose bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	out, err := media.InsertJPEGExif(data, exif)
+	if errors.Is(err, media.ErrExifTooLarge) {
+		ui.PrintVerbose(verbose, "Not copying EXIF metadata: %v", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to embed EXIF metadata: %w", err)
+	}
+
+	return os.WriteFile(path, out, 0644)
+}
+
+// Preflight verifies that ffmpeg is available and can decode HEIF images
+func (c *JPGConverter) Preflight() error {
+	ff, err := c.ffmpeg.Get()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	req := c.options.Requirements()
+	c.mu.Unlock()
+
+	return ff.Preflight(req)
+}
+
+// OptionSchema describes the options of the jpg converter
+func (c *JPGConverter) OptionSchema() []converter.OptionSpec {
+	return optionSchema()
+}
+
+// Configure applies option values resolved against OptionSchema
+func (c *JPGConverter) Configure(values converter.OptionValues) error {
+	return c.SetOptions(optionsFromValues(values))
+}
+
+// SetOptions sets converter-specific options
+func (c *JPGConverter) SetOptions(opts JPGOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	c.ffmpeg.SetToolchain(opts.Toolchain)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options = opts
+	return nil
+}
+
+// SetExecutor overrides the executor used for conversions
+func (c *JPGConverter) SetExecutor(e executor.Executor) {
+	c.ffmpeg.Set(e)
+}
@@ -0,0 +1,127 @@
+package heic_to_jpg
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+)
+
+// box returns an ISO BMFF box of the given type holding the parts
+func box(typ string, parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(b, typ...), body...)
+}
+
+// fullBox returns a box with a version and zero flags
+func fullBox(typ string, version byte, parts ...[]byte) []byte {
+	return box(typ, append([][]byte{{version, 0, 0, 0}}, parts...)...)
+}
+
+func u16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+func u32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+// infe returns a version 2 item info entry
+func infe(id uint16, typ string) []byte {
+	return box("infe", []byte{2, 0, 0, 0}, u16(id), u16(0), []byte(typ), []byte{0})
+}
+
+// burstFile builds a HEIC burst: two images (items 1 and 2, the first
+// primary) and an Exif item (3) stored in idat
+func burstFile() []byte {
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0, 0, 0, 0, 0, 0, 0} // empty IFD0
+	exif := append(u32(0), tiff...)
+
+	meta := fullBox("meta", 0,
+		box("hdlr", make([]byte, 24)),
+		fullBox("pitm", 0, u16(1)),
+		fullBox("iinf", 0, u16(3), infe(1, "hvc1"), infe(2, "hvc1"), infe(3, "Exif")),
+		fullBox("iloc", 1, u16(0x4400), u16(1),
+			u16(3), u16(1), u16(0), u16(1), u32(0), u32(uint32(len(exif)))),
+		fullBox("iref", 0, box("cdsc", u16(3), u16(1), u16(1))),
+		box("idat", exif),
+	)
+	return append(box("ftyp", []byte("heicmif1heic")), meta...)
+}
+
+// fakeFFmpeg writes a bare JPEG to the output of every run
+type fakeFFmpeg struct {
+	executor.Executor
+	outputs []string
+}
+
+func (f *fakeFFmpeg) Run(_ context.Context, args []string, _ executor.RunOptions) (*executor.FFmpegResult, error) {
+	output := args[len(args)-1]
+	f.outputs = append(f.outputs, output)
+	return &executor.FFmpegResult{Success: true}, os.WriteFile(output, []byte{0xFF, 0xD8, 0xFF, 0xD9}, 0644)
+}
+
+func TestConvertAllImages(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    []string // outputs present before the run
+		wantWritten []string
+		wantSkipped bool
+	}{
+		{"both images", nil, []string{"in.jpg", "in_2.jpg"}, false},
+		{"only the missing image", []string{"in.jpg"}, []string{"in_2.jpg"}, false},
+		{"nothing missing", []string{"in.jpg", "in_2.jpg"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := filepath.Join(dir, "in.heic")
+			if err := os.WriteFile(input, burstFile(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range tt.existing {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("old"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			conv := NewJPGConverter()
+			opts := DefaultJPGOptions()
+			opts.AllImages = true
+			if err := conv.SetOptions(opts); err != nil {
+				t.Fatal(err)
+			}
+			ff := &fakeFFmpeg{}
+			conv.SetExecutor(ff)
+
+			job := converter.Job{Input: input, Output: filepath.Join(dir, "in.jpg"), Options: converter.Options{SkipExisting: true}}
+			result, err := conv.Convert(context.Background(), job)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if result.Skipped != tt.wantSkipped {
+				t.Errorf("Skipped = %v, want %v", result.Skipped, tt.wantSkipped)
+			}
+			if len(ff.outputs) != len(tt.wantWritten) {
+				t.Fatalf("ffmpeg ran %d times, want %d", len(ff.outputs), len(tt.wantWritten))
+			}
+
+			for _, name := range tt.existing {
+				if data, _ := os.ReadFile(filepath.Join(dir, name)); string(data) != "old" {
+					t.Errorf("%s was rewritten", name)
+				}
+			}
+			for _, name := range tt.wantWritten {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatalf("%s not written: %v", name, err)
+				}
+				if !bytes.Contains(data, []byte("Exif\x00\x00")) {
+					t.Errorf("%s has no EXIF", name)
+				}
+			}
+		})
+	}
+}
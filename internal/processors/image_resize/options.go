@@ -0,0 +1,165 @@
+package image_resize
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+)
+
+// Fit modes
+const (
+	FitContain = "contain" // fit within width x height, keeping the aspect ratio
+	FitInside  = "inside"  // like contain, but never upscale
+	FitCover   = "cover"   // fill width x height, cropping the overflow
+	FitFill    = "fill"    // stretch to width x height
+)
+
+// ResizeOptions contains image resize options. Sizes are in pixels; DPI
+// metadata of the input is ignored.
+type ResizeOptions struct {
+	// Format of the output: jpg, png, webp, gif, tiff or bmp
+	Format  string
+	Quality int // 1-100 for jpg and webp, higher = better
+
+	// Target size (0 = unconstrained) and how the image is fitted into it
+	Width  int
+	Height int
+	Fit    string
+
+	// Scale resizes by a factor instead of to a size (0 = off)
+	Scale float64
+
+	// Sharpen is the unsharp mask amount applied after resizing (0 = off)
+	Sharpen float64
+
+	// AutoOrient rotates the pixels upright according to EXIF orientation
+	AutoOrient bool
+
+	// Toolchain selects a named ffmpeg/ffprobe pair (empty = default), used
+	// for WebP output and inputs the built-in decoders cannot read
+	Toolchain string
+}
+
+// DefaultResizeOptions returns default resize options
+func DefaultResizeOptions() ResizeOptions {
+	return ResizeOptions{
+		Format:     "jpg",
+		Quality:    85,
+		Fit:        FitInside,
+		AutoOrient: true,
+	}
+}
+
+// optionSchema declares the options exposed on the command line and in the
+// "resize" config section
+func optionSchema() []converter.OptionSpec {
+	defaults := DefaultResizeOptions()
+	return []converter.OptionSpec{
+		{Name: "format", Type: converter.OptionString, Default: defaults.Format,
+			Allowed: []string{"jpg", "png", "webp", "gif", "tiff", "bmp"},
+			Help:    "output format"},
+		{Name: "quality", Short: "q", Type: converter.OptionInt, Default: defaults.Quality,
+			Help: "jpg/webp quality (1-100, higher = better)"},
+		{Name: "width", Type: converter.OptionInt, Default: defaults.Width,
+			Help: "target width in pixels (0 = unconstrained)"},
+		{Name: "height", Type: converter.OptionInt, Default: defaults.Height,
+			Help: "target height in pixels (0 = unconstrained)"},
+		{Name: "fit", Type: converter.OptionString, Default: defaults.Fit,
+			Allowed: []string{FitInside, FitContain, FitCover, FitFill},
+			Help:    "how the image is fitted into width x height"},
+		{Name: "scale", Type: converter.OptionFloat, Default: defaults.Scale,
+			Help: "resize by a factor instead (e.g., 0.5; 0 = off)"},
+		{Name: "sharpen", Type: converter.OptionFloat, Default: defaults.Sharpen,
+			Help: "unsharp mask amount after resizing (e.g., 0.5; 0 = off)"},
+		{Name: "auto-orient", Type: converter.OptionBool, Default: defaults.AutoOrient,
+			Help: "rotate pixels upright according to EXIF orientation"},
+		{Name: "toolchain", Type: converter.OptionString,
+			Help: "named ffmpeg toolchain from config"},
+	}
+}
+
+// optionsFromValues builds ResizeOptions from resolved schema values
+func optionsFromValues(values converter.OptionValues) ResizeOptions {
+	opts := DefaultResizeOptions()
+	opts.Format = values.String("format")
+	opts.Quality = values.Int("quality")
+	opts.Width = values.Int("width")
+	opts.Height = values.Int("height")
+	opts.Fit = values.String("fit")
+	opts.Scale = values.Float("scale")
+	opts.Sharpen = values.Float("sharpen")
+	opts.AutoOrient = values.Bool("auto-orient")
+	opts.Toolchain = values.String("toolchain")
+	return opts
+}
+
+// Validate checks if options are valid
+func (o *ResizeOptions) Validate() error {
+	switch o.Format {
+	case "jpg", "png", "webp", "gif", "tiff", "bmp":
+	default:
+		return fmt.Errorf("invalid format %q (allowed: jpg, png, webp, gif, tiff, bmp)", o.Format)
+	}
+	if o.Quality < 1 || o.Quality > 100 {
+		return fmt.Errorf("quality must be between 1 and 100, got %d", o.Quality)
+	}
+	if o.Width < 0 || o.Height < 0 {
+		return fmt.Errorf("width and height cannot be negative")
+	}
+	switch o.Fit {
+	case FitContain, FitInside, FitCover, FitFill:
+	default:
+		return fmt.Errorf("invalid fit %q (allowed: inside, contain, cover, fill)", o.Fit)
+	}
+	if o.Scale < 0 || o.Scale > 16 {
+		return fmt.Errorf("scale must be between 0 and 16, got %g", o.Scale)
+	}
+	if o.Scale > 0 && (o.Width > 0 || o.Height > 0) {
+		return fmt.Errorf("use either --scale or --width/--height, not both")
+	}
+	if o.Sharpen < 0 || o.Sharpen > 5 {
+		return fmt.Errorf("sharpen must be between 0 and 5, got %g", o.Sharpen)
+	}
+	return nil
+}
+
+// Requirements returns the ffmpeg components every conversion depends on.
+// Only WebP output always needs ffmpeg; other formats are encoded in Go.
+func (o *ResizeOptions) Requirements() executor.Requirements {
+	if o.Format != "webp" {
+		return executor.Requirements{}
+	}
+	return executor.Requirements{
+		Encoders: []string{"libwebp"},
+		Muxers:   []string{"image2"},
+	}
+}
+
+// ext returns the output extension
+func (o *ResizeOptions) ext() string {
+	return "." + o.Format
+}
+
+// codecArgs returns the ffmpeg arguments encoding the output. Animated
+// inputs stay animated in formats that support it.
+func (o *ResizeOptions) codecArgs(output string, animated bool) []string {
+	args := []string{}
+	switch o.Format {
+	case "jpg":
+		// mjpeg's -q:v runs from 2 (best) to 31 (worst)
+		args = append(args, "-c:v", "mjpeg", "-q:v", strconv.Itoa(31-(o.Quality-1)*29/99))
+	case "webp":
+		args = append(args, "-c:v", "libwebp", "-quality", strconv.Itoa(o.Quality))
+	case "tiff":
+		args = append(args, "-c:v", "tiff", "-compression_algo", "deflate")
+	default:
+		args = append(args, "-c:v", o.Format)
+	}
+
+	if animated && (o.Format == "gif" || o.Format == "webp") {
+		return append(args, "-loop", "0", "-f", o.Format, output)
+	}
+	return append(args, "-frames:v", "1", "-update", "1", "-f", "image2", output)
+}
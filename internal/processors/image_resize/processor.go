@@ -0,0 +1,352 @@
+package image_resize
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp" // register the WebP decoder
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/ui"
+)
+
+func init() {
+	// Auto-register this converter
+	converter.RegisterFactory(New)
+}
+
+// New creates an image resize converter ready for registration
+func New() converter.Converter {
+	return NewResizeConverter()
+}
+
+// ResizeConverter resizes images and converts between image formats.
+// Images are decoded, oriented, resized and encoded in Go; ffmpeg is only
+// run for WebP output, animated GIFs, images over maxDecodePixels and
+// inputs Go cannot decode.
+type ResizeConverter struct {
+	mu      sync.Mutex
+	ffmpeg  executor.Lazy
+	options ResizeOptions
+}
+
+// NewResizeConverter creates a new image resize converter
+func NewResizeConverter() *ResizeConverter {
+	return &ResizeConverter{
+		options: DefaultResizeOptions(),
+	}
+}
+
+// Name returns the converter name
+func (c *ResizeConverter) Name() string {
+	return "resize"
+}
+
+// Description returns the converter description
+func (c *ResizeConverter) Description() string {
+	return "Resize images and convert between JPEG, PNG, WebP, GIF, TIFF and BMP"
+}
+
+// SupportedInputs returns supported input formats
+func (c *ResizeConverter) SupportedInputs() []string {
+	return []string{".jpg", ".jpeg", ".png", ".webp", ".gif", ".tif", ".tiff", ".bmp"}
+}
+
+// OutputExtension returns the extension of the configured output format
+func (c *ResizeConverter) OutputExtension() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.options.ext()
+}
+
+// Cost returns the planner cost hint; most jobs run without ffmpeg
+func (c *ResizeConverter) Cost() int {
+	return 5
+}
+
+// Validate checks if the input file is valid
+func (c *ResizeConverter) Validate(input string) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return fmt.Errorf("cannot access file: %w", err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("input is a directory, not a file")
+	}
+
+	return media.CheckInput(input, c.SupportedInputs())
+}
+
+// Convert processes a single file
+func (c *ResizeConverter) Convert(ctx context.Context, job converter.Job) (*converter.Result, error) {
+	c.mu.Lock()
+	options := c.options
+	c.mu.Unlock()
+
+	return converter.RunJob(ctx, job, converter.Task{
+		Validate: c.Validate,
+		Encode: func(partial string) (*executor.FFmpegResult, error) {
+			return c.process(ctx, job.Input, partial, options, job.Options.Verbose)
+		},
+	})
+}
+
+// process writes the resized image to partial, in Go when the input can be
+// decoded here and with ffmpeg otherwise
+func (c *ResizeConverter) process(ctx context.Context, input, partial string, options ResizeOptions, verbose bool) (*executor.FFmpegResult, error) {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	img, animated, err := decode(data)
+	switch {
+	case animated:
+		ui.PrintVerbose(verbose, "Resizing animated %s with ffmpeg", input)
+		return c.viaFFmpeg(ctx, input, partial, options, true, verbose)
+	case err != nil:
+		ui.PrintVerbose(verbose, "Decoding %s with ffmpeg: %v", input, err)
+		return c.viaFFmpeg(ctx, input, partial, options, false, verbose)
+	}
+
+	rgba := toRGBA(img)
+	if options.AutoOrient {
+		rgba = orient(rgba, media.ExifOrientation(media.ImageExif(data)))
+	}
+	w, h, crop := geometry(rgba.Rect.Dx(), rgba.Rect.Dy(), options)
+	rgba = sharpen(resize(rgba, w, h, crop), options.Sharpen)
+
+	if options.Format == "webp" {
+		return c.encodeWebP(ctx, rgba, partial, options, verbose)
+	}
+	return nil, writeImage(partial, rgba, options)
+}
+
+// maxDecodePixels bounds the images decoded in Go. Decoding and the RGBA
+// copy take about 8 bytes per pixel, per worker; larger images are left to
+// ffmpeg.
+const maxDecodePixels = 64 << 20
+
+// decode decodes an image with the built-in decoders, reporting animated
+// GIFs (which are left to ffmpeg) instead of decoding their first frame.
+// The dimensions are checked against maxDecodePixels before decoding.
+func decode(data []byte) (image.Image, bool, error) {
+	isGIF := bytes.HasPrefix(data, []byte("GIF8"))
+	if isGIF && gifFrames(data, 2) > 1 {
+		return nil, true, nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxDecodePixels {
+		return nil, false, fmt.Errorf("%dx%d image is over the %d megapixel decode limit", cfg.Width, cfg.Height, maxDecodePixels>>20)
+	}
+
+	if isGIF {
+		img, err := gif.Decode(bytes.NewReader(data))
+		return img, false, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, false, err
+}
+
+// gifFrames counts the frames of a GIF by walking its blocks without
+// decoding them, stopping at limit. A malformed stream counts the frames
+// found before the error.
+func gifFrames(data []byte, limit int) int {
+	if len(data) < 13 {
+		return 0
+	}
+	pos := 13 // header and logical screen descriptor
+	if data[10]&0x80 != 0 {
+		pos += 3 << (data[10]&0x07 + 1) // global color table
+	}
+
+	// skipSubBlocks returns the position after a chain of data sub-blocks
+	skipSubBlocks := func(pos int) int {
+		for pos < len(data) && data[pos] != 0 {
+			pos += int(data[pos]) + 1
+		}
+		return pos + 1
+	}
+
+	frames := 0
+	for pos < len(data) && frames < limit {
+		switch data[pos] {
+		case 0x21: // extension: label, then sub-blocks
+			pos = skipSubBlocks(pos + 2)
+		case 0x2C: // image descriptor, optional local color table, LZW data
+			if pos+10 > len(data) {
+				return frames
+			}
+			packed := data[pos+9]
+			pos += 10
+			if packed&0x80 != 0 {
+				pos += 3 << (packed&0x07 + 1)
+			}
+			pos = skipSubBlocks(pos + 1) // after the LZW minimum code size
+			frames++
+		default: // trailer or garbage
+			return frames
+		}
+	}
+	return frames
+}
+
+// writeImage encodes img into a new file at path
+func writeImage(path string, img *image.RGBA, options ResizeOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+
+	err = encode(w, img, options)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// encode writes img in the output format (all but WebP)
+func encode(w io.Writer, img *image.RGBA, options ResizeOptions) error {
+	switch options.Format {
+	case "jpg":
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: options.Quality})
+	case "png":
+		return png.Encode(w, img)
+	case "gif":
+		return gif.Encode(w, img, &gif.Options{NumColors: 256})
+	case "tiff":
+		return tiff.Encode(w, img, &tiff.Options{Compression: tiff.Deflate, Predictor: true})
+	case "bmp":
+		return bmp.Encode(w, img)
+	}
+	return fmt.Errorf("no encoder for %s", options.Format)
+}
+
+// encodeWebP hands the resized image to ffmpeg as a lossless PNG for WebP
+// encoding
+func (c *ResizeConverter) encodeWebP(ctx context.Context, img *image.RGBA, partial string, options ResizeOptions, verbose bool) (*executor.FFmpegResult, error) {
+	ff, err := c.ffmpeg.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "sb-resize-*.png")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := writeImage(tmp.Name(), img, ResizeOptions{Format: "png"}); err != nil {
+		return nil, err
+	}
+
+	args := append([]string{"-y", "-i", tmp.Name()}, options.codecArgs(partial, false)...)
+	return ff.Run(ctx, args, executor.RunOptions{Verbose: verbose})
+}
+
+// viaFFmpeg resizes with ffmpeg filters, using the same geometry as the Go
+// path. EXIF orientation is left to ffmpeg here.
+func (c *ResizeConverter) viaFFmpeg(ctx context.Context, input, partial string, options ResizeOptions, animated, verbose bool) (*executor.FFmpegResult, error) {
+	ff, err := c.ffmpeg.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := ff.GetInfo(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe input: %w", err)
+	}
+	video := info.PrimaryVideo()
+	if video == nil || video.Width == 0 || video.Height == 0 {
+		return nil, fmt.Errorf("no image in input")
+	}
+
+	w, h, crop := geometry(video.Width, video.Height, options)
+	filters := []string{}
+	if crop != image.Rect(0, 0, video.Width, video.Height) {
+		filters = append(filters, fmt.Sprintf("crop=%d:%d:%d:%d", crop.Dx(), crop.Dy(), crop.Min.X, crop.Min.Y))
+	}
+	filters = append(filters, fmt.Sprintf("scale=%d:%d:flags=lanczos", w, h))
+	if options.Sharpen > 0 {
+		filters = append(filters, fmt.Sprintf("unsharp=5:5:%.2f", options.Sharpen))
+	}
+	filter := strings.Join(filters, ",")
+	if options.Format == "gif" {
+		// A palette computed for the resized frames
+		filter += ",split[a][b];[a]palettegen[p];[b][p]paletteuse"
+	}
+
+	args := append([]string{"-y", "-i", input, "-vf", filter}, options.codecArgs(partial, animated)...)
+	return ff.Run(ctx, args, executor.RunOptions{Verbose: verbose})
+}
+
+// Preflight verifies that ffmpeg can encode WebP when that is the output
+// format; other formats need no ffmpeg unless an input requires it
+func (c *ResizeConverter) Preflight() error {
+	c.mu.Lock()
+	req := c.options.Requirements()
+	c.mu.Unlock()
+
+	if len(req.Encoders) == 0 {
+		return nil
+	}
+
+	ff, err := c.ffmpeg.Get()
+	if err != nil {
+		return err
+	}
+	return ff.Preflight(req)
+}
+
+// OptionSchema describes the options of the resize converter
+func (c *ResizeConverter) OptionSchema() []converter.OptionSpec {
+	return optionSchema()
+}
+
+// Configure applies option values resolved against OptionSchema
+func (c *ResizeConverter) Configure(values converter.OptionValues) error {
+	return c.SetOptions(optionsFromValues(values))
+}
+
+// SetOptions sets converter-specific options
+func (c *ResizeConverter) SetOptions(opts ResizeOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	c.ffmpeg.SetToolchain(opts.Toolchain)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options = opts
+	return nil
+}
+
+// SetExecutor overrides the executor used for conversions
+func (c *ResizeConverter) SetExecutor(e executor.Executor) {
+	c.ffmpeg.Set(e)
+}
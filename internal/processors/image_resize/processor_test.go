@@ -0,0 +1,119 @@
+package image_resize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// pngOf encodes a w x h PNG
+func pngOf(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withPNGSize rewrites the dimensions in the IHDR chunk of a PNG
+func withPNGSize(data []byte, w, h uint32) []byte {
+	out := append([]byte{}, data...)
+	binary.BigEndian.PutUint32(out[16:], w)
+	binary.BigEndian.PutUint32(out[20:], h)
+	binary.BigEndian.PutUint32(out[29:], crc32.ChecksumIEEE(out[12:29]))
+	return out
+}
+
+// gifOf encodes a GIF with the given number of frames
+func gifOf(t *testing.T, frames int, globalPalette bool) []byte {
+	t.Helper()
+	g := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		img := image.NewPaletted(image.Rect(0, 0, 8, 8), palette.Plan9)
+		img.Set(i%8, 0, color.White)
+		g.Image = append(g.Image, img)
+		g.Delay = append(g.Delay, 10)
+	}
+	if globalPalette {
+		g.Config = image.Config{ColorModel: color.Palette(palette.Plan9), Width: 8, Height: 8}
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	small := pngOf(t, 4, 3)
+
+	tests := []struct {
+		name         string
+		data         []byte
+		wantAnimated bool
+		wantErr      string
+	}{
+		{"png", small, false, ""},
+		{"static gif", gifOf(t, 1, false), false, ""},
+		{"animated gif", gifOf(t, 3, true), true, ""},
+		{"over the pixel budget", withPNGSize(small, 100000, 100000), false, "megapixel"},
+		{"unknown format", []byte("not an image"), false, "unknown format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, animated, err := decode(tt.data)
+			if animated != tt.wantAnimated {
+				t.Errorf("animated = %v, want %v", animated, tt.wantAnimated)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("decode() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decode() error = %v", err)
+			}
+			if !animated && img == nil {
+				t.Error("decode() returned no image")
+			}
+		})
+	}
+}
+
+func TestGIFFrames(t *testing.T) {
+	animated := gifOf(t, 5, false)
+
+	tests := []struct {
+		name  string
+		data  []byte
+		limit int
+		want  int
+	}{
+		{"single frame", gifOf(t, 1, false), 10, 1},
+		{"local palettes", animated, 10, 5},
+		{"global palette", gifOf(t, 4, true), 10, 4},
+		{"stops at the limit", animated, 2, 2},
+		{"truncated", animated[:len(animated)/2], 10, 3},
+		{"header only", animated[:13], 10, 0},
+		{"too short", []byte("GIF89a"), 10, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gifFrames(tt.data, tt.limit); got != tt.want {
+				t.Errorf("gifFrames() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
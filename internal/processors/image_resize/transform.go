@@ -0,0 +1,196 @@
+package image_resize
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// geometry returns the output size for a source image and the region of
+// the source that is scaled into it (smaller than the source for cover)
+func geometry(srcW, srcH int, o ResizeOptions) (int, int, image.Rectangle) {
+	full := image.Rect(0, 0, srcW, srcH)
+	w, h := o.Width, o.Height
+
+	switch {
+	case o.Scale > 0:
+		return scaled(srcW, o.Scale), scaled(srcH, o.Scale), full
+	case w == 0 && h == 0:
+		return srcW, srcH, full
+	}
+
+	switch o.Fit {
+	case FitFill:
+		if w == 0 {
+			w = scaled(srcW, float64(h)/float64(srcH))
+		}
+		if h == 0 {
+			h = scaled(srcH, float64(w)/float64(srcW))
+		}
+		return w, h, full
+
+	case FitCover:
+		if w > 0 && h > 0 {
+			s := max(float64(w)/float64(srcW), float64(h)/float64(srcH))
+			cropW := min(srcW, max(1, int(math.Round(float64(w)/s))))
+			cropH := min(srcH, max(1, int(math.Round(float64(h)/s))))
+			x, y := (srcW-cropW)/2, (srcH-cropH)/2
+			return w, h, image.Rect(x, y, x+cropW, y+cropH)
+		}
+		// With one side given, cover and contain are the same
+	}
+
+	s := math.Inf(1)
+	if w > 0 {
+		s = float64(w) / float64(srcW)
+	}
+	if h > 0 {
+		s = min(s, float64(h)/float64(srcH))
+	}
+	if o.Fit == FitInside {
+		s = min(s, 1)
+	}
+	return scaled(srcW, s), scaled(srcH, s), full
+}
+
+// scaled returns n scaled by s, at least 1
+func scaled(n int, s float64) int {
+	return max(1, int(math.Round(float64(n)*s)))
+}
+
+// toRGBA returns img as an RGBA image with its origin at (0, 0)
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	if rgba, ok := img.(*image.RGBA); ok && b.Min == (image.Point{}) {
+		return rgba
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
+
+// orient returns img transformed for display according to an EXIF
+// orientation (1-8)
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		// Orientations 5-8 swap width and height
+		dw, dh = h, w
+	}
+
+	// source returns the source pixel shown at (x, y) of the result
+	source := func(x, y int) (int, int) {
+		switch orientation {
+		case 2: // mirrored horizontally
+			return w - 1 - x, y
+		case 3: // rotated 180°
+			return w - 1 - x, h - 1 - y
+		case 4: // mirrored vertically
+			return x, h - 1 - y
+		case 5: // transposed
+			return y, x
+		case 6: // rotated 90° clockwise for display
+			return y, h - 1 - x
+		case 7: // transversed
+			return w - 1 - y, h - 1 - x
+		default: // 8: rotated 90° counter-clockwise for display
+			return w - 1 - y, x
+		}
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sx, sy := source(x, y)
+			copy(out.Pix[out.PixOffset(x, y):][:4], img.Pix[img.PixOffset(sx, sy):][:4])
+		}
+	}
+	return out
+}
+
+// resize scales the crop region of img to w x h with Catmull-Rom
+// resampling
+func resize(img *image.RGBA, w, h int, crop image.Rectangle) *image.RGBA {
+	if w == img.Rect.Dx() && h == img.Rect.Dy() && crop == img.Rect {
+		return img
+	}
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(out, out.Rect, img, crop, draw.Src, nil)
+	return out
+}
+
+// sharpen applies an unsharp mask: each color channel moves away from a
+// Gaussian blur of itself by amount. Alpha is left unchanged.
+func sharpen(img *image.RGBA, amount float64) *image.RGBA {
+	if amount <= 0 {
+		return img
+	}
+
+	blurred := blur(img)
+	out := image.NewRGBA(img.Rect)
+	for i := 0; i < len(img.Pix); i += 4 {
+		a := float64(img.Pix[i+3])
+		for c := 0; c < 3; c++ {
+			v := float64(img.Pix[i+c])
+			v += amount * (v - float64(blurred[i+c]))
+			// Premultiplied colors cannot exceed alpha
+			out.Pix[i+c] = uint8(math.Round(min(max(v, 0), a)))
+		}
+		out.Pix[i+3] = img.Pix[i+3]
+	}
+	return out
+}
+
+// blur returns the pixels of img blurred with a separable 5-tap binomial
+// kernel (an approximation of a Gaussian with sigma 1)
+func blur(img *image.RGBA) []uint8 {
+	kernel := [5]int{1, 4, 6, 4, 1}
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	tmp := make([]int, len(img.Pix))
+	out := make([]uint8, len(img.Pix))
+
+	// pass blurs src into dst along one axis, clamping at the edges
+	pass := func(get func(x, y, c int) int, set func(x, y, c, v int), horizontal bool) {
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				for c := 0; c < 4; c++ {
+					sum := 0
+					for k, weight := range kernel {
+						sx, sy := x, y
+						if horizontal {
+							sx = min(max(x+k-2, 0), w-1)
+						} else {
+							sy = min(max(y+k-2, 0), h-1)
+						}
+						sum += weight * get(sx, sy, c)
+					}
+					set(x, y, c, sum)
+				}
+			}
+		}
+	}
+
+	pass(func(x, y, c int) int { return int(img.Pix[img.PixOffset(x, y)+c]) },
+		func(x, y, c, v int) { tmp[img.PixOffset(x, y)+c] = v }, true)
+	pass(func(x, y, c int) int { return tmp[img.PixOffset(x, y)+c] },
+		func(x, y, c, v int) { out[img.PixOffset(x, y)+c] = uint8((v + 128) / 256) }, false)
+	return out
+}
+
+// flatten composites img over white, for formats without transparency
+func flatten(img *image.RGBA) *image.RGBA {
+	if img.Opaque() {
+		return img
+	}
+	out := image.NewRGBA(img.Rect)
+	draw.Draw(out, out.Rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(out, out.Rect, img, img.Rect.Min, draw.Over)
+	return out
+}
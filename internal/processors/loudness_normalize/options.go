@@ -0,0 +1,132 @@
+package loudness_normalize
+
+import (
+	"fmt"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+)
+
+// format describes one output format of the normalizer
+type format struct {
+	ext     string // output extension
+	muxer   string // ffmpeg muxer
+	encoder string // ffmpeg encoder
+	lossy   bool   // takes a bitrate
+	rate    int    // required sample rate (0 = any)
+}
+
+// formats lists the supported output formats by name
+var formats = map[string]format{
+	"mp3":  {ext: ".mp3", muxer: "mp3", encoder: "libmp3lame", lossy: true},
+	"m4a":  {ext: ".m4a", muxer: "ipod", encoder: "aac", lossy: true},
+	"opus": {ext: ".opus", muxer: "opus", encoder: "libopus", lossy: true, rate: 48000},
+	"flac": {ext: ".flac", muxer: "flac", encoder: "flac"},
+	"wav":  {ext: ".wav", muxer: "wav", encoder: "pcm_s16le"},
+}
+
+// NormalizeOptions contains loudness normalization options
+type NormalizeOptions struct {
+	// Target loudness
+	Target executor.LoudnessTarget
+
+	// Format of the output: mp3, m4a, opus, flac or wav
+	Format  string
+	Bitrate string // lossy formats, e.g., "192k"
+
+	// SampleRate of the output in Hz (0 = keep the source rate)
+	SampleRate int
+
+	// Toolchain selects a named ffmpeg/ffprobe pair (empty = default)
+	Toolchain string
+}
+
+// DefaultNormalizeOptions returns default normalization options
+func DefaultNormalizeOptions() NormalizeOptions {
+	return NormalizeOptions{
+		Target:  executor.DefaultLoudnessTarget(),
+		Format:  "mp3",
+		Bitrate: "192k",
+	}
+}
+
+// optionSchema declares the options exposed on the command line and in the
+// "loudnorm" config section
+func optionSchema() []converter.OptionSpec {
+	defaults := DefaultNormalizeOptions()
+	return []converter.OptionSpec{
+		{Name: "integrated", Type: converter.OptionFloat, Default: defaults.Target.I,
+			Help: "target integrated loudness in LUFS (-70 to -5)"},
+		{Name: "true-peak", Type: converter.OptionFloat, Default: defaults.Target.TP,
+			Help: "maximum true peak in dBTP (-9 to 0)"},
+		{Name: "lra", Type: converter.OptionFloat, Default: defaults.Target.LRA,
+			Help: "target loudness range in LU (1 to 50)"},
+		{Name: "format", Type: converter.OptionString, Default: defaults.Format,
+			Allowed: []string{"mp3", "m4a", "opus", "flac", "wav"},
+			Help:    "output format"},
+		{Name: "bitrate", Short: "b", Type: converter.OptionString, Default: defaults.Bitrate,
+			Help: "audio bitrate of lossy formats (e.g., 128k, 192k)"},
+		{Name: "sample-rate", Type: converter.OptionInt,
+			Help: "sample rate in Hz (e.g., 44100, 48000; 0 = source)"},
+		{Name: "toolchain", Type: converter.OptionString,
+			Help: "named ffmpeg toolchain from config"},
+	}
+}
+
+// optionsFromValues builds NormalizeOptions from resolved schema values
+func optionsFromValues(values converter.OptionValues) NormalizeOptions {
+	opts := DefaultNormalizeOptions()
+	opts.Target.I = values.Float("integrated")
+	opts.Target.TP = values.Float("true-peak")
+	opts.Target.LRA = values.Float("lra")
+	opts.Format = values.String("format")
+	opts.Bitrate = values.String("bitrate")
+	opts.SampleRate = values.Int("sample-rate")
+	opts.Toolchain = values.String("toolchain")
+	return opts
+}
+
+// Validate checks if options are valid
+func (o *NormalizeOptions) Validate() error {
+	if err := o.Target.Validate(); err != nil {
+		return err
+	}
+	if _, ok := formats[o.Format]; !ok {
+		return fmt.Errorf("invalid format %q (allowed: mp3, m4a, opus, flac, wav)", o.Format)
+	}
+	if o.SampleRate < 0 {
+		return fmt.Errorf("sample rate cannot be negative")
+	}
+	return nil
+}
+
+// Requirements returns the ffmpeg components these options depend on
+func (o *NormalizeOptions) Requirements() executor.Requirements {
+	f := formats[o.Format]
+	return executor.Requirements{
+		Encoders: []string{f.encoder},
+		Muxers:   []string{f.muxer},
+	}.Merge(executor.LoudnessRequirements())
+}
+
+// sampleRate returns the output sample rate for a source rate
+func (o *NormalizeOptions) sampleRate(source int) int {
+	if f := formats[o.Format]; f.rate > 0 {
+		return f.rate
+	}
+	if o.SampleRate > 0 {
+		return o.SampleRate
+	}
+	return source
+}
+
+// encodeArgs returns the second-pass arguments applying filter to the
+// selected audio stream of input and encoding it into output
+func (o *NormalizeOptions) encodeArgs(input, stream, filter, output string) []string {
+	f := formats[o.Format]
+	args := []string{"-y", "-i", input, "-map", stream, "-af", filter, "-c:a", f.encoder}
+	if f.lossy && o.Bitrate != "" {
+		args = append(args, "-b:a", o.Bitrate)
+	}
+	return append(args, "-map_metadata", "0", "-f", f.muxer, output)
+}
@@ -0,0 +1,198 @@
+package loudness_normalize
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/ui"
+)
+
+func init() {
+	// Auto-register this converter
+	converter.RegisterFactory(New)
+}
+
+// New creates a loudness normalizer ready for registration
+func New() converter.Converter {
+	return NewNormalizeConverter()
+}
+
+// NormalizeConverter normalizes the loudness of audio files to EBU R128
+// targets in two passes: loudnorm measures the input, then applies a linear
+// gain computed from the measurements
+type NormalizeConverter struct {
+	mu      sync.Mutex
+	ffmpeg  executor.Lazy
+	options NormalizeOptions
+}
+
+// NewNormalizeConverter creates a new loudness normalizer
+func NewNormalizeConverter() *NormalizeConverter {
+	return &NormalizeConverter{
+		options: DefaultNormalizeOptions(),
+	}
+}
+
+// Name returns the converter name
+func (c *NormalizeConverter) Name() string {
+	return "loudnorm"
+}
+
+// Description returns the converter description
+func (c *NormalizeConverter) Description() string {
+	return "Normalize audio loudness to EBU R128 targets (two-pass loudnorm)"
+}
+
+// SupportedInputs returns supported input formats
+func (c *NormalizeConverter) SupportedInputs() []string {
+	return media.AudioExtensions
+}
+
+// OutputExtension returns the extension of the configured output format
+func (c *NormalizeConverter) OutputExtension() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return formats[c.options.Format].ext
+}
+
+// Cost returns the planner cost hint; the audio is decoded twice
+func (c *NormalizeConverter) Cost() int {
+	return 10
+}
+
+// Validate checks if the input file is valid
+func (c *NormalizeConverter) Validate(input string) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return fmt.Errorf("cannot access file: %w", err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("input is a directory, not a file")
+	}
+
+	return media.CheckInput(input, c.SupportedInputs())
+}
+
+// Convert processes a single file
+func (c *NormalizeConverter) Convert(ctx context.Context, job converter.Job) (*converter.Result, error) {
+	input, opts := job.Input, job.Options
+
+	c.mu.Lock()
+	options := c.options
+	c.mu.Unlock()
+
+	var (
+		ff    executor.Executor
+		info  *media.MediaInfo
+		audio *media.AudioStream
+		stats executor.LoudnessStats
+	)
+	return converter.RunJob(ctx, job, converter.Task{
+		Validate: c.Validate,
+		Prepare: func() error {
+			var err error
+			if ff, err = c.ffmpeg.Get(); err != nil {
+				return err
+			}
+			if info, err = ff.GetInfo(ctx, input); err != nil {
+				return fmt.Errorf("failed to probe input: %w", err)
+			}
+			if audio = info.PrimaryAudio(); audio == nil {
+				return fmt.Errorf("no audio stream in input")
+			}
+			return nil
+		},
+		Encode: func(partial string) (ffResult *executor.FFmpegResult, err error) {
+			stream := fmt.Sprintf("0:%d", audio.Index)
+			stats, ffResult, err = c.normalize(ctx, ff, input, stream, partial, options.sampleRate(audio.SampleRate), info.Duration(), options, opts)
+			return ffResult, err
+		},
+		Finish: func(result *converter.Result) {
+			result.Loudness = &converter.Loudness{
+				Measured:          converter.LoudnessLevels{I: stats.InputI, TP: stats.InputTP, LRA: stats.InputLRA},
+				Achieved:          converter.LoudnessLevels{I: stats.OutputI, TP: stats.OutputTP, LRA: stats.OutputLRA},
+				NormalizationType: stats.NormalizationType,
+			}
+		},
+	})
+}
+
+// normalize measures the loudness of stream, then writes it normalized to
+// partial and returns the statistics of the second pass
+func (c *NormalizeConverter) normalize(ctx context.Context, ff executor.Executor, input, stream, partial string, sampleRate int, duration time.Duration, options NormalizeOptions, opts converter.Options) (executor.LoudnessStats, *executor.FFmpegResult, error) {
+	ui.PrintVerbose(opts.Verbose, "Pass 1/2: measuring %s", input)
+	measured, ffResult, err := executor.MeasureLoudness(ctx, ff, input, stream, options.Target, converter.RunOptionsFor(opts, input, duration, 0, 2))
+	if err != nil {
+		return executor.LoudnessStats{}, ffResult, fmt.Errorf("loudness measurement failed: %w", err)
+	}
+	if ctx.Err() != nil {
+		return executor.LoudnessStats{}, nil, ctx.Err()
+	}
+	ui.PrintVerbose(opts.Verbose, "Measured %s: %.1f LUFS, %.1f dBTP, %.1f LU", input, measured.InputI, measured.InputTP, measured.InputLRA)
+
+	ui.PrintVerbose(opts.Verbose, "Pass 2/2: normalizing %s", input)
+	filter := options.Target.Filter(measured, sampleRate)
+	ffResult, err = ff.Run(ctx, options.encodeArgs(input, stream, filter, partial), converter.RunOptionsFor(opts, input, duration, 1, 2))
+	if err != nil {
+		return executor.LoudnessStats{}, ffResult, err
+	}
+
+	stats, err := executor.ParseLoudness(ffResult.Stderr)
+	if err != nil {
+		return executor.LoudnessStats{}, ffResult, err
+	}
+	if !stats.Linear() {
+		ui.PrintWarning("%s: a linear gain would exceed the %.1f dBTP true peak target; normalized dynamically, which compresses the loudness range", input, options.Target.TP)
+	}
+	return stats, ffResult, nil
+}
+
+// Preflight verifies that ffmpeg has the loudnorm filter, encoder and muxer
+func (c *NormalizeConverter) Preflight() error {
+	ff, err := c.ffmpeg.Get()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	req := c.options.Requirements()
+	c.mu.Unlock()
+
+	return ff.Preflight(req)
+}
+
+// OptionSchema describes the options of the loudnorm converter
+func (c *NormalizeConverter) OptionSchema() []converter.OptionSpec {
+	return optionSchema()
+}
+
+// Configure applies option values resolved against OptionSchema
+func (c *NormalizeConverter) Configure(values converter.OptionValues) error {
+	return c.SetOptions(optionsFromValues(values))
+}
+
+// SetOptions sets converter-specific options
+func (c *NormalizeConverter) SetOptions(opts NormalizeOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	c.ffmpeg.SetToolchain(opts.Toolchain)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options = opts
+	return nil
+}
+
+// SetExecutor overrides the executor used for conversions
+func (c *NormalizeConverter) SetExecutor(e executor.Executor) {
+	c.ffmpeg.Set(e)
+}
@@ -0,0 +1,121 @@
+package loudness_normalize
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/media"
+)
+
+// loudnormJSON is the statistics block loudnorm prints at the end of stderr
+const loudnormJSON = `[Parsed_loudnorm_0 @ 0x0]
+{
+	"input_i" : "%s",
+	"input_tp" : "-1.20",
+	"input_lra" : "9.80",
+	"input_thresh" : "-28.40",
+	"output_i" : "-16.10",
+	"output_tp" : "-1.60",
+	"output_lra" : "7.30",
+	"output_thresh" : "-26.20",
+	"normalization_type" : "%s",
+	"target_offset" : "0.10"
+}
+`
+
+// fakeFFmpeg answers the measurement pass with measuredI and writes the
+// output of the normalization pass, reporting normalizationType
+type fakeFFmpeg struct {
+	executor.Executor
+	measuredI         string
+	normalizationType string
+}
+
+func (f *fakeFFmpeg) GetInfo(context.Context, string) (*media.MediaInfo, error) {
+	return &media.MediaInfo{Audio: []media.AudioStream{{Stream: media.Stream{Index: 0}, SampleRate: 44100, Channels: 2}}}, nil
+}
+
+func (f *fakeFFmpeg) Run(_ context.Context, args []string, _ executor.RunOptions) (*executor.FFmpegResult, error) {
+	if slices.Contains(args, "null") {
+		return &executor.FFmpegResult{Success: true, Stderr: fmt.Sprintf(loudnormJSON, f.measuredI, "dynamic")}, nil
+	}
+	if err := os.WriteFile(args[len(args)-1], []byte("normalized"), 0644); err != nil {
+		return nil, err
+	}
+	return &executor.FFmpegResult{Success: true, Stderr: fmt.Sprintf(loudnormJSON, "-23.00", f.normalizationType)}, nil
+}
+
+// captureStderr returns what fn writes to stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestConvertNormalizationType(t *testing.T) {
+	tests := []struct {
+		name              string
+		normalizationType string
+		wantWarning       bool
+	}{
+		{"linear", "linear", false},
+		{"dynamic fallback is reported", "dynamic", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := filepath.Join(dir, "in.wav")
+			if err := os.WriteFile(input, []byte("RIFF\x24\x00\x00\x00WAVEfmt "), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			conv := NewNormalizeConverter()
+			conv.SetExecutor(&fakeFFmpeg{measuredI: "-30.50", normalizationType: tt.normalizationType})
+
+			var result *converter.Result
+			var err error
+			stderr := captureStderr(t, func() {
+				result, err = conv.Convert(context.Background(), converter.Job{Input: input, Output: filepath.Join(dir, "out.wav")})
+			})
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+
+			l := result.Loudness
+			if l == nil {
+				t.Fatal("Convert() recorded no loudness")
+			}
+			if l.NormalizationType != tt.normalizationType {
+				t.Errorf("NormalizationType = %q, want %q", l.NormalizationType, tt.normalizationType)
+			}
+			if l.Measured.I != -23 || l.Achieved.I != -16.1 || l.Achieved.TP != -1.6 || l.Achieved.LRA != 7.3 {
+				t.Errorf("Loudness = %+v, want the levels of the normalization pass", *l)
+			}
+			if got := strings.Contains(stderr, "normalized dynamically"); got != tt.wantWarning {
+				t.Errorf("warning printed = %v, want %v (stderr %q)", got, tt.wantWarning, stderr)
+			}
+		})
+	}
+}
@@ -1,5 +1,14 @@
 package mov_to_mp4
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+)
+
 // MP4Options contains MP4-specific conversion options
 type MP4Options struct {
 	// Quality
@@ -17,6 +26,14 @@ type MP4Options struct {
 
 	// Bitrate control
 	VideoBitrate string // e.g., "2M", "5M"
+
+	// Normalize measures the audio loudness in a first pass and encodes it
+	// with a linear correction to the Loudness targets (EBU R128)
+	Normalize bool
+	Loudness  executor.LoudnessTarget
+
+	// Toolchain selects a named ffmpeg/ffprobe pair (empty = default)
+	Toolchain string
 }
 
 // DefaultMP4Options returns default options for MP4 conversion
@@ -27,32 +44,117 @@ func DefaultMP4Options() MP4Options {
 		VideoCodec:   "h264",
 		AudioCodec:   "aac",
 		AudioBitrate: "192k",
+		Loudness:     executor.DefaultLoudnessTarget(),
+	}
+}
+
+// optionSchema declares the options exposed on the command line and in the
+// "mp4" config section
+func optionSchema() []converter.OptionSpec {
+	defaults := DefaultMP4Options()
+	return []converter.OptionSpec{
+		{Name: "quality", Short: "q", Type: converter.OptionInt, Default: defaults.CRF,
+			Help: "CRF quality (0-51, lower = better)"},
+		{Name: "preset", Short: "p", Type: converter.OptionString, Default: defaults.Preset,
+			Allowed: presets,
+			Help:    "encoding preset"},
+		{Name: "codec", Short: "c", Type: converter.OptionString, Default: defaults.VideoCodec,
+			Allowed: []string{"h264", "h265", "hevc", "vp9"},
+			Help:    "video codec"},
+		{Name: "audio", Type: converter.OptionString, Default: defaults.AudioCodec,
+			Allowed: []string{"aac", "mp3", "opus", "copy"},
+			Help:    "audio codec"},
+		{Name: "audio-bitrate", Type: converter.OptionString, Default: defaults.AudioBitrate,
+			Help: "audio bitrate (e.g., 128k, 192k)"},
+		{Name: "bitrate", Short: "b", Type: converter.OptionString,
+			Help: "video bitrate (e.g., 2M, 5M; empty = use CRF)"},
+		{Name: "hw", Type: converter.OptionString,
+			Allowed:   []string{"videotoolbox", "nvenc", "qsv"},
+			Help:      "hardware acceleration",
+			ConfigKey: "hardware.type", EnabledBy: "hardware.enabled"},
+		{Name: "normalize", Type: converter.OptionBool, Default: defaults.Normalize,
+			Help: "normalize audio loudness (two-pass EBU R128)"},
+		{Name: "integrated", Type: converter.OptionFloat, Default: defaults.Loudness.I,
+			Help: "target integrated loudness in LUFS when normalizing (-70 to -5)"},
+		{Name: "true-peak", Type: converter.OptionFloat, Default: defaults.Loudness.TP,
+			Help: "maximum true peak in dBTP when normalizing (-9 to 0)"},
+		{Name: "lra", Type: converter.OptionFloat, Default: defaults.Loudness.LRA,
+			Help: "target loudness range in LU when normalizing (1 to 50)"},
+		{Name: "toolchain", Type: converter.OptionString,
+			Help: "named ffmpeg toolchain from config"},
 	}
 }
 
+// optionsFromValues builds MP4Options from resolved schema values
+func optionsFromValues(values converter.OptionValues) MP4Options {
+	opts := DefaultMP4Options()
+	opts.CRF = values.Int("quality")
+	opts.Preset = values.String("preset")
+	opts.VideoCodec = values.String("codec")
+	opts.AudioCodec = values.String("audio")
+	opts.AudioBitrate = values.String("audio-bitrate")
+	opts.VideoBitrate = values.String("bitrate")
+	opts.HWAccel = values.String("hw")
+	opts.Normalize = values.Bool("normalize")
+	opts.Loudness.I = values.Float("integrated")
+	opts.Loudness.TP = values.Float("true-peak")
+	opts.Loudness.LRA = values.Float("lra")
+	opts.Toolchain = values.String("toolchain")
+	return opts
+}
+
+// presets lists the x264/x265 encoding presets, fastest first
+var presets = []string{"ultrafast", "superfast", "veryfast", "faster", "fast", "medium", "slow", "slower", "veryslow"}
+
 // Validate checks if options are valid
 func (o *MP4Options) Validate() error {
-	// CRF validation
 	if o.CRF < 0 || o.CRF > 51 {
-		o.CRF = 23
+		return fmt.Errorf("quality must be between 0 and 51, got %d", o.CRF)
 	}
-
-	// Preset validation
-	validPresets := map[string]bool{
-		"ultrafast": true,
-		"superfast": true,
-		"veryfast":  true,
-		"faster":    true,
-		"fast":      true,
-		"medium":    true,
-		"slow":      true,
-		"slower":    true,
-		"veryslow":  true,
+	if o.Preset != "" && !slices.Contains(presets, o.Preset) {
+		return fmt.Errorf("invalid preset %q (allowed: %s)", o.Preset, strings.Join(presets, ", "))
 	}
 
-	if o.Preset != "" && !validPresets[o.Preset] {
-		o.Preset = "medium"
+	// Normalization rewrites the audio, so it cannot be copied
+	if o.Normalize {
+		if o.AudioCodec == "copy" {
+			return fmt.Errorf("loudness normalization re-encodes the audio; use --audio aac, mp3 or opus instead of copy")
+		}
+		if err := o.Loudness.Validate(); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// Requirements returns the ffmpeg components these options depend on
+func (o *MP4Options) Requirements() executor.Requirements {
+	req := o.ffmpegOptions().Requirements()
+	req.Muxers = append(req.Muxers, "mp4")
+	if o.Normalize {
+		req = req.Merge(executor.LoudnessRequirements())
+	}
+	return req
+}
+
+// CheckCapabilities verifies that the local ffmpeg supports these options,
+// naming every missing encoder, hwaccel or muxer
+func (o *MP4Options) CheckCapabilities(caps *executor.Capabilities) error {
+	return caps.Check(o.Requirements())
+}
+
+// ffmpegOptions maps MP4 options onto executor options
+func (o *MP4Options) ffmpegOptions() executor.FFmpegOptions {
+	return executor.FFmpegOptions{
+		VideoCodec:    o.VideoCodec,
+		CRF:           o.CRF,
+		Preset:        o.Preset,
+		AudioCodec:    o.AudioCodec,
+		AudioBitrate:  o.AudioBitrate,
+		HWAccel:       o.HWAccel,
+		HWAccelDevice: o.HWAccelDevice,
+		Bitrate:       o.VideoBitrate,
+		Format:        "mp4",
+	}
+}
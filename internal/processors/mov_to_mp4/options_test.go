@@ -0,0 +1,36 @@
+package mov_to_mp4
+
+import "testing"
+
+func TestMP4OptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*MP4Options)
+		wantErr bool
+	}{
+		{"defaults", func(*MP4Options) {}, false},
+		{"lossless", func(o *MP4Options) { o.CRF = 0 }, false},
+		{"worst quality", func(o *MP4Options) { o.CRF = 51 }, false},
+		{"negative quality", func(o *MP4Options) { o.CRF = -1 }, true},
+		{"quality above 51", func(o *MP4Options) { o.CRF = 52 }, true},
+		{"unset preset", func(o *MP4Options) { o.Preset = "" }, false},
+		{"unknown preset", func(o *MP4Options) { o.Preset = "fastest" }, true},
+		{"normalized audio copy", func(o *MP4Options) { o.Normalize, o.AudioCodec = true, "copy" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultMP4Options()
+			tt.modify(&opts)
+			want := opts
+
+			err := opts.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if opts.CRF != want.CRF || opts.Preset != want.Preset {
+				t.Errorf("Validate() changed the options to CRF %d, preset %q", opts.CRF, opts.Preset)
+			}
+		})
+	}
+}
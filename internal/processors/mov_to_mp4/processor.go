@@ -4,23 +4,29 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
-	"time"
+	"slices"
+	"sync"
 
 	"github.com/onedusk/sb/internal/converter"
 	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/media"
 	"github.com/onedusk/sb/internal/ui"
 )
 
 func init() {
 	// Auto-register this converter
-	converter.Register(NewMP4Converter())
+	converter.RegisterFactory(New)
 }
 
-// MP4Converter implements the Converter interface for MOV to MP4 conversion
+// New creates an MP4 converter ready for registration
+func New() converter.Converter {
+	return NewMP4Converter()
+}
+
+// MP4Converter converts MOV and other video files to MP4
 type MP4Converter struct {
-	ffmpeg  *executor.FFmpeg
+	mu      sync.Mutex
+	ffmpeg  executor.Lazy
 	options MP4Options
 }
 
@@ -41,9 +47,11 @@ func (c *MP4Converter) Description() string {
 	return "Convert video files to MP4 format using H.264/H.265 encoding"
 }
 
-// SupportedInputs returns supported input formats
+// SupportedInputs returns the video formats other than MP4 itself
 func (c *MP4Converter) SupportedInputs() []string {
-	return []string{".mov", ".avi", ".mkv", ".flv", ".wmv", ".m4v", ".mpeg", ".mpg", ".webm"}
+	return slices.DeleteFunc(slices.Clone(media.VideoInputs), func(ext string) bool {
+		return ext == c.OutputExtension()
+	})
 }
 
 // OutputExtension returns the output extension
@@ -63,205 +71,138 @@ func (c *MP4Converter) Validate(input string) error {
 		return fmt.Errorf("input is a directory, not a file")
 	}
 
-	// Check if extension is supported
-	ext := strings.ToLower(filepath.Ext(input))
-	supported := false
-	for _, validExt := range c.SupportedInputs() {
-		if ext == validExt {
-			supported = true
-			break
-		}
-	}
-
-	if !supported {
-		return fmt.Errorf("unsupported file format: %s", ext)
-	}
-
-	return nil
+	// Check that the content is a supported format
+	return media.CheckInput(input, c.SupportedInputs())
 }
 
 // Convert processes a single file
-func (c *MP4Converter) Convert(input string, opts converter.Options) (*converter.Result, error) {
-	result := &converter.Result{
-		Input: input,
-	}
-
-	start := time.Now()
+func (c *MP4Converter) Convert(ctx context.Context, job converter.Job) (*converter.Result, error) {
+	input, output, opts := job.Input, job.Output, job.Options
+
+	c.mu.Lock()
+	options := c.options
+	c.mu.Unlock()
+
+	var (
+		ff       executor.Executor
+		ffResult *executor.FFmpegResult
+		filter   string
+	)
+	return converter.RunJob(ctx, job, converter.Task{
+		Validate: c.Validate,
+		Prepare: func() (err error) {
+			ff, err = c.ffmpeg.Get()
+			return err
+		},
+		Encode: func(partial string) (*executor.FFmpegResult, error) {
+			ffmpegOpts := options.ffmpegOptions()
+			ffmpegOpts.Verbose = opts.Verbose
+
+			// Wire per-file progress reporting
+			if opts.OnProgress != nil {
+				duration, err := ff.GetDuration(ctx, input)
+				if err != nil {
+					ui.PrintVerbose(opts.Verbose, "Unable to probe duration of %s: %v", input, err)
+				}
+				ffmpegOpts.Duration = duration
+				ffmpegOpts.Progress = func(p executor.ProgressInfo) {
+					opts.OnProgress(converter.ProgressFrom(p, input, 0, 1))
+				}
+			}
+
+			// Measure the loudness first so the encode can apply a linear correction
+			if options.Normalize {
+				var err error
+				filter, ffResult, err = c.loudnessFilter(ctx, ff, input, options, opts.Verbose)
+				if err != nil {
+					return ffResult, fmt.Errorf("loudness measurement failed: %w", err)
+				}
+				ffmpegOpts.AudioFilter = filter
+			}
+
+			var err error
+			ffResult, err = ff.Convert(ctx, input, partial, ffmpegOpts)
+			return ffResult, err
+		},
+		// The loudnorm pass reports the loudness it achieved
+		Finish: func(result *converter.Result) {
+			if filter == "" {
+				return
+			}
+			stats, err := executor.ParseLoudness(ffResult.Stderr)
+			if err != nil {
+				ui.PrintVerbose(opts.Verbose, "Unable to read achieved loudness of %s: %v", output, err)
+				return
+			}
+			result.Loudness = &converter.Loudness{
+				Measured:          converter.LoudnessLevels{I: stats.InputI, TP: stats.InputTP, LRA: stats.InputLRA},
+				Achieved:          converter.LoudnessLevels{I: stats.OutputI, TP: stats.OutputTP, LRA: stats.OutputLRA},
+				NormalizationType: stats.NormalizationType,
+			}
+			if !stats.Linear() {
+				ui.PrintWarning("%s: a linear gain would exceed the %.1f dBTP true peak target; normalized dynamically, which compresses the loudness range", input, options.Loudness.TP)
+			}
+		},
+	})
+}
 
-	// Validate input
-	if err := c.Validate(input); err != nil {
-		result.Error = err
-		result.Duration = time.Since(start)
-		return result, err
+// loudnessFilter measures the loudness of the audio stream ffmpeg encodes
+// and returns the audio filter normalizing it, or "" if there is no audio
+func (c *MP4Converter) loudnessFilter(ctx context.Context, ff executor.Executor, input string, options MP4Options, verbose bool) (string, *executor.FFmpegResult, error) {
+	info, err := ff.GetInfo(ctx, input)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to probe input: %w", err)
 	}
-
-	// Initialize ffmpeg if needed
-	if c.ffmpeg == nil {
-		ff, err := executor.NewFFmpeg()
-		if err != nil {
-			result.Error = err
-			result.Duration = time.Since(start)
-			return result, err
-		}
-		c.ffmpeg = ff
+	if len(info.Audio) == 0 {
+		ui.PrintVerbose(verbose, "No audio in %s, skipping loudness normalization", input)
+		return "", nil, nil
 	}
 
-	// Determine output path
-	output := c.determineOutputPath(input, opts)
-	result.Output = output
-
-	// Check if output already exists
-	if opts.SkipExisting {
-		if _, err := os.Stat(output); err == nil {
-			result.Skipped = true
-			result.SkipReason = "file already exists"
-			result.Duration = time.Since(start)
-			ui.PrintVerbose(opts.Verbose, "Skipping %s (already exists)", input)
-			return result, nil
+	// Without -map, ffmpeg encodes the audio stream with the most channels
+	audio := &info.Audio[0]
+	for i := range info.Audio {
+		if info.Audio[i].Channels > audio.Channels {
+			audio = &info.Audio[i]
 		}
 	}
 
-	// Get input file size
-	if info, err := os.Stat(input); err == nil {
-		result.InputSize = info.Size()
-	}
-
-	// Dry run mode
-	if opts.DryRun {
-		fmt.Printf("[DRY-RUN] Would convert: %s -> %s\n", input, output)
-		result.Success = true
-		result.Duration = time.Since(start)
-		return result, nil
-	}
-
-	// Create output directory if needed
-	outputDir := filepath.Dir(output)
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		result.Error = fmt.Errorf("failed to create output directory: %w", err)
-		result.Duration = time.Since(start)
-		return result, result.Error
-	}
-
-	// Build ffmpeg options
-	ffmpegOpts := executor.FFmpegOptions{
-		VideoCodec:   c.options.VideoCodec,
-		CRF:          c.options.CRF,
-		Preset:       c.options.Preset,
-		AudioCodec:   c.options.AudioCodec,
-		AudioBitrate: c.options.AudioBitrate,
-		HWAccel:      c.options.HWAccel,
-		Bitrate:      c.options.VideoBitrate,
-		Verbose:      opts.Verbose,
-	}
-
-	// Execute conversion
-	ctx := opts.Context
-	if ctx == nil {
-		ctx = context.Background()
-	}
-
-	ui.PrintVerbose(opts.Verbose, "Converting: %s -> %s", input, output)
-
-	ffResult, err := c.ffmpeg.Convert(ctx, input, output, ffmpegOpts)
-	result.Duration = time.Since(start)
-
+	stream := fmt.Sprintf("0:%d", audio.Index)
+	measured, ffResult, err := executor.MeasureLoudness(ctx, ff, input, stream, options.Loudness, executor.RunOptions{Verbose: verbose})
 	if err != nil {
-		result.Error = fmt.Errorf("conversion failed: %w", err)
-		ui.PrintVerbose(opts.Verbose, "Error: %v", err)
-		if ffResult != nil && ffResult.Stderr != "" {
-			ui.PrintVerbose(opts.Verbose, "FFmpeg stderr: %s", ffResult.Stderr)
-		}
-		return result, result.Error
+		return "", ffResult, err
 	}
+	ui.PrintVerbose(verbose, "Measured %s: %.1f LUFS, %.1f dBTP, %.1f LU", input, measured.InputI, measured.InputTP, measured.InputLRA)
 
-	// Get output file size
-	if info, err := os.Stat(output); err == nil {
-		result.OutputSize = info.Size()
-	}
-
-	result.Success = true
-	ui.PrintVerbose(opts.Verbose, "Successfully converted %s in %s", input, result.Duration.Round(time.Millisecond))
-
-	return result, nil
+	return options.Loudness.Filter(measured, audio.SampleRate), ffResult, nil
 }
 
-// ConvertBatch processes multiple files
-func (c *MP4Converter) ConvertBatch(inputs []string, opts converter.Options) ([]*converter.Result, error) {
-	if len(inputs) == 0 {
-		return nil, fmt.Errorf("no input files provided")
+// Preflight verifies that ffmpeg is available and supports the configured options
+func (c *MP4Converter) Preflight() error {
+	ff, err := c.ffmpeg.Get()
+	if err != nil {
+		return err
 	}
 
-	results := make([]*converter.Result, 0, len(inputs))
-	totalStart := time.Now()
-
-	// Create progress bar
-	showProgress := opts.ShowProgress && !opts.DryRun && !opts.Verbose
-	progress := ui.NewProgressBar(len(inputs), "Converting", showProgress)
-
-	// Create worker pool
-	pool := executor.NewPool(opts.Workers)
-	pool.Start()
-
-	// Submit jobs
-	go func() {
-		for _, input := range inputs {
-			inputCopy := input // Capture for closure
-			pool.Submit(func(ctx context.Context) error {
-				jobOpts := opts
-				jobOpts.Context = ctx
-				result, err := c.Convert(inputCopy, jobOpts)
-				results = append(results, result)
-				progress.Increment()
-
-				if !opts.Verbose && !result.Skipped {
-					if result.Success {
-						fmt.Printf("✓ %s\n", inputCopy)
-					} else {
-						fmt.Printf("✗ %s: %v\n", inputCopy, err)
-					}
-				}
-
-				return err
-			})
-		}
-		pool.Stop()
-	}()
-
-	// Wait for completion
-	errors := make([]error, 0)
-	for err := range pool.Results() {
-		if err != nil {
-			errors = append(errors, err)
-		}
+	caps, err := ff.Capabilities()
+	if err != nil {
+		return err
 	}
 
-	progress.Finish()
-
-	// Calculate statistics
-	totalDuration := time.Since(totalStart)
-	success := 0
-	failed := 0
-	skipped := 0
-
-	for _, result := range results {
-		if result.Skipped {
-			skipped++
-		} else if result.Success {
-			success++
-		} else {
-			failed++
-		}
-	}
+	c.mu.Lock()
+	options := c.options
+	c.mu.Unlock()
 
-	if !opts.Verbose {
-		ui.PrintSummary(len(inputs), success, failed, skipped, totalDuration)
-	}
+	return options.CheckCapabilities(caps)
+}
 
-	if len(errors) > 0 {
-		return results, fmt.Errorf("%d conversion(s) failed", len(errors))
-	}
+// OptionSchema describes the options of the mp4 converter
+func (c *MP4Converter) OptionSchema() []converter.OptionSpec {
+	return optionSchema()
+}
 
-	return results, nil
+// Configure applies option values resolved against OptionSchema
+func (c *MP4Converter) Configure(values converter.OptionValues) error {
+	return c.SetOptions(optionsFromValues(values))
 }
 
 // SetOptions sets converter-specific options
@@ -269,28 +210,16 @@ func (c *MP4Converter) SetOptions(opts MP4Options) error {
 	if err := opts.Validate(); err != nil {
 		return err
 	}
+
+	c.ffmpeg.SetToolchain(opts.Toolchain)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.options = opts
 	return nil
 }
 
-// determineOutputPath calculates the output file path
-func (c *MP4Converter) determineOutputPath(input string, opts converter.Options) string {
-	baseName := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
-	outputName := baseName + c.OutputExtension()
-
-	// If output directory is specified
-	if opts.OutputDir != "" {
-		if opts.FlatStructure {
-			// Flat structure: output all files to output dir
-			return filepath.Join(opts.OutputDir, outputName)
-		} else {
-			// Preserve structure: maintain relative path
-			// For now, just use the output dir with filename
-			// TODO: implement full path preservation
-			return filepath.Join(opts.OutputDir, outputName)
-		}
-	}
-
-	// No output dir specified: place next to input file
-	return filepath.Join(filepath.Dir(input), outputName)
+// SetExecutor overrides the executor used for conversions
+func (c *MP4Converter) SetExecutor(e executor.Executor) {
+	c.ffmpeg.Set(e)
 }
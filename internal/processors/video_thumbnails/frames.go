@@ -0,0 +1,165 @@
+package video_thumbnails
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onedusk/sb/internal/media"
+)
+
+// spaced returns n timestamps evenly spread over duration, each in the
+// middle of its slice so the first and last frames (often black) are avoided
+func spaced(duration time.Duration, n int) []time.Duration {
+	times := make([]time.Duration, n)
+	for i := range times {
+		times[i] = duration * time.Duration(2*i+1) / time.Duration(2*n)
+	}
+	return times
+}
+
+// posterStart returns where the poster search starts, past intros and fades
+func posterStart(duration time.Duration) time.Duration {
+	return time.Duration(float64(duration) * posterSkip)
+}
+
+// frameArgs writes the frame at a timestamp. Seeking before the input is
+// fast and lands on the exact frame since ffmpeg decodes from the previous
+// keyframe.
+func (o *ThumbOptions) frameArgs(input, output string, at time.Duration, width int) []string {
+	args := []string{"-y", "-ss", seconds(at), "-i", input}
+	if f := scale(width); f != "" {
+		args = append(args, "-vf", f)
+	}
+	return append(args, o.codecArgs(output)...)
+}
+
+// posterArgs writes the best non-black frame: black frames are dropped by
+// their blackframe coverage and the thumbnail filter picks the most
+// representative of the remaining frames in the search window
+func (o *ThumbOptions) posterArgs(input, output string, duration time.Duration) []string {
+	start := posterStart(duration)
+	window := min(posterWindow, duration-start)
+
+	filters := []string{
+		"blackframe=amount=0:threshold=32",
+		fmt.Sprintf("metadata=mode=select:key=lavfi.blackframe.pblack:value=%d:function=less", blackThreshold),
+		fmt.Sprintf("thumbnail=n=%d", posterBatch),
+	}
+	if f := scale(o.Width); f != "" {
+		filters = append(filters, f)
+	}
+
+	args := []string{"-y", "-ss", seconds(start), "-t", seconds(window), "-i", input,
+		"-vf", strings.Join(filters, ",")}
+	return append(args, o.codecArgs(output)...)
+}
+
+// sheetArgs writes a contact sheet: one input per tile, each seeked to its
+// timestamp and cut to a single frame, then concatenated and tiled
+func (o *ThumbOptions) sheetArgs(input, output string, duration time.Duration) []string {
+	tiles := o.Columns * o.Rows
+	width := o.Width
+	if width == 0 {
+		width = defaultTileW
+	}
+
+	args := []string{"-y"}
+	graph := []string{}
+	labels := ""
+	for i, at := range spaced(duration, tiles) {
+		args = append(args, "-ss", seconds(at), "-i", input)
+
+		filters := []string{"trim=end_frame=1", "setpts=PTS-STARTPTS", scale(width), "setsar=1"}
+		if o.Timestamps {
+			filters = append(filters, o.drawtext(clock(at), width))
+		}
+		label := fmt.Sprintf("[t%d]", i)
+		graph = append(graph, fmt.Sprintf("[%d:v]%s%s", i, strings.Join(filters, ","), label))
+		labels += label
+	}
+	graph = append(graph, fmt.Sprintf("%sconcat=n=%d:v=1:a=0,tile=%dx%d:padding=%d:margin=%d[sheet]",
+		labels, tiles, o.Columns, o.Rows, sheetPadding, sheetPadding))
+
+	args = append(args, "-filter_complex", strings.Join(graph, ";"), "-map", "[sheet]")
+	return append(args, o.codecArgs(output)...)
+}
+
+// drawtext labels a tile with text in its bottom right corner
+func (o *ThumbOptions) drawtext(text string, width int) string {
+	f := fmt.Sprintf("drawtext=text=%s:x=w-tw-6:y=h-th-6:fontsize=%d:fontcolor=white:box=1:boxcolor=black@0.6:boxborderw=4",
+		filterValue(text), max(12, width/14))
+	if o.Font != "" {
+		f += ":fontfile=" + filterValue(o.Font)
+	}
+	return f
+}
+
+// spriteTile returns the size of a sprite thumbnail, keeping the display
+// aspect ratio of the video at the given width (even, as encoders need)
+func spriteTile(video *media.VideoStream, width int) (int, int) {
+	srcW, srcH := video.DisplaySize()
+	height := width * 9 / 16
+	if srcW > 0 && srcH > 0 {
+		height = width * srcH / srcW
+	}
+	return width, max(2, height/2*2)
+}
+
+// spriteLayout returns the columns and rows holding one thumbnail per
+// interval of duration
+func spriteLayout(duration, interval time.Duration) (int, int) {
+	count := max(1, int((duration+interval-1)/interval))
+	cols := min(spriteColumns, count)
+	return cols, (count + cols - 1) / cols
+}
+
+// spriteArgs writes all sprite thumbnails into a single tiled image. Only
+// keyframes are decoded, which keeps long videos fast at the cost of
+// thumbnails up to a GOP away from their cue.
+func (o *ThumbOptions) spriteArgs(input, output string, duration time.Duration, width, height int) []string {
+	cols, rows := spriteLayout(duration, o.SpriteInterval)
+	filter := fmt.Sprintf("fps=1/%s,scale=%d:%d,setsar=1,tile=%dx%d",
+		seconds(o.SpriteInterval), width, height, cols, rows)
+
+	args := []string{"-y", "-skip_frame", "nokey", "-i", input, "-vf", filter}
+	return append(args, o.codecArgs(output)...)
+}
+
+// spriteTrack returns a WebVTT thumbnail track pointing each interval of
+// duration at its tile of the sprite image
+func spriteTrack(sprite string, duration, interval time.Duration, width, height int) string {
+	cols, _ := spriteLayout(duration, interval)
+
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	for i, at := 0, time.Duration(0); at < duration; i, at = i+1, at+interval {
+		end := min(at+interval, duration)
+		fmt.Fprintf(&b, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n",
+			vttTime(at), vttTime(end), sprite, i%cols*width, i/cols*height, width, height)
+	}
+	return b.String()
+}
+
+// clock formats a timestamp for a contact sheet, e.g., "04:05" or "1:02:03"
+func clock(d time.Duration) string {
+	s := int(d.Seconds())
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
+
+// vttTime formats a WebVTT timestamp, e.g., "00:01:30.000"
+func vttTime(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// filterValue escapes a filter option value for both levels of filtergraph
+// parsing: the option parser (which splits on ':') and the graph parser
+// (which splits on ',', ';' and brackets)
+func filterValue(s string) string {
+	option := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`).Replace(s)
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, `[`, `\[`, `]`, `\]`, `,`, `\,`, `;`, `\;`).Replace(option)
+}
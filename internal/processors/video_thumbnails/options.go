@@ -0,0 +1,225 @@
+package video_thumbnails
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+)
+
+// Limits of the generated images
+const (
+	maxTiles       = 100 // contact sheet tiles
+	spriteColumns  = 10  // sprite sheet columns
+	defaultTileW   = 320 // contact sheet tile width when Width is 0
+	sheetPadding   = 4   // pixels between and around contact sheet tiles
+	posterSkip     = 0.1 // fraction of the video skipped before looking for a poster
+	posterWindow   = 60 * time.Second
+	posterBatch    = 50 // frames the thumbnail filter picks the best one from
+	blackThreshold = 50 // percentage of black pixels that makes a frame "black"
+)
+
+// ThumbOptions contains still image options
+type ThumbOptions struct {
+	// Mode selects what is generated: poster, thumbnails or sheet
+	Mode string
+
+	// Format of the images: jpg, png or webp
+	Format  string
+	Quality int // 1-100 for jpg and webp, higher = better
+
+	// At is the poster timestamp (0 = best non-black frame)
+	At time.Duration
+
+	// Count is the number of evenly spaced thumbnails
+	Count int
+
+	// Width of each image or sheet tile (0 = source; sheets default to 320)
+	Width int
+
+	// Contact sheet layout
+	Columns    int
+	Rows       int
+	Timestamps bool   // draw the timestamp on each tile
+	Font       string // font file for timestamps (empty = fontconfig default)
+
+	// Sprite sheet and WebVTT thumbnail track for player scrubbing previews
+	Sprite         bool
+	SpriteInterval time.Duration
+	SpriteWidth    int
+
+	// Toolchain selects a named ffmpeg/ffprobe pair (empty = default)
+	Toolchain string
+}
+
+// DefaultThumbOptions returns default still image options
+func DefaultThumbOptions() ThumbOptions {
+	return ThumbOptions{
+		Mode:           "poster",
+		Format:         "jpg",
+		Quality:        85,
+		Count:          9,
+		Columns:        4,
+		Rows:           4,
+		Timestamps:     true,
+		SpriteInterval: 10 * time.Second,
+		SpriteWidth:    160,
+	}
+}
+
+// optionSchema declares the options exposed on the command line and in the
+// "thumbs" config section
+func optionSchema() []converter.OptionSpec {
+	defaults := DefaultThumbOptions()
+	return []converter.OptionSpec{
+		{Name: "mode", Short: "m", Type: converter.OptionString, Default: defaults.Mode,
+			Allowed: []string{"poster", "thumbnails", "sheet"},
+			Help:    "what to generate"},
+		{Name: "format", Type: converter.OptionString, Default: defaults.Format,
+			Allowed: []string{"jpg", "png", "webp"},
+			Help:    "image format"},
+		{Name: "quality", Short: "q", Type: converter.OptionInt, Default: defaults.Quality,
+			Help: "jpg/webp quality (1-100, higher = better)"},
+		{Name: "at", Type: converter.OptionDuration,
+			Help: "poster timestamp (e.g., 1m30s; 0 = best non-black frame)"},
+		{Name: "count", Type: converter.OptionInt, Default: defaults.Count,
+			Help: "number of evenly spaced thumbnails"},
+		{Name: "width", Type: converter.OptionInt, Default: defaults.Width,
+			Help: "image or sheet tile width (0 = source; sheets: 320)"},
+		{Name: "columns", Type: converter.OptionInt, Default: defaults.Columns,
+			Help: "contact sheet columns"},
+		{Name: "rows", Type: converter.OptionInt, Default: defaults.Rows,
+			Help: "contact sheet rows"},
+		{Name: "timestamps", Type: converter.OptionBool, Default: defaults.Timestamps,
+			Help: "draw timestamps on contact sheet tiles"},
+		{Name: "font", Type: converter.OptionString,
+			Help: "font file for timestamps (empty = fontconfig default)"},
+		{Name: "sprite", Type: converter.OptionBool, Default: defaults.Sprite,
+			Help: "also write a sprite sheet and WebVTT thumbnail track"},
+		{Name: "sprite-interval", Type: converter.OptionDuration, Default: defaults.SpriteInterval,
+			Help: "time between sprite thumbnails"},
+		{Name: "sprite-width", Type: converter.OptionInt, Default: defaults.SpriteWidth,
+			Help: "sprite thumbnail width"},
+		{Name: "toolchain", Type: converter.OptionString,
+			Help: "named ffmpeg toolchain from config"},
+	}
+}
+
+// optionsFromValues builds ThumbOptions from resolved schema values
+func optionsFromValues(values converter.OptionValues) ThumbOptions {
+	opts := DefaultThumbOptions()
+	opts.Mode = values.String("mode")
+	opts.Format = values.String("format")
+	opts.Quality = values.Int("quality")
+	opts.At = values.Duration("at")
+	opts.Count = values.Int("count")
+	opts.Width = values.Int("width")
+	opts.Columns = values.Int("columns")
+	opts.Rows = values.Int("rows")
+	opts.Timestamps = values.Bool("timestamps")
+	opts.Font = values.String("font")
+	opts.Sprite = values.Bool("sprite")
+	opts.SpriteInterval = values.Duration("sprite-interval")
+	opts.SpriteWidth = values.Int("sprite-width")
+	opts.Toolchain = values.String("toolchain")
+	return opts
+}
+
+// Validate checks if options are valid
+func (o *ThumbOptions) Validate() error {
+	switch o.Mode {
+	case "poster", "thumbnails", "sheet":
+	default:
+		return fmt.Errorf("invalid mode %q (allowed: poster, thumbnails, sheet)", o.Mode)
+	}
+	switch o.Format {
+	case "jpg", "png", "webp":
+	default:
+		return fmt.Errorf("invalid format %q (allowed: jpg, png, webp)", o.Format)
+	}
+	if o.Quality < 1 || o.Quality > 100 {
+		return fmt.Errorf("quality must be between 1 and 100, got %d", o.Quality)
+	}
+	if o.At < 0 {
+		return fmt.Errorf("poster timestamp cannot be negative")
+	}
+	if o.Count < 1 {
+		return fmt.Errorf("count must be at least 1, got %d", o.Count)
+	}
+	if o.Width < 0 || o.SpriteWidth < 16 {
+		return fmt.Errorf("width cannot be negative and sprite-width must be at least 16")
+	}
+	if o.Columns < 1 || o.Rows < 1 || o.Columns*o.Rows > maxTiles {
+		return fmt.Errorf("contact sheet needs 1 to %d tiles, got %dx%d", maxTiles, o.Columns, o.Rows)
+	}
+	if o.Sprite && o.SpriteInterval < time.Second {
+		return fmt.Errorf("sprite-interval must be at least 1s")
+	}
+	return nil
+}
+
+// Requirements returns the ffmpeg components these options depend on
+func (o *ThumbOptions) Requirements() executor.Requirements {
+	req := executor.Requirements{
+		Encoders: []string{o.encoder()},
+		Muxers:   []string{"image2"},
+		Filters:  []string{"scale"},
+	}
+	switch {
+	case o.Mode == "poster" && o.At == 0:
+		req.Filters = append(req.Filters, "blackframe", "metadata", "thumbnail")
+	case o.Mode == "sheet":
+		req.Filters = append(req.Filters, "trim", "setsar", "concat", "tile")
+		if o.Timestamps {
+			req.Filters = append(req.Filters, "drawtext")
+		}
+	}
+	if o.Sprite {
+		req.Filters = append(req.Filters, "fps", "tile")
+	}
+	return req
+}
+
+// ext returns the extension of the generated images
+func (o *ThumbOptions) ext() string {
+	return "." + o.Format
+}
+
+// encoder returns the ffmpeg encoder of the image format
+func (o *ThumbOptions) encoder() string {
+	switch o.Format {
+	case "png":
+		return "png"
+	case "webp":
+		return "libwebp"
+	}
+	return "mjpeg"
+}
+
+// codecArgs returns the arguments writing one image in the configured format
+func (o *ThumbOptions) codecArgs(output string) []string {
+	args := []string{"-frames:v", "1", "-c:v", o.encoder()}
+	switch o.Format {
+	case "jpg":
+		// mjpeg's -q:v runs from 2 (best) to 31 (worst)
+		args = append(args, "-q:v", strconv.Itoa(31-(o.Quality-1)*29/99))
+	case "webp":
+		args = append(args, "-quality", strconv.Itoa(o.Quality))
+	}
+	return append(args, "-update", "1", "-f", "image2", output)
+}
+
+// scale returns a scale filter for width (empty when keeping the source size)
+func scale(width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("scale=%d:-2", width)
+}
+
+// seconds formats a duration as ffmpeg seconds, e.g., "90.5"
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,280 @@
+package video_thumbnails
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/fsutil"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/ui"
+)
+
+func init() {
+	// Auto-register this converter
+	converter.RegisterFactory(New)
+}
+
+// New creates a still image converter ready for registration
+func New() converter.Converter {
+	return NewThumbConverter()
+}
+
+// ThumbConverter extracts still images from videos: a poster frame, evenly
+// spaced thumbnails or a contact sheet, optionally with a sprite sheet and
+// WebVTT track for player scrubbing previews
+type ThumbConverter struct {
+	mu      sync.Mutex
+	ffmpeg  executor.Lazy
+	options ThumbOptions
+}
+
+// NewThumbConverter creates a new still image converter
+func NewThumbConverter() *ThumbConverter {
+	return &ThumbConverter{
+		options: DefaultThumbOptions(),
+	}
+}
+
+// still is one file written for an input
+type still struct {
+	output   string
+	partial  string
+	args     []string // ffmpeg arguments writing partial (nil = vtt)
+	fallback []string // ffmpeg arguments to retry with when args wrote no image
+	vtt      string   // WebVTT track contents
+}
+
+// Name returns the converter name
+func (c *ThumbConverter) Name() string {
+	return "thumbs"
+}
+
+// Description returns the converter description
+func (c *ThumbConverter) Description() string {
+	return "Extract poster frames, thumbnails and contact sheets from videos"
+}
+
+// SupportedInputs returns supported input formats
+func (c *ThumbConverter) SupportedInputs() []string {
+	return slices.Clone(media.VideoInputs)
+}
+
+// OutputExtension returns the extension of the configured image format
+func (c *ThumbConverter) OutputExtension() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.options.ext()
+}
+
+// Validate checks if the input file is valid
+func (c *ThumbConverter) Validate(input string) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return fmt.Errorf("cannot access file: %w", err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("input is a directory, not a file")
+	}
+
+	return media.CheckInput(input, c.SupportedInputs())
+}
+
+// Convert processes a single file. Every file is written to a partial file,
+// and all are moved into place once each is done.
+func (c *ThumbConverter) Convert(ctx context.Context, job converter.Job) (*converter.Result, error) {
+	input, output, opts := job.Input, job.Output, job.Options
+
+	c.mu.Lock()
+	options := c.options
+	c.mu.Unlock()
+
+	var (
+		ff     executor.Executor
+		stills []still
+	)
+	return converter.RunJob(ctx, job, converter.Task{
+		Validate: c.Validate,
+		// The stills are planned by Prepare; the skip check looks at the job
+		// output only
+		Outputs: func() []string {
+			if stills == nil {
+				return []string{output}
+			}
+			outputs := []string{}
+			for _, s := range stills {
+				outputs = append(outputs, s.output)
+			}
+			return outputs
+		},
+		// Frames are spaced over the probed duration
+		Prepare: func() error {
+			var err error
+			if ff, err = c.ffmpeg.Get(); err != nil {
+				return err
+			}
+			info, err := ff.GetInfo(ctx, input)
+			if err != nil {
+				return fmt.Errorf("failed to probe input: %w", err)
+			}
+			video := info.PrimaryVideo()
+			if video == nil {
+				return fmt.Errorf("no video stream in input")
+			}
+			duration := info.Duration()
+			if duration <= 0 {
+				return fmt.Errorf("unable to determine the duration of the input")
+			}
+			stills = plan(input, output, video, duration, options)
+			return nil
+		},
+		Encode: func(string) (*executor.FFmpegResult, error) {
+			for i, s := range stills {
+				if opts.OnProgress != nil {
+					opts.OnProgress(converter.ProgressFrom(executor.ProgressInfo{}, input, i, len(stills)))
+				}
+				ffResult, err := c.write(ctx, ff, input, s, opts.Verbose)
+				if err != nil || ctx.Err() != nil {
+					return ffResult, err
+				}
+			}
+			return nil, nil
+		},
+		Commit: func(string) error {
+			for _, s := range stills {
+				if err := fsutil.Commit(s.partial, s.output); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Discard: func(string) {
+			for _, s := range stills {
+				fsutil.Discard(s.partial)
+			}
+		},
+	})
+}
+
+// plan lists the files to write for the configured mode. The first one is
+// always output; extra thumbnails are numbered next to it, and the sprite
+// sheet and WebVTT track share its base name.
+func plan(input, output string, video *media.VideoStream, duration time.Duration, options ThumbOptions) []still {
+	ext := filepath.Ext(output)
+	base := strings.TrimSuffix(output, ext)
+	newStill := func(name string) still {
+		return still{output: name, partial: fsutil.PartialPath(name)}
+	}
+
+	var stills []still
+	switch options.Mode {
+	case "poster":
+		s := newStill(output)
+		if options.At > 0 {
+			s.args = options.frameArgs(input, s.partial, options.At, options.Width)
+		} else {
+			// A video that is black throughout the search window yields no
+			// frame; fall back to the frame the search started at
+			s.args = options.posterArgs(input, s.partial, duration)
+			s.fallback = options.frameArgs(input, s.partial, posterStart(duration), options.Width)
+		}
+		stills = append(stills, s)
+	case "thumbnails":
+		for i, at := range spaced(duration, options.Count) {
+			s := newStill(output)
+			if i > 0 {
+				s = newStill(fmt.Sprintf("%s_%d%s", base, i+1, ext))
+			}
+			s.args = options.frameArgs(input, s.partial, at, options.Width)
+			stills = append(stills, s)
+		}
+	case "sheet":
+		s := newStill(output)
+		s.args = options.sheetArgs(input, s.partial, duration)
+		stills = append(stills, s)
+	}
+
+	if options.Sprite {
+		width, height := spriteTile(video, options.SpriteWidth)
+		sprite := newStill(base + "_sprite" + ext)
+		sprite.args = options.spriteArgs(input, sprite.partial, duration, width, height)
+		track := newStill(base + ".vtt")
+		track.vtt = spriteTrack(filepath.Base(sprite.output), duration, options.SpriteInterval, width, height)
+		stills = append(stills, sprite, track)
+	}
+	return stills
+}
+
+// write creates the partial file of one still
+func (c *ThumbConverter) write(ctx context.Context, ff executor.Executor, input string, s still, verbose bool) (*executor.FFmpegResult, error) {
+	if s.args == nil {
+		return nil, os.WriteFile(s.partial, []byte(s.vtt), 0644)
+	}
+
+	ffResult, err := ff.Run(ctx, s.args, executor.RunOptions{Verbose: verbose})
+	if err == nil && s.fallback != nil && empty(s.partial) {
+		ui.PrintVerbose(verbose, "No non-black frame found in %s; using a plain frame", input)
+		ffResult, err = ff.Run(ctx, s.fallback, executor.RunOptions{Verbose: verbose})
+	}
+	if err == nil && empty(s.partial) {
+		err = fmt.Errorf("ffmpeg wrote no image to %s", s.output)
+	}
+	return ffResult, err
+}
+
+// empty reports whether ffmpeg left no image at path
+func empty(path string) bool {
+	info, err := os.Stat(path)
+	return err != nil || info.Size() == 0
+}
+
+// Preflight verifies that ffmpeg has the image encoder and filters
+func (c *ThumbConverter) Preflight() error {
+	ff, err := c.ffmpeg.Get()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	req := c.options.Requirements()
+	c.mu.Unlock()
+
+	return ff.Preflight(req)
+}
+
+// OptionSchema describes the options of the thumbs converter
+func (c *ThumbConverter) OptionSchema() []converter.OptionSpec {
+	return optionSchema()
+}
+
+// Configure applies option values resolved against OptionSchema
+func (c *ThumbConverter) Configure(values converter.OptionValues) error {
+	return c.SetOptions(optionsFromValues(values))
+}
+
+// SetOptions sets converter-specific options
+func (c *ThumbConverter) SetOptions(opts ThumbOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	c.ffmpeg.SetToolchain(opts.Toolchain)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options = opts
+	return nil
+}
+
+// SetExecutor overrides the executor used for conversions
+func (c *ThumbConverter) SetExecutor(e executor.Executor) {
+	c.ffmpeg.Set(e)
+}
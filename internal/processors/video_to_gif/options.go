@@ -0,0 +1,220 @@
+package video_to_gif
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+)
+
+// Lower bounds of the max-size search
+const (
+	minFPS   = 5
+	minWidth = 120
+)
+
+// GIFOptions contains GIF-specific conversion options
+type GIFOptions struct {
+	FPS   int // frames per second (default: 15)
+	Width int // output width in pixels, height follows the aspect ratio (0 = source)
+
+	// Window of the input to convert
+	Start    time.Duration // offset into the input
+	Duration time.Duration // length of the clip (0 = to the end)
+
+	// Loop is the GIF loop count: 0 = forever, -1 = play once, N = N repeats
+	Loop int
+
+	// MaxSize is a file size target such as "5M" or "800k" (empty = none);
+	// fps and width are lowered until the GIF fits
+	MaxSize string
+
+	// Palette generation and dithering
+	Dither    string // sierra2_4a, floyd_steinberg, bayer, sierra2, none
+	StatsMode string // full (whole clip) or diff (moving parts, for screen recordings)
+
+	// Toolchain selects a named ffmpeg/ffprobe pair (empty = default)
+	Toolchain string
+}
+
+// DefaultGIFOptions returns default options for GIF conversion
+func DefaultGIFOptions() GIFOptions {
+	return GIFOptions{
+		FPS:       15,
+		Width:     480,
+		Dither:    "sierra2_4a",
+		StatsMode: "full",
+	}
+}
+
+// optionSchema declares the options exposed on the command line and in the
+// "gif" config section
+func optionSchema() []converter.OptionSpec {
+	defaults := DefaultGIFOptions()
+	return []converter.OptionSpec{
+		{Name: "fps", Type: converter.OptionInt, Default: defaults.FPS,
+			Help: "frames per second"},
+		{Name: "width", Type: converter.OptionInt, Default: defaults.Width,
+			Help: "width in pixels, height keeps the aspect ratio (0 = source)"},
+		{Name: "start", Type: converter.OptionDuration,
+			Help: "start offset into the video (e.g., 1m30s)"},
+		{Name: "duration", Short: "t", Type: converter.OptionDuration,
+			Help: "clip length (e.g., 10s; 0 = to the end)"},
+		{Name: "loop", Type: converter.OptionInt, Default: defaults.Loop,
+			Help: "loop count (0 = forever, -1 = play once, N = repeat N times)"},
+		{Name: "max-size", Type: converter.OptionString,
+			Help: "target file size (e.g., 5M, 800k); lowers fps and width until it fits"},
+		{Name: "dither", Type: converter.OptionString, Default: defaults.Dither,
+			Allowed: []string{"sierra2_4a", "sierra2", "floyd_steinberg", "bayer", "none"},
+			Help:    "dithering algorithm"},
+		{Name: "stats-mode", Type: converter.OptionString, Default: defaults.StatsMode,
+			Allowed: []string{"full", "diff"},
+			Help:    "palette statistics (diff favors moving parts, e.g., screen recordings)"},
+		{Name: "toolchain", Type: converter.OptionString,
+			Help: "named ffmpeg toolchain from config"},
+	}
+}
+
+// optionsFromValues builds GIFOptions from resolved schema values
+func optionsFromValues(values converter.OptionValues) GIFOptions {
+	opts := DefaultGIFOptions()
+	opts.FPS = values.Int("fps")
+	opts.Width = values.Int("width")
+	opts.Start = values.Duration("start")
+	opts.Duration = values.Duration("duration")
+	opts.Loop = values.Int("loop")
+	opts.MaxSize = values.String("max-size")
+	opts.Dither = values.String("dither")
+	opts.StatsMode = values.String("stats-mode")
+	opts.Toolchain = values.String("toolchain")
+	return opts
+}
+
+// Validate checks if options are valid
+func (o *GIFOptions) Validate() error {
+	if o.FPS < 1 || o.FPS > 50 {
+		return fmt.Errorf("fps must be between 1 and 50, got %d", o.FPS)
+	}
+	if o.Width < 0 {
+		return fmt.Errorf("width cannot be negative")
+	}
+	if o.Start < 0 || o.Duration < 0 {
+		return fmt.Errorf("start and duration cannot be negative")
+	}
+	if o.Loop < -1 {
+		return fmt.Errorf("loop must be -1 (play once), 0 (forever) or a repeat count")
+	}
+	if _, err := o.maxBytes(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Requirements returns the ffmpeg components these options depend on
+func (o *GIFOptions) Requirements() executor.Requirements {
+	return executor.Requirements{
+		Encoders: []string{"gif", "png"},
+		Decoders: []string{"png"},
+		Filters:  []string{"fps", "scale", "palettegen", "paletteuse"},
+		Muxers:   []string{"gif", "image2"},
+	}
+}
+
+// maxBytes returns MaxSize in bytes (0 = no target)
+func (o *GIFOptions) maxBytes() (int64, error) {
+	if o.MaxSize == "" {
+		return 0, nil
+	}
+	n, err := parseSize(o.MaxSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max-size %q: %w", o.MaxSize, err)
+	}
+	return n, nil
+}
+
+// attempt is one encoding setting tried while fitting the size target
+type attempt struct {
+	fps   int
+	width int // 0 = source width
+}
+
+// inputArgs selects the clip window
+func (o *GIFOptions) inputArgs(input string) []string {
+	args := []string{"-y"}
+	if o.Start > 0 {
+		args = append(args, "-ss", formatSeconds(o.Start))
+	}
+	if o.Duration > 0 {
+		args = append(args, "-t", formatSeconds(o.Duration))
+	}
+	return append(args, "-i", input)
+}
+
+// scaleFilter returns the fps and scale filters of an attempt
+func scaleFilter(a attempt) string {
+	filter := "fps=" + strconv.Itoa(a.fps)
+	if a.width > 0 {
+		filter += fmt.Sprintf(",scale=%d:-1:flags=lanczos", a.width)
+	}
+	return filter
+}
+
+// paletteArgs builds the first pass: an optimal 256-color palette for the clip
+func (o *GIFOptions) paletteArgs(input, palette string, a attempt) []string {
+	return append(o.inputArgs(input),
+		"-vf", scaleFilter(a)+",palettegen=stats_mode="+o.StatsMode,
+		"-frames:v", "1",
+		"-update", "1",
+		"-c:v", "png",
+		"-f", "image2",
+		palette,
+	)
+}
+
+// gifArgs builds the second pass: the clip mapped onto the palette
+func (o *GIFOptions) gifArgs(input, palette, output string, a attempt) []string {
+	args := append(o.inputArgs(input), "-i", palette)
+	return append(args,
+		"-lavfi", fmt.Sprintf("[0:v]%s[x];[x][1:v]paletteuse=dither=%s", scaleFilter(a), o.Dither),
+		"-an",
+		"-loop", strconv.Itoa(o.Loop),
+		"-f", "gif",
+		output,
+	)
+}
+
+// formatSeconds formats a duration as ffmpeg seconds, e.g., "90.5"
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// parseSize parses a size with an optional binary unit suffix, e.g., "5M"
+// (5 MiB), "800k", "1.5MB" or "2048"
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(strings.ToUpper(s))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+
+	multiplier := int64(1)
+	if n := len(s); n > 0 {
+		switch s[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			s = s[:n-1]
+		}
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("expected a positive size such as 5M or 800k")
+	}
+	return int64(v * float64(multiplier)), nil
+}
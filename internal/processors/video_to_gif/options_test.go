@@ -0,0 +1,45 @@
+package video_to_gif
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPaletteArgs(t *testing.T) {
+	opts := DefaultGIFOptions()
+	opts.Start = 1500 * time.Millisecond
+
+	got := strings.Join(opts.paletteArgs("in.mov", "out.gif.sb-partial.png", attempt{fps: 10, width: 320}), " ")
+	want := "-y -ss 1.5 -i in.mov -vf fps=10,scale=320:-1:flags=lanczos,palettegen=stats_mode=" + opts.StatsMode +
+		" -frames:v 1 -update 1 -c:v png -f image2 out.gif.sb-partial.png"
+	if got != want {
+		t.Errorf("paletteArgs() = %s\nwant %s", got, want)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"2048", 2048, false},
+		{"800k", 800 << 10, false},
+		{"5M", 5 << 20, false},
+		{"1.5MB", 3 << 19, false},
+		{"2MiB", 2 << 20, false},
+		{" 1g ", 1 << 30, false},
+		{"0", 0, true},
+		{"-5M", 0, true},
+		{"big", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v, want %d (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
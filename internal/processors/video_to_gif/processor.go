@@ -0,0 +1,245 @@
+package video_to_gif
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/fsutil"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/ui"
+)
+
+func init() {
+	// Auto-register this converter
+	converter.RegisterFactory(New)
+}
+
+// New creates a GIF converter ready for registration
+func New() converter.Converter {
+	return NewGIFConverter()
+}
+
+// GIFConverter turns video clips into GIFs with a two-pass palette: the
+// first pass computes an optimal 256-color palette for the clip, the
+// second maps the frames onto it with dithering
+type GIFConverter struct {
+	mu      sync.Mutex
+	ffmpeg  executor.Lazy
+	options GIFOptions
+}
+
+// NewGIFConverter creates a new GIF converter
+func NewGIFConverter() *GIFConverter {
+	return &GIFConverter{
+		options: DefaultGIFOptions(),
+	}
+}
+
+// Name returns the converter name
+func (c *GIFConverter) Name() string {
+	return "gif"
+}
+
+// Description returns the converter description
+func (c *GIFConverter) Description() string {
+	return "Convert video clips to GIF with an optimized palette"
+}
+
+// SupportedInputs returns supported input formats
+func (c *GIFConverter) SupportedInputs() []string {
+	return slices.Clone(media.VideoInputs)
+}
+
+// OutputExtension returns the output extension
+func (c *GIFConverter) OutputExtension() string {
+	return ".gif"
+}
+
+// Cost returns the planner cost hint; GIF is lossy and slow to encode
+func (c *GIFConverter) Cost() int {
+	return 20
+}
+
+// Validate checks if the input file is valid
+func (c *GIFConverter) Validate(input string) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return fmt.Errorf("cannot access file: %w", err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("input is a directory, not a file")
+	}
+
+	return media.CheckInput(input, c.SupportedInputs())
+}
+
+// Convert processes a single file
+func (c *GIFConverter) Convert(ctx context.Context, job converter.Job) (*converter.Result, error) {
+	input, output, opts := job.Input, job.Output, job.Options
+
+	c.mu.Lock()
+	options := c.options
+	c.mu.Unlock()
+
+	var ff executor.Executor
+	return converter.RunJob(ctx, job, converter.Task{
+		Validate: c.Validate,
+		Prepare: func() (err error) {
+			ff, err = c.ffmpeg.Get()
+			return err
+		},
+		Encode: func(partial string) (*executor.FFmpegResult, error) {
+			info, err := ff.GetInfo(ctx, input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to probe input: %w", err)
+			}
+			video := info.PrimaryVideo()
+			if video == nil {
+				return nil, fmt.Errorf("no video stream in input")
+			}
+
+			// Report per-file progress for the second pass
+			runOpts := converter.RunOptionsFor(opts, input, clipLength(info.Duration(), options), 0, 1)
+
+			palette := fsutil.PartialPath(strings.TrimSuffix(output, filepath.Ext(output)) + ".palette.png")
+			defer fsutil.Discard(palette)
+
+			return c.encode(ctx, ff, input, partial, palette, video, options, runOpts)
+		},
+	})
+}
+
+// encode runs both passes, lowering fps and width in turns until the GIF
+// meets the size target
+func (c *GIFConverter) encode(ctx context.Context, ff executor.Executor, input, partial, palette string, video *media.VideoStream, options GIFOptions, runOpts executor.RunOptions) (*executor.FFmpegResult, error) {
+	maxBytes, err := options.maxBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	// Never upscale; the size search needs the real width to shrink from
+	sourceWidth, _ := video.DisplaySize()
+	a := attempt{fps: options.FPS, width: options.Width}
+	if a.width == 0 || a.width > sourceWidth && sourceWidth > 0 {
+		a.width = sourceWidth
+	}
+
+	paletteOpts := runOpts
+	paletteOpts.Progress = nil
+
+	for round := 0; ; round++ {
+		if ffResult, err := ff.Run(ctx, options.paletteArgs(input, palette, a), paletteOpts); err != nil {
+			return ffResult, fmt.Errorf("palette generation failed: %w", err)
+		}
+		ffResult, err := ff.Run(ctx, options.gifArgs(input, palette, partial, a), runOpts)
+		if err != nil {
+			return ffResult, err
+		}
+
+		info, err := os.Stat(partial)
+		if err != nil {
+			return ffResult, err
+		}
+		if maxBytes == 0 || info.Size() <= maxBytes {
+			return ffResult, nil
+		}
+
+		next, ok := shrink(a, round)
+		if !ok {
+			return ffResult, fmt.Errorf("GIF is %s at %d fps and %dpx, still over the %s limit",
+				ui.FormatBytes(info.Size()), a.fps, a.width, ui.FormatBytes(maxBytes))
+		}
+		ui.PrintVerbose(runOpts.Verbose, "GIF is %s, over the %s limit; retrying at %d fps, %dpx",
+			ui.FormatBytes(info.Size()), ui.FormatBytes(maxBytes), next.fps, next.width)
+		a = next
+	}
+}
+
+// shrink returns the next, smaller attempt: fps and width are lowered in
+// turns (by 20% and 15%) down to minFPS and minWidth
+func shrink(a attempt, round int) (attempt, bool) {
+	lowerFPS := func() bool {
+		if a.fps <= minFPS {
+			return false
+		}
+		a.fps = max(minFPS, a.fps*4/5)
+		return true
+	}
+	lowerWidth := func() bool {
+		if a.width <= minWidth {
+			return false
+		}
+		a.width = max(minWidth, a.width*85/100)
+		return true
+	}
+
+	if round%2 == 0 {
+		if lowerFPS() || lowerWidth() {
+			return a, true
+		}
+	} else if lowerWidth() || lowerFPS() {
+		return a, true
+	}
+	return a, false
+}
+
+// clipLength returns the length of the converted window of an input
+func clipLength(total time.Duration, options GIFOptions) time.Duration {
+	length := total - options.Start
+	if options.Duration > 0 && (options.Duration < length || length <= 0) {
+		length = options.Duration
+	}
+	return max(length, 0)
+}
+
+// Preflight verifies that ffmpeg has the palette filters and GIF encoder
+func (c *GIFConverter) Preflight() error {
+	ff, err := c.ffmpeg.Get()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	req := c.options.Requirements()
+	c.mu.Unlock()
+
+	return ff.Preflight(req)
+}
+
+// OptionSchema describes the options of the gif converter
+func (c *GIFConverter) OptionSchema() []converter.OptionSpec {
+	return optionSchema()
+}
+
+// Configure applies option values resolved against OptionSchema
+func (c *GIFConverter) Configure(values converter.OptionValues) error {
+	return c.SetOptions(optionsFromValues(values))
+}
+
+// SetOptions sets converter-specific options
+func (c *GIFConverter) SetOptions(opts GIFOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	c.ffmpeg.SetToolchain(opts.Toolchain)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options = opts
+	return nil
+}
+
+// SetExecutor overrides the executor used for conversions
+func (c *GIFConverter) SetExecutor(e executor.Executor) {
+	c.ffmpeg.Set(e)
+}
@@ -0,0 +1,262 @@
+package web_video
+
+import (
+	"fmt"
+	"math/bits"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+)
+
+// WebOptions contains WebM/AV1 conversion options
+type WebOptions struct {
+	// Codecs
+	Codec      string // vp9 or av1
+	AV1Encoder string // libaom-av1 or libsvtav1
+
+	// Rate control: constant quality, optionally capped at MaxBitrate
+	CRF        int    // 0-63, lower = better quality
+	MaxBitrate string // e.g., "2M" (empty = uncapped CRF)
+	Passes     int    // 1 or 2
+
+	// Speed
+	CPUUsed     int  // libvpx/libaom -cpu-used, libsvtav1 -preset (higher = faster)
+	RowMT       bool // row-based multithreading
+	TileColumns int  // log2 of the tile columns (-1 = from the video width)
+
+	// Audio
+	Audio        string // opus, aac, copy or none (per target)
+	AudioBitrate string // e.g., "128k"
+
+	// Toolchain selects a named ffmpeg/ffprobe pair (empty = default)
+	Toolchain string
+}
+
+// DefaultWebOptions returns default options for a target
+func DefaultWebOptions(t target) WebOptions {
+	return WebOptions{
+		Codec:        t.codecs[0],
+		AV1Encoder:   "libaom-av1",
+		CRF:          t.crf,
+		Passes:       2,
+		CPUUsed:      t.cpuUsed,
+		RowMT:        true,
+		TileColumns:  -1,
+		Audio:        t.audio[0],
+		AudioBitrate: "128k",
+	}
+}
+
+// optionSchema declares the options exposed on the command line and in the
+// config section of a target
+func optionSchema(t target) []converter.OptionSpec {
+	defaults := DefaultWebOptions(t)
+	schema := []converter.OptionSpec{}
+	if len(t.codecs) > 1 {
+		schema = append(schema, converter.OptionSpec{
+			Name: "codec", Short: "c", Type: converter.OptionString, Default: defaults.Codec,
+			Allowed: t.codecs,
+			Help:    "video codec"})
+	}
+	return append(schema,
+		converter.OptionSpec{Name: "av1-encoder", Type: converter.OptionString, Default: defaults.AV1Encoder,
+			Allowed: []string{"libaom-av1", "libsvtav1"},
+			Help:    "AV1 encoder (libsvtav1 is faster but single-pass only)"},
+		converter.OptionSpec{Name: "quality", Short: "q", Type: converter.OptionInt, Default: defaults.CRF,
+			Help: "CRF quality (0-63, lower = better)"},
+		converter.OptionSpec{Name: "max-bitrate", Short: "b", Type: converter.OptionString,
+			Help: "bitrate cap for constrained quality (e.g., 2M; empty = uncapped)"},
+		converter.OptionSpec{Name: "passes", Type: converter.OptionInt, Default: defaults.Passes,
+			Help: "encoding passes (1 or 2)"},
+		converter.OptionSpec{Name: "cpu-used", Type: converter.OptionInt, Default: defaults.CPUUsed,
+			Help: "speed (libvpx 0-8, libaom 0-8, libsvtav1 preset 0-13; higher = faster)"},
+		converter.OptionSpec{Name: "row-mt", Type: converter.OptionBool, Default: defaults.RowMT,
+			Help: "row-based multithreading"},
+		converter.OptionSpec{Name: "tile-columns", Type: converter.OptionInt, Default: defaults.TileColumns,
+			Help: "log2 of tile columns (0-6; -1 = from the video width)"},
+		converter.OptionSpec{Name: "audio", Type: converter.OptionString, Default: defaults.Audio,
+			Allowed: t.audio,
+			Help:    "audio codec"},
+		converter.OptionSpec{Name: "audio-bitrate", Type: converter.OptionString, Default: defaults.AudioBitrate,
+			Help: "audio bitrate (e.g., 96k, 128k)"},
+		converter.OptionSpec{Name: "toolchain", Type: converter.OptionString,
+			Help: "named ffmpeg toolchain from config"},
+	)
+}
+
+// optionsFromValues builds WebOptions from resolved schema values
+func optionsFromValues(t target, values converter.OptionValues) WebOptions {
+	opts := DefaultWebOptions(t)
+	if len(t.codecs) > 1 {
+		opts.Codec = values.String("codec")
+	}
+	opts.AV1Encoder = values.String("av1-encoder")
+	opts.CRF = values.Int("quality")
+	opts.MaxBitrate = values.String("max-bitrate")
+	opts.Passes = values.Int("passes")
+	opts.CPUUsed = values.Int("cpu-used")
+	opts.RowMT = values.Bool("row-mt")
+	opts.TileColumns = values.Int("tile-columns")
+	opts.Audio = values.String("audio")
+	opts.AudioBitrate = values.String("audio-bitrate")
+	opts.Toolchain = values.String("toolchain")
+	return opts
+}
+
+// Validate checks the options against the target
+func (o *WebOptions) Validate(t target) error {
+	if !slices.Contains(t.codecs, o.Codec) {
+		return fmt.Errorf("invalid codec %q for %s (allowed: %s)", o.Codec, t.name, strings.Join(t.codecs, ", "))
+	}
+	if !slices.Contains(t.audio, o.Audio) {
+		return fmt.Errorf("invalid audio codec %q for %s (allowed: %s)", o.Audio, t.name, strings.Join(t.audio, ", "))
+	}
+	if o.CRF < 0 || o.CRF > 63 {
+		return fmt.Errorf("quality must be between 0 and 63, got %d", o.CRF)
+	}
+	if o.Passes != 1 && o.Passes != 2 {
+		return fmt.Errorf("passes must be 1 or 2, got %d", o.Passes)
+	}
+	if o.TileColumns < -1 || o.TileColumns > 6 {
+		return fmt.Errorf("tile-columns must be between 0 and 6 (or -1 for auto), got %d", o.TileColumns)
+	}
+
+	encoder := o.encoder()
+	if o.Passes == 2 && encoder == "libsvtav1" {
+		return fmt.Errorf("libsvtav1 supports single-pass encoding only; use --passes 1 or --av1-encoder libaom-av1")
+	}
+	maxSpeed := 8
+	if encoder == "libsvtav1" {
+		maxSpeed = 13
+	}
+	if o.CPUUsed < 0 || o.CPUUsed > maxSpeed {
+		return fmt.Errorf("cpu-used must be between 0 and %d for %s, got %d", maxSpeed, encoder, o.CPUUsed)
+	}
+	return nil
+}
+
+// Requirements returns the ffmpeg components these options depend on
+func (o *WebOptions) Requirements(t target) executor.Requirements {
+	req := executor.Requirements{
+		Encoders: []string{o.encoder()},
+		Muxers:   []string{t.muxer},
+	}
+	if enc := o.audioEncoder(); enc != "" {
+		req.Encoders = append(req.Encoders, enc)
+	}
+	if o.Passes == 2 {
+		req.Muxers = append(req.Muxers, "null")
+	}
+	return req
+}
+
+// encoder returns the ffmpeg video encoder
+func (o *WebOptions) encoder() string {
+	if o.Codec == "av1" {
+		return o.AV1Encoder
+	}
+	return executor.EncoderFor(o.Codec, "")
+}
+
+// audioEncoder returns the ffmpeg audio encoder ("" = copy or no audio)
+func (o *WebOptions) audioEncoder() string {
+	switch o.Audio {
+	case "copy", "none":
+		return ""
+	}
+	return executor.AudioEncoderFor(o.Audio)
+}
+
+// tileColumns returns the log2 tile column count for a video width: about
+// one column per 256 pixels, as recommended for libvpx and libaom
+func (o *WebOptions) tileColumns(width int) int {
+	if o.TileColumns >= 0 {
+		return o.TileColumns
+	}
+	if width < 512 {
+		return 0
+	}
+	return min(bits.Len(uint(width/256))-1, 6)
+}
+
+// videoArgs returns the video encoding arguments for a pass (0 = single
+// pass, 1 or 2 with passlog as the statistics file prefix)
+func (o *WebOptions) videoArgs(pass int, passlog string, width int) []string {
+	encoder := o.encoder()
+	args := []string{"-c:v", encoder, "-pix_fmt", "yuv420p", "-crf", strconv.Itoa(o.CRF)}
+
+	// Constrained quality: the CRF target with a bitrate ceiling
+	switch {
+	case encoder == "libsvtav1" && o.MaxBitrate != "":
+		args = append(args, "-maxrate", o.MaxBitrate)
+	case encoder == "libsvtav1":
+	case o.MaxBitrate != "":
+		args = append(args, "-b:v", o.MaxBitrate)
+	default:
+		args = append(args, "-b:v", "0")
+	}
+
+	tiles := strconv.Itoa(o.tileColumns(width))
+	switch encoder {
+	case "libsvtav1":
+		args = append(args, "-preset", strconv.Itoa(o.CPUUsed), "-svtav1-params", "tile-columns="+tiles)
+	default:
+		speed := o.CPUUsed
+		if pass == 1 && encoder == "libvpx-vp9" {
+			// The first pass only gathers statistics; it can run fast
+			speed = max(speed, 4)
+		}
+		if encoder == "libvpx-vp9" {
+			args = append(args, "-deadline", "good")
+		}
+		args = append(args, "-cpu-used", strconv.Itoa(speed), "-tile-columns", tiles)
+		if o.RowMT {
+			args = append(args, "-row-mt", "1")
+		}
+	}
+
+	if pass > 0 {
+		args = append(args, "-pass", strconv.Itoa(pass), "-passlogfile", passlog)
+	}
+	return args
+}
+
+// audioArgs returns the audio encoding arguments
+func (o *WebOptions) audioArgs() []string {
+	switch o.Audio {
+	case "none":
+		return []string{"-an"}
+	case "copy":
+		return []string{"-c:a", "copy"}
+	}
+	args := []string{"-c:a", o.audioEncoder()}
+	if o.AudioBitrate != "" {
+		args = append(args, "-b:a", o.AudioBitrate)
+	}
+	return args
+}
+
+// firstPassArgs builds the statistics pass, which writes no output
+func (o *WebOptions) firstPassArgs(input, passlog string, width int) []string {
+	args := []string{"-y", "-i", input, "-map", "0:v:0"}
+	args = append(args, o.videoArgs(1, passlog, width)...)
+	return append(args, "-an", "-f", "null", os.DevNull)
+}
+
+// encodeArgs builds the final pass (pass 2, or 0 for single-pass)
+func (o *WebOptions) encodeArgs(t target, input, output, passlog string, pass, width int) []string {
+	args := []string{"-y", "-i", input, "-map", "0:v:0"}
+	if o.Audio != "none" {
+		args = append(args, "-map", "0:a:0?")
+	}
+	args = append(args, o.videoArgs(pass, passlog, width)...)
+	args = append(args, o.audioArgs()...)
+	if t.muxer == "mp4" {
+		args = append(args, "-movflags", "+faststart")
+	}
+	return append(args, "-f", t.muxer, output)
+}
@@ -0,0 +1,199 @@
+package web_video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/ui"
+)
+
+func init() {
+	// Auto-register one converter per target
+	for _, factory := range Factories() {
+		converter.RegisterFactory(factory)
+	}
+}
+
+// Factories returns a factory for each of the web video converters (webm, av1)
+func Factories() []converter.Factory {
+	factories := []converter.Factory{}
+	for _, name := range Targets() {
+		factories = append(factories, func() converter.Converter {
+			conv, _ := NewWebConverter(name)
+			return conv
+		})
+	}
+	return factories
+}
+
+// WebConverter encodes video for browser delivery with VP9 or AV1, in one
+// or two passes
+type WebConverter struct {
+	target target
+
+	mu      sync.Mutex
+	ffmpeg  executor.Lazy
+	options WebOptions
+}
+
+// NewWebConverter creates a web video converter for the named target (see Targets)
+func NewWebConverter(name string) (*WebConverter, error) {
+	t, err := lookupTarget(name)
+	if err != nil {
+		return nil, err
+	}
+	return &WebConverter{
+		target:  t,
+		options: DefaultWebOptions(t),
+	}, nil
+}
+
+// Name returns the converter name
+func (c *WebConverter) Name() string {
+	return c.target.name
+}
+
+// Description returns the converter description
+func (c *WebConverter) Description() string {
+	return c.target.description
+}
+
+// SupportedInputs returns supported input formats
+func (c *WebConverter) SupportedInputs() []string {
+	return supportedInputs()
+}
+
+// OutputExtension returns the output extension
+func (c *WebConverter) OutputExtension() string {
+	return c.target.ext
+}
+
+// Cost returns the planner cost hint; VP9 and AV1 encode slowly, so the
+// planner prefers H.264 for .mp4 routes
+func (c *WebConverter) Cost() int {
+	return c.target.cost
+}
+
+// Validate checks if the input file is valid
+func (c *WebConverter) Validate(input string) error {
+	info, err := os.Stat(input)
+	if err != nil {
+		return fmt.Errorf("cannot access file: %w", err)
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf("input is a directory, not a file")
+	}
+
+	return media.CheckInput(input, c.SupportedInputs())
+}
+
+// Convert processes a single file
+func (c *WebConverter) Convert(ctx context.Context, job converter.Job) (*converter.Result, error) {
+	input, opts := job.Input, job.Options
+
+	c.mu.Lock()
+	options := c.options
+	c.mu.Unlock()
+
+	var ff executor.Executor
+	return converter.RunJob(ctx, job, converter.Task{
+		Validate: c.Validate,
+		Prepare: func() (err error) {
+			ff, err = c.ffmpeg.Get()
+			return err
+		},
+		Encode: func(partial string) (*executor.FFmpegResult, error) {
+			// The width picks the tile layout; the duration drives progress
+			info, err := ff.GetInfo(ctx, input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to probe input: %w", err)
+			}
+			video := info.PrimaryVideo()
+			if video == nil {
+				return nil, fmt.Errorf("no video stream in input")
+			}
+			width, _ := video.DisplaySize()
+
+			return c.encode(ctx, ff, input, partial, width, info.Duration(), options, opts)
+		},
+	})
+}
+
+// encode runs the passes into partial. Two-pass statistics go to a
+// temporary directory that is removed afterwards.
+func (c *WebConverter) encode(ctx context.Context, ff executor.Executor, input, partial string, width int, duration time.Duration, options WebOptions, opts converter.Options) (*executor.FFmpegResult, error) {
+	if options.Passes == 1 {
+		args := options.encodeArgs(c.target, input, partial, "", 0, width)
+		return ff.Run(ctx, args, converter.RunOptionsFor(opts, input, duration, 0, 1))
+	}
+
+	dir, err := os.MkdirTemp("", "sb-2pass-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pass log directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	passlog := filepath.Join(dir, "pass")
+
+	ui.PrintVerbose(opts.Verbose, "Pass 1/2: %s", input)
+	if ffResult, err := ff.Run(ctx, options.firstPassArgs(input, passlog, width), converter.RunOptionsFor(opts, input, duration, 0, 2)); err != nil {
+		return ffResult, fmt.Errorf("first pass failed: %w", err)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	ui.PrintVerbose(opts.Verbose, "Pass 2/2: %s", input)
+	args := options.encodeArgs(c.target, input, partial, passlog, 2, width)
+	return ff.Run(ctx, args, converter.RunOptionsFor(opts, input, duration, 1, 2))
+}
+
+// Preflight verifies that ffmpeg has the configured encoders and muxers
+func (c *WebConverter) Preflight() error {
+	ff, err := c.ffmpeg.Get()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	req := c.options.Requirements(c.target)
+	c.mu.Unlock()
+
+	return ff.Preflight(req)
+}
+
+// OptionSchema describes the options of the target
+func (c *WebConverter) OptionSchema() []converter.OptionSpec {
+	return optionSchema(c.target)
+}
+
+// Configure applies option values resolved against OptionSchema
+func (c *WebConverter) Configure(values converter.OptionValues) error {
+	return c.SetOptions(optionsFromValues(c.target, values))
+}
+
+// SetOptions sets converter-specific options
+func (c *WebConverter) SetOptions(opts WebOptions) error {
+	if err := opts.Validate(c.target); err != nil {
+		return err
+	}
+
+	c.ffmpeg.SetToolchain(opts.Toolchain)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.options = opts
+	return nil
+}
+
+// SetExecutor overrides the executor used for conversions
+func (c *WebConverter) SetExecutor(e executor.Executor) {
+	c.ffmpeg.Set(e)
+}
@@ -0,0 +1,73 @@
+package web_video
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/onedusk/sb/internal/media"
+)
+
+// target describes one output of the web video converter
+type target struct {
+	name        string   // converter name
+	description string   // converter description
+	ext         string   // output extension
+	muxer       string   // ffmpeg muxer
+	codecs      []string // video codecs, default first
+	audio       []string // audio codecs, default first
+	crf         int      // default CRF
+	cpuUsed     int      // default speed setting
+	cost        int      // planner cost hint
+}
+
+// targets lists the supported outputs by converter name
+var targets = map[string]target{
+	"webm": {
+		name:        "webm",
+		description: "Convert video to WebM (VP9 or AV1 with Opus) for web delivery",
+		ext:         ".webm",
+		muxer:       "webm",
+		codecs:      []string{"vp9", "av1"},
+		audio:       []string{"opus", "copy", "none"},
+		crf:         31,
+		cpuUsed:     2,
+		cost:        25,
+	},
+	"av1": {
+		name:        "av1",
+		description: "Convert video to AV1 in MP4 for web delivery",
+		ext:         ".mp4",
+		muxer:       "mp4",
+		codecs:      []string{"av1"},
+		audio:       []string{"aac", "opus", "copy", "none"},
+		crf:         30,
+		cpuUsed:     4,
+		cost:        30,
+	},
+}
+
+// Targets returns the names of the supported outputs
+func Targets() []string {
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupTarget returns the target with the given name
+func lookupTarget(name string) (target, error) {
+	t, ok := targets[name]
+	if !ok {
+		return target{}, fmt.Errorf("unknown web video target %q (supported: %s)", name, strings.Join(Targets(), ", "))
+	}
+	return t, nil
+}
+
+// supportedInputs returns the video formats web targets accept
+func supportedInputs() []string {
+	return slices.Clone(media.VideoInputs)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/schollz/progressbar/v3"
@@ -51,6 +52,40 @@ func NewProgressBar(total int, description string, enabled bool) *ProgressBar {
 	}
 }
 
+// NewPercentBar creates a progress bar tracking a single file from 0 to 100 percent
+func NewPercentBar(description string, enabled bool) *ProgressBar {
+	if !enabled {
+		return &ProgressBar{
+			enabled: false,
+			writer:  os.Stdout,
+		}
+	}
+
+	bar := progressbar.NewOptions(100,
+		progressbar.OptionSetDescription(description),
+		progressbar.OptionSetWriter(os.Stderr),
+		progressbar.OptionSetWidth(40),
+		progressbar.OptionThrottle(100*time.Millisecond),
+		progressbar.OptionShowElapsedTimeOnFinish(),
+		progressbar.OptionSetTheme(progressbar.Theme{
+			Saucer:        "=",
+			SaucerHead:    ">",
+			SaucerPadding: " ",
+			BarStart:      "[",
+			BarEnd:        "]",
+		}),
+		progressbar.OptionOnCompletion(func() {
+			fmt.Fprint(os.Stderr, "\n")
+		}),
+	)
+
+	return &ProgressBar{
+		bar:     bar,
+		enabled: true,
+		writer:  os.Stdout,
+	}
+}
+
 // Increment advances the progress bar by one
 func (p *ProgressBar) Increment() error {
 	if !p.enabled || p.bar == nil {
@@ -67,6 +102,14 @@ func (p *ProgressBar) Set(n int) error {
 	return p.bar.Set(n)
 }
 
+// Describe updates the text shown next to the progress bar
+func (p *ProgressBar) Describe(description string) {
+	if !p.enabled || p.bar == nil {
+		return
+	}
+	p.bar.Describe(description)
+}
+
 // Finish completes the progress bar
 func (p *ProgressBar) Finish() error {
 	if !p.enabled || p.bar == nil {
@@ -105,24 +148,109 @@ func (p *ProgressBar) Println(args ...interface{}) {
 	}
 }
 
-// Summary displays conversion statistics
-func PrintSummary(total, success, failed, skipped int, duration time.Duration) {
+// FormatFileProgress formats per-file encode progress for a progress bar description
+func FormatFileProgress(file string, percentage, speed float64, eta time.Duration) string {
+	desc := fmt.Sprintf("%s %3.0f%%", filepath.Base(file), percentage)
+	if speed > 0 {
+		desc += fmt.Sprintf(" %.2fx", speed)
+	}
+	desc += " ETA " + FormatETA(eta)
+	return desc
+}
+
+// FormatETA formats a remaining duration, or "--:--" when unknown
+func FormatETA(eta time.Duration) string {
+	if eta <= 0 {
+		return "--:--"
+	}
+
+	eta = eta.Round(time.Second)
+	h := int(eta.Hours())
+	m := int(eta.Minutes()) % 60
+	s := int(eta.Seconds()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
+
+// FormatBytes formats a byte count using binary units, e.g., "1.5 MiB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Summary contains batch statistics for PrintSummary
+type Summary struct {
+	Total       int
+	Success     int
+	Failed      int
+	Skipped     int
+	Interrupted int // aborted while running
+	NotStarted  int // never started because the batch was interrupted
+	Duration    time.Duration
+
+	// Loudness lists the files whose loudness was normalized
+	Loudness []LoudnessLine
+}
+
+// LoudnessLine is the measured and achieved loudness of one file
+type LoudnessLine struct {
+	File                               string
+	MeasuredI, MeasuredTP, MeasuredLRA float64
+	I, TP, LRA                         float64
+	Type                               string // normalization type, e.g., "linear"
+}
+
+// PrintSummary displays conversion statistics
+func PrintSummary(s Summary) {
 	fmt.Println()
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	fmt.Println("Conversion Summary")
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Printf("Total:     %d files\n", total)
-	fmt.Printf("Success:   %d files\n", success)
-	if failed > 0 {
-		fmt.Printf("Failed:    %d files\n", failed)
+	fmt.Printf("Total:       %d files\n", s.Total)
+	fmt.Printf("Success:     %d files\n", s.Success)
+	if s.Failed > 0 {
+		fmt.Printf("Failed:      %d files\n", s.Failed)
+	}
+	if s.Skipped > 0 {
+		fmt.Printf("Skipped:     %d files\n", s.Skipped)
 	}
-	if skipped > 0 {
-		fmt.Printf("Skipped:   %d files\n", skipped)
+	if s.Interrupted > 0 {
+		fmt.Printf("Interrupted: %d files\n", s.Interrupted)
+	}
+	if s.NotStarted > 0 {
+		fmt.Printf("Not started: %d files\n", s.NotStarted)
+	}
+	fmt.Printf("Duration:    %s\n", s.Duration.Round(time.Millisecond))
+	if len(s.Loudness) > 0 {
+		fmt.Println()
+		fmt.Println("Loudness (integrated / true peak / range):")
+		for _, l := range s.Loudness {
+			fmt.Printf("  %s\n", l.File)
+			fmt.Printf("    %s -> %s", FormatLoudness(l.MeasuredI, l.MeasuredTP, l.MeasuredLRA), FormatLoudness(l.I, l.TP, l.LRA))
+			if l.Type != "" {
+				fmt.Printf(" (%s)", l.Type)
+			}
+			fmt.Println()
+		}
 	}
-	fmt.Printf("Duration:  %s\n", duration.Round(time.Millisecond))
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 }
 
+// FormatLoudness formats EBU R128 levels, e.g., "-16.0 LUFS / -1.5 dBTP / 7.2 LU"
+func FormatLoudness(i, tp, lra float64) string {
+	return fmt.Sprintf("%.1f LUFS / %.1f dBTP / %.1f LU", i, tp, lra)
+}
+
 // PrintError prints an error message
 func PrintError(format string, args ...interface{}) {
 	fmt.Fprintf(os.Stderr, "ERROR: "+format+"\n", args...)
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFormatETA(t *testing.T) {
+	tests := []struct {
+		eta  time.Duration
+		want string
+	}{
+		{0, "--:--"},
+		{-time.Second, "--:--"},
+		{90*time.Second + 400*time.Millisecond, "01:30"},
+		{2*time.Hour + 5*time.Minute + 9*time.Second, "2:05:09"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatETA(tt.eta); got != tt.want {
+			t.Errorf("FormatETA(%v) = %q, want %q", tt.eta, got, tt.want)
+		}
+	}
+}
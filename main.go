@@ -1,14 +1,44 @@
 package main
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/onedusk/sb/cmd"
 	"github.com/onedusk/sb/cmd/formats"
-	_ "github.com/onedusk/sb/internal/processors/mov_to_mp4" // Register MP4 converter
+	"github.com/onedusk/sb/internal/ui"
+	"github.com/onedusk/sb/pkg/sb"
 )
 
 func main() {
-	// Register format commands
-	cmd.GetRootCmd().AddCommand(formats.MP4Cmd)
+	// The default registry already holds the built-in converters
+	reg := sb.DefaultRegistry()
+
+	// Read config early: it can declare converters
+	cmd.LoadConfig()
+
+	// Register converters declared in config and ~/.sb/converters.d
+	if _, err := sb.LoadDeclared(reg, sb.DeclaredDir()); err != nil {
+		ui.PrintWarning("%v", err)
+	}
+
+	// Register external plugins from ~/.sb/plugins and $SB_PLUGIN_PATH. An
+	// interrupt during the handshakes kills the plugin processes and exits.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	_, err := sb.LoadPlugins(ctx, reg, sb.PluginDirs())
+	interrupted := ctx.Err() != nil
+	stop()
+	if interrupted {
+		os.Exit(130)
+	}
+	if err != nil {
+		ui.PrintWarning("%v", err)
+	}
+
+	// Generate a subcommand for every registered converter
+	formats.Register(cmd.GetRootCmd(), reg)
 
 	// Execute CLI
 	cmd.Execute()
@@ -0,0 +1,151 @@
+package sb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/media"
+)
+
+// UnknownOptionError reports option names a converter does not declare
+type UnknownOptionError struct {
+	Converter string
+	Names     []string // sorted
+}
+
+func (e *UnknownOptionError) Error() string {
+	return fmt.Sprintf("unknown %s option(s): %s", e.Converter, strings.Join(e.Names, ", "))
+}
+
+// InvalidOptionError reports an option value that does not fit its schema,
+// or values the converter rejected as a whole (Option empty)
+type InvalidOptionError struct {
+	Converter string
+	Option    string
+	Err       error
+}
+
+func (e *InvalidOptionError) Error() string {
+	if e.Option == "" {
+		return fmt.Sprintf("invalid %s options: %v", e.Converter, e.Err)
+	}
+	return fmt.Sprintf("%s option %s: %v", e.Converter, e.Option, e.Err)
+}
+
+func (e *InvalidOptionError) Unwrap() error {
+	return e.Err
+}
+
+// NewConverter returns a new instance of the converter named name in r,
+// configured with values (see Configure). Other users of r are unaffected.
+func NewConverter(r *Registry, name string, values OptionValues) (Converter, error) {
+	conv, err := r.New(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := Configure(conv, values); err != nil {
+		return nil, err
+	}
+	return conv, nil
+}
+
+// Configure checks values against conv's option schema and applies them.
+// Values may be given in any form the option type accepts (e.g., "20" or
+// 20.0 for an int); options missing from values get their defaults.
+// Unknown option names are reported with an *UnknownOptionError, values
+// that do not fit the schema or that conv rejects with an
+// *InvalidOptionError. The resolved values are returned.
+func Configure(conv Converter, values OptionValues) (OptionValues, error) {
+	var schema []OptionSpec
+	c, configurable := conv.(Configurable)
+	if configurable {
+		schema = c.OptionSchema()
+	}
+
+	known := make(map[string]bool, len(schema))
+	for _, spec := range schema {
+		known[spec.Name] = true
+	}
+	unknown := []string{}
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, &UnknownOptionError{Converter: conv.Name(), Names: unknown}
+	}
+	if !configurable {
+		return OptionValues{}, nil
+	}
+
+	var invalid error
+	resolved, err := converter.ResolveOptions(schema, func(spec OptionSpec) (any, bool) {
+		v, ok := values[spec.Name]
+		if !ok || invalid != nil {
+			return nil, false
+		}
+		coerced, err := converter.CoerceValue(spec.Type, v)
+		if err == nil && coerced != nil {
+			err = spec.Check(coerced)
+		}
+		if err != nil {
+			invalid = &InvalidOptionError{Converter: conv.Name(), Option: spec.Name, Err: err}
+			return nil, false
+		}
+		return coerced, coerced != nil
+	})
+	if invalid != nil {
+		return nil, invalid
+	}
+	if err != nil {
+		return nil, &InvalidOptionError{Converter: conv.Name(), Err: err}
+	}
+
+	if err := c.Configure(resolved); err != nil {
+		return nil, &InvalidOptionError{Converter: conv.Name(), Err: err}
+	}
+	return resolved, nil
+}
+
+// Convert converts a single input with conv. The output path is opts.Output
+// when set, otherwise the converter's default next to the input or in
+// opts.OutputDir. Cancelling ctx stops the conversion.
+func Convert(ctx context.Context, conv Converter, input string, opts Options) (*Result, error) {
+	return conv.Convert(ctx, converter.NewJob(conv, input, opts))
+}
+
+// Batch converts inputs in parallel with conv, delivering lifecycle events to
+// opts.OnEvent. Cancelling ctx stops new jobs from starting; cancelling
+// opts.Abort (default ctx) kills running ones. Results are returned in
+// input order; failed jobs are reported through a *BatchError.
+func Batch(ctx context.Context, conv Converter, inputs []string, opts Options) ([]*Result, Stats, error) {
+	start := time.Now()
+	results, err := converter.Batch(ctx, conv, inputs, opts)
+
+	stats := converter.StatsOf(results)
+	stats.StartTime, stats.EndTime = start, time.Now()
+	return results, stats, err
+}
+
+// ConvertTo converts input to the format with extension ext, routing it
+// through the cheapest converter chain in r
+func ConvertTo(ctx context.Context, r *Registry, input, ext string, opts Options) (*Result, error) {
+	plan, err := PlanFor(r, input, ext)
+	if err != nil {
+		return nil, err
+	}
+	return Convert(ctx, plan.Converter(), input, opts)
+}
+
+// PlanFor finds the cheapest converter chain in r turning input into a file
+// with extension ext. The input format is detected from the file's content
+// when its extension does not match.
+func PlanFor(r *Registry, input, ext string) (*Plan, error) {
+	return r.FindPlan(media.DetectExt(input), ext)
+}
@@ -0,0 +1,55 @@
+package sb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewConverter(t *testing.T) {
+	reg, err := NewBuiltinRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		values      OptionValues
+		wantUnknown bool
+		wantInvalid string // option reported by *InvalidOptionError
+	}{
+		{"defaults", nil, false, ""},
+		{"coerced values", OptionValues{"quality": "20", "preset": "slow"}, false, ""},
+		{"unknown option", OptionValues{"bogus": 1}, true, ""},
+		{"value of the wrong type", OptionValues{"quality": "high"}, false, "quality"},
+		{"value not allowed", OptionValues{"preset": "fastest"}, false, "preset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := NewConverter(reg, "mp4", tt.values)
+
+			var unknown *UnknownOptionError
+			if errors.As(err, &unknown) != tt.wantUnknown {
+				t.Fatalf("NewConverter() error = %v, want unknown option %v", err, tt.wantUnknown)
+			}
+			var invalid *InvalidOptionError
+			if errors.As(err, &invalid) != (tt.wantInvalid != "") {
+				t.Fatalf("NewConverter() error = %v, want invalid option %q", err, tt.wantInvalid)
+			}
+			if invalid != nil && invalid.Option != tt.wantInvalid {
+				t.Errorf("invalid option = %q, want %q", invalid.Option, tt.wantInvalid)
+			}
+			if err != nil {
+				return
+			}
+
+			shared, err := reg.Get("mp4")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if conv == shared {
+				t.Error("NewConverter() returned the registry's shared instance")
+			}
+		})
+	}
+}
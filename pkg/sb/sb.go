@@ -0,0 +1,236 @@
+// Package sb is the public Go API of the sb media processor. It lets other
+// programs use sb's converters, registry, planner, batch engine and ffprobe
+// wrapper in-process instead of shelling out to the CLI, which is itself a
+// client of this package.
+//
+// A minimal program converts a batch with the built-in converters:
+//
+//	reg, err := sb.NewBuiltinRegistry()
+//	if err != nil { ... }
+//	conv, err := sb.NewConverter(reg, "mp4", sb.OptionValues{"quality": 20})
+//	if err != nil { ... }
+//	results, stats, err := sb.Batch(ctx, conv, inputs, sb.Options{
+//		Workers: 4,
+//		OnEvent: func(ev sb.Event) { log.Println(ev.Type, ev.Input) },
+//	})
+//
+// The types below are aliases of sb's internal types, so values can be
+// passed freely between this package and converters built against it.
+package sb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onedusk/sb/internal/converter"
+	"github.com/onedusk/sb/internal/declarative"
+	"github.com/onedusk/sb/internal/executor"
+	"github.com/onedusk/sb/internal/media"
+	"github.com/onedusk/sb/internal/plugin"
+	"github.com/onedusk/sb/internal/processors/all"
+)
+
+// Converter types
+type (
+	Base           = converter.Base
+	Converter      = converter.Converter
+	Configurable   = converter.Configurable
+	Preflighter    = converter.Preflighter
+	Coster         = converter.Coster
+	Job            = converter.Job
+	Options        = converter.Options
+	Result         = converter.Result
+	Loudness       = converter.Loudness
+	LoudnessLevels = converter.LoudnessLevels
+	Stats          = converter.Stats
+	Progress       = converter.Progress
+	BatchError     = converter.BatchError
+)
+
+// Option schema types
+type (
+	OptionSpec   = converter.OptionSpec
+	OptionType   = converter.OptionType
+	OptionValues = converter.OptionValues
+)
+
+// Option value types
+const (
+	OptionString   = converter.OptionString
+	OptionInt      = converter.OptionInt
+	OptionBool     = converter.OptionBool
+	OptionFloat    = converter.OptionFloat
+	OptionDuration = converter.OptionDuration
+)
+
+// Event types
+type (
+	Event        = converter.Event
+	EventType    = converter.EventType
+	EventHandler = converter.EventHandler
+)
+
+// Batch lifecycle events
+const (
+	EventQueued     = converter.EventQueued
+	EventStarted    = converter.EventStarted
+	EventProgress   = converter.EventProgress
+	EventFinished   = converter.EventFinished
+	EventSkipped    = converter.EventSkipped
+	EventFailed     = converter.EventFailed
+	EventNotStarted = converter.EventNotStarted
+)
+
+// Registry and planning types
+type (
+	Registry = converter.Registry
+	Factory  = converter.Factory
+	Plan     = converter.Plan
+	Step     = converter.Step
+)
+
+// ErrNoRoute is returned when no converter chain leads to the requested format
+var ErrNoRoute = converter.ErrNoRoute
+
+// Media and execution types
+type (
+	MediaInfo      = media.MediaInfo
+	VideoStream    = media.VideoStream
+	AudioStream    = media.AudioStream
+	SubtitleStream = media.SubtitleStream
+	Executor       = executor.Executor
+	Toolchain      = executor.Toolchain
+	Pool           = executor.Pool
+	PoolJob        = executor.Job
+)
+
+// ErrNotStarted is the error of jobs a cancelled batch or pool never ran
+var ErrNotStarted = executor.ErrNotStarted
+
+// Definition declares an ffmpeg recipe converter (see NewDeclared)
+type Definition = declarative.Definition
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return converter.NewRegistry()
+}
+
+// NewBuiltinRegistry creates a registry holding new instances of the
+// built-in converters, independent of the default registry
+func NewBuiltinRegistry() (*Registry, error) {
+	r := converter.NewRegistry()
+	if err := all.Register(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DefaultRegistry returns the process-wide registry used by the CLI. It
+// holds the built-in converters; the CLI adds declared converters and
+// plugins at startup.
+func DefaultRegistry() *Registry {
+	return converter.Default()
+}
+
+// Builtins returns new instances of the built-in converters
+func Builtins() []Converter {
+	return all.Builtins()
+}
+
+// NormalizeExt lowercases an extension and adds the leading dot if missing,
+// e.g., "WEBM" -> ".webm"
+func NormalizeExt(ext string) string {
+	return converter.NormalizeExt(ext)
+}
+
+// OutputPath returns the path conv writes input to for opts
+func OutputPath(conv Base, input string, opts Options) string {
+	return converter.OutputPath(conv, input, opts)
+}
+
+// ResolveOptions builds the values for schema; lookup returns an explicitly
+// set value for an option, if any, and all other options get their defaults
+func ResolveOptions(schema []OptionSpec, lookup func(OptionSpec) (any, bool)) (OptionValues, error) {
+	return converter.ResolveOptions(schema, lookup)
+}
+
+// NewPool creates a worker pool. Cancelling ctx stops queued jobs from
+// starting; jobCtx (default ctx) is handed to running jobs.
+func NewPool(ctx, jobCtx context.Context, workers int) *Pool {
+	return executor.NewPoolWithContext(ctx, jobCtx, workers)
+}
+
+// LoadDeclared registers the converters declared in the "converters" section
+// of the loaded config and in the YAML files of dirs with r
+func LoadDeclared(r *Registry, dirs ...string) ([]Converter, error) {
+	loaded, err := declarative.LoadAll(r, dirs...)
+	convs := make([]Converter, len(loaded))
+	for i, c := range loaded {
+		convs[i] = c
+	}
+	return convs, err
+}
+
+// DeclaredDir returns the directory of standalone converter definitions
+func DeclaredDir() string {
+	return declarative.DefaultDir()
+}
+
+// LoadPlugins registers the plugins found in dirs with r
+func LoadPlugins(ctx context.Context, r *Registry, dirs []string) ([]Converter, error) {
+	loaded, err := plugin.LoadAll(ctx, r, dirs)
+	convs := make([]Converter, len(loaded))
+	for i, c := range loaded {
+		convs[i] = c
+	}
+	return convs, err
+}
+
+// PluginDirs returns the plugin search path: ~/.sb/plugins and $SB_PLUGIN_PATH
+func PluginDirs() []string {
+	return plugin.SearchPaths()
+}
+
+// NewDeclared builds a converter from an ffmpeg recipe definition
+func NewDeclared(def Definition) (Converter, error) {
+	conv, err := declarative.New(def)
+	if err != nil {
+		return nil, err
+	}
+	return conv, nil
+}
+
+// LoadPlugin starts the plugin executable at path, performs the handshake
+// and returns a proxy converter for it
+func LoadPlugin(ctx context.Context, path string) (Converter, error) {
+	conv, err := plugin.Load(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return conv, nil
+}
+
+// NewExecutor returns an executor for the given ffmpeg and ffprobe binaries
+// (empty = from PATH)
+func NewExecutor(ffmpegPath, ffprobePath string) (Executor, error) {
+	return executor.NewFFmpegWithPaths(ffmpegPath, ffprobePath)
+}
+
+// ToolchainExecutor returns an executor for a registered toolchain ("" = default)
+func ToolchainExecutor(name string) (Executor, error) {
+	return executor.New(name)
+}
+
+// RegisterToolchain makes a named ffmpeg/ffprobe pair selectable by converters
+func RegisterToolchain(tc Toolchain) {
+	executor.RegisterToolchain(tc)
+}
+
+// Probe describes the media file at path with ffprobe from the default toolchain
+func Probe(ctx context.Context, path string) (*MediaInfo, error) {
+	ff, err := executor.New("")
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not available: %w", err)
+	}
+	return ff.GetInfo(ctx, path)
+}